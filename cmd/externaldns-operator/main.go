@@ -4,6 +4,8 @@ import (
 	"context"
 	"os"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"strconv"
+	"time"
 
 	"github.com/danehans/external-dns-operator/pkg/operator"
 	operatorclient "github.com/danehans/external-dns-operator/pkg/operator/client"
@@ -12,10 +14,9 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 
-	operatorv1 "github.com/danehans/api/operator/v1"
-
 	corev1 "k8s.io/api/core/v1"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -53,17 +54,64 @@ func main() {
 	if len(externalDNSImage) == 0 {
 		logrus.Fatalf("IMAGE environment variable is required")
 	}
+	roleARN := os.Getenv("ROLE_ARN")
+	var resolveZoneIDFromTags bool
+	if v := os.Getenv("RESOLVE_ZONE_ID_FROM_TAGS"); len(v) != 0 {
+		resolveZoneIDFromTags, err = strconv.ParseBool(v)
+		if err != nil {
+			logrus.Fatalf("invalid RESOLVE_ZONE_ID_FROM_TAGS environment variable %q: %v", v, err)
+		}
+	}
+	var verifyZoneFilter bool
+	if v := os.Getenv("VERIFY_ZONE_FILTER"); len(v) != 0 {
+		verifyZoneFilter, err = strconv.ParseBool(v)
+		if err != nil {
+			logrus.Fatalf("invalid VERIFY_ZONE_FILTER environment variable %q: %v", v, err)
+		}
+	}
+	var detectZoneType bool
+	if v := os.Getenv("DETECT_ZONE_TYPE"); len(v) != 0 {
+		detectZoneType, err = strconv.ParseBool(v)
+		if err != nil {
+			logrus.Fatalf("invalid DETECT_ZONE_TYPE environment variable %q: %v", v, err)
+		}
+	}
+	var syncPeriod *metav1.Duration
+	if v := os.Getenv("RESYNC_PERIOD"); len(v) != 0 {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			logrus.Fatalf("invalid RESYNC_PERIOD environment variable %q: must be a positive duration", v)
+		}
+		syncPeriod = &metav1.Duration{Duration: d}
+	}
+	var operandPriorityClassName *string
+	if v, ok := os.LookupEnv("OPERAND_PRIORITY_CLASS_NAME"); ok {
+		operandPriorityClassName = &v
+	}
+	var validateOperandFlags bool
+	if v := os.Getenv("VALIDATE_OPERAND_FLAGS"); len(v) != 0 {
+		validateOperandFlags, err = strconv.ParseBool(v)
+		if err != nil {
+			logrus.Fatalf("invalid VALIDATE_OPERAND_FLAGS environment variable %q: %v", v, err)
+		}
+	}
+	var cleanupOperandNamespace bool
+	if v := os.Getenv("CLEANUP_OPERAND_NAMESPACE"); len(v) != 0 {
+		cleanupOperandNamespace, err = strconv.ParseBool(v)
+		if err != nil {
+			logrus.Fatalf("invalid CLEANUP_OPERAND_NAMESPACE environment variable %q: %v", v, err)
+		}
+	}
 	releaseVersion := os.Getenv("RELEASE_VERSION")
 	if len(releaseVersion) == 0 {
 		releaseVersion = controller.UnknownReleaseVersionName
 		logrus.Infof("RELEASE_VERSION environment variable missing; using release version: %s", controller.UnknownReleaseVersionName)
 	}
 
-	// Retrieve the cluster infrastructure and dns configs.
-	infraConfig := &configv1.Infrastructure{}
-	err = kubeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig)
+	// Retrieve the cluster platform and dns config.
+	platform, err := controller.PlatformType(kubeClient)
 	if err != nil {
-		logrus.Fatalf("failed to get infrastructure 'config': %v", err)
+		logrus.Fatalf("failed to get cluster platform: %v", err)
 	}
 	dnsConfig := &configv1.DNS{}
 	err = kubeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, dnsConfig)
@@ -71,24 +119,37 @@ func main() {
 		logrus.Fatalf("failed to get dns 'cluster': %v", err)
 	}
 
+	// The credentials secret is read on every platform, so that
+	// externaldnses selecting a provider other than the platform's through
+	// spec.provider.type get their credentials. Each provider reads its own
+	// keys of the secret.
+	provider := controller.ProviderTypeForPlatform(platform)
 	creds := &corev1.Secret{}
-	var provider operatorv1.ProviderType
-	switch infraConfig.Status.Platform {
-	case configv1.AWSPlatformType:
-		// Get Operand creds
-		err := kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: operatorNamespace, Name: cloudCredentialsSecretName}, creds)
-		if err != nil {
-			logrus.Fatalf("failed to get aws credentials from secret %q: %v", cloudCredentialsSecretName, err)
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: operatorNamespace, Name: cloudCredentialsSecretName}, creds); err != nil {
+		if operator.CredentialsRequired(provider, roleARN) {
+			logrus.Fatalf("failed to get %s credentials from secret %q: %v", provider, cloudCredentialsSecretName, err)
 		}
-		provider = operatorv1.AWSProvider
+		logrus.Infof("failed to get credentials from secret %q, only externaldnses not requiring them can be managed: %v", cloudCredentialsSecretName, err)
+		creds = &corev1.Secret{}
 	}
 
 	operatorConfig := operatorconfig.Config{
-		OperatorReleaseVersion: releaseVersion,
-		Namespace:              operatorNamespace,
-		ExternalDNSImage:       externalDNSImage,
-		Credentials:            creds,
-		Provider:               provider,
+		OperatorReleaseVersion:   releaseVersion,
+		Namespace:                operatorNamespace,
+		ExternalDNSImage:         externalDNSImage,
+		Credentials:              creds,
+		CredentialsSecretName:    cloudCredentialsSecretName,
+		OperandNamespace:         os.Getenv("OPERAND_NAMESPACE"),
+		Provider:                 provider,
+		RoleARN:                  roleARN,
+		ResolveZoneIDFromTags:    resolveZoneIDFromTags,
+		VerifyZoneFilter:         verifyZoneFilter,
+		DetectZoneType:           detectZoneType,
+		SyncPeriod:               syncPeriod,
+		OperandContainerName:     os.Getenv("OPERAND_CONTAINER_NAME"),
+		OperandPriorityClassName: operandPriorityClassName,
+		ValidateOperandFlags:     validateOperandFlags,
+		CleanupOperandNamespace:  cleanupOperandNamespace,
 	}
 
 	// Set up and start the operator.
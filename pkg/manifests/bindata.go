@@ -1,9 +1,19 @@
 // Code generated by go-bindata. DO NOT EDIT.
 // sources:
+// assets/externaldns/cleanup-job.yaml (259B)
 // assets/externaldns/cluster-role-binding.yaml (262B)
-// assets/externaldns/cluster-role.yaml (424B)
-// assets/externaldns/deployment.yaml (609B)
+// assets/externaldns/cluster-role.yaml (613B)
+// assets/externaldns/contour-httpproxy-source-cluster-role-binding.yaml (270B)
+// assets/externaldns/contour-httpproxy-source-cluster-role.yaml (368B)
+// assets/externaldns/crd-source-cluster-role-binding.yaml (256B)
+// assets/externaldns/crd-source-cluster-role.yaml (552B)
+// assets/externaldns/crd-source-read-only-cluster-role.yaml (372B)
+// assets/externaldns/crd-source-role-binding.yaml (264B)
+// assets/externaldns/deployment.yaml (1.489kB)
+// assets/externaldns/horizontal-pod-autoscaler.yaml (339B)
+// assets/externaldns/job.yaml (221B)
 // assets/externaldns/namespace.yaml (71B)
+// assets/externaldns/network-policy.yaml (659B)
 // assets/externaldns/service-account.yaml (101B)
 
 package manifests
@@ -73,6 +83,26 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
+var _assetsExternaldnsCleanupJobYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x54\xce\xb1\x4e\xf4\x30\x10\xc4\xf1\xde\x4f\x31\x52\xea\xef\x3e\x21\xba\xd4\xd0\x20\x44\x03\xa2\xdf\xc4\x63\xc5\x3a\xdb\x6b\xad\x37\x39\xee\xed\xd1\xa5\xa3\x1b\x4d\xf1\xd3\x7f\xc2\x9b\x2e\xb8\x65\xdf\x10\x99\x64\x2f\x8e\x43\xca\xce\x81\xa4\x06\xdf\x08\x6d\xfc\x37\x36\x75\x18\xab\x1e\x52\xa0\xe9\xfc\x8d\xab\x5a\x1c\xd0\x5b\x63\x0c\x13\x96\x3b\xa4\xe1\xf5\xc7\x69\x4d\xca\xcb\xc7\xe7\x05\x5f\x1b\xd1\x35\xc2\x59\x7b\x11\x27\xf2\x40\xa4\xe5\x83\x11\xc9\xb4\x9e\x8e\x76\x9a\xb4\x87\x10\xd9\x8b\xde\x2b\x9b\x43\x1c\xb6\x37\xcf\x95\x97\x70\xcd\x2d\xce\x8f\xcc\x20\x3d\x7f\xd3\x46\xd6\x36\x63\x11\x5f\xb7\xff\xc7\x53\x98\xd0\xa4\x12\xd2\xe2\x39\x46\x97\x95\x10\x23\x06\xff\x3a\xa3\x73\x9d\x03\xb0\xc8\x7a\xd5\x94\xde\x73\xcd\x3e\xe3\x39\xfc\x0e\x00\x08\x52\x2d\xb5\x03\x01\x00\x00")
+
+func assetsExternaldnsCleanupJobYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsCleanupJobYaml,
+		"assets/externaldns/cleanup-job.yaml",
+	)
+}
+
+func assetsExternaldnsCleanupJobYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsCleanupJobYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/cleanup-job.yaml", size: 259, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x36, 0x6e, 0x76, 0xa3, 0xfd, 0x71, 0xba, 0x60, 0x32, 0xec, 0xf4, 0xf8, 0x4d, 0x78, 0x12, 0x72, 0x28, 0x2, 0xa4, 0x3d, 0xbb, 0x67, 0xef, 0xe9, 0xe1, 0x1a, 0xe1, 0xa9, 0x94, 0x38, 0x2d, 0x51}}
+	return a, nil
+}
+
 var _assetsExternaldnsClusterRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\xce\x41\x6a\xc4\x30\x0c\x85\xe1\xbd\x4f\xa1\x0b\x24\xa5\xbb\xe2\x5d\xdb\x1b\xa4\xd0\xbd\x62\x2b\x8d\x1a\x47\x0a\x96\x1c\x4a\x4f\x3f\x84\x61\x20\x30\xcc\x6c\x85\xde\xc7\x8f\x1b\x7f\x53\x35\x56\x89\x50\x47\x4c\x3d\x36\x9f\xb5\xf2\x3f\x3a\xab\xf4\xcb\x9b\xf5\xac\x2f\xfb\x6b\x58\x58\x72\x84\xcf\xd2\xcc\xa9\x0e\x5a\xe8\x83\x25\xb3\xfc\x84\x95\x1c\x33\x3a\xc6\x00\x00\x20\xb8\x52\x04\xdd\x48\x6c\xe6\xc9\x3b\xfa\x73\xaa\x82\x25\x8b\x05\x6b\xe3\x2f\x25\xb7\xe3\xb3\x83\x2b\xf8\x45\x75\xe7\x44\xef\x29\x69\x13\x3f\x11\xe7\xe1\xed\x6a\x1b\xa6\x87\x7a\xd5\x42\x03\x4d\x07\x7e\xd7\x1a\x9e\x87\x85\x4b\x00\x00\x00\xff\xff\x40\xfe\x1b\x90\x06\x01\x00\x00")
 
 func assetsExternaldnsClusterRoleBindingYamlBytes() ([]byte, error) {
@@ -93,7 +123,7 @@ func assetsExternaldnsClusterRoleBindingYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsExternaldnsClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\xd0\x31\x4f\x33\x31\x0c\x06\xe0\x3d\xbf\xc2\xca\xdc\xde\xa7\x6f\x43\x59\x19\xd8\x19\x58\x10\x83\x7b\x31\x3d\xab\xa9\x1d\xd9\xce\x81\xf8\xf5\xe8\x4e\xdd\x60\x40\x88\xf5\xf5\xab\x47\xb6\xb1\xf3\x13\x99\xb3\x4a\x01\x3b\xe1\x3c\xe1\x88\x45\x8d\x3f\x30\x58\x65\xba\xdc\xf9\xc4\xfa\x6f\xfd\x9f\x2e\x2c\xb5\xc0\x7d\x1b\x1e\x64\x8f\xda\x28\x5d\x29\xb0\x62\x60\x49\x00\x82\x57\x2a\xa0\x9d\xc4\x17\x7e\x8d\x23\xbd\x07\x99\x60\xab\xe2\xc9\x46\x23\xdf\x4a\x47\xc0\xce\x0f\xa6\xa3\x7b\x81\xe7\x9c\x5f\x12\x00\x80\x91\xeb\xb0\x99\xf6\xcc\xc9\x56\x9e\xc9\x6f\xb3\x95\xec\xb4\xe7\x67\x8a\x7c\xc8\x6f\x18\xf3\x92\x0f\xb9\xb1\xc7\xde\xf8\x81\xd8\xb5\xfe\x52\xdb\x6e\x90\xed\x33\xfe\x9d\xcb\x72\x36\x72\xff\xcb\x55\x45\xeb\x57\xee\x06\x7c\x06\x00\x00\xff\xff\x8b\x3d\x14\xe4\xa8\x01\x00\x00")
+var _assetsExternaldnsClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\xd1\x31\x4f\xc3\x30\x10\x05\xe0\x3d\xbf\xe2\xe4\xb9\x0d\x62\x43\x59\x19\xd8\x19\x58\x50\x87\x6b\x7c\x34\xa7\xba\x77\xd6\xdd\x39\x20\x7e\x3d\x4a\x04\x2c\x64\xa8\x50\xd7\xe7\xa7\x4f\xf6\x33\x56\x7e\x21\x73\x56\x19\xc0\x8e\x38\xf6\xd8\x62\x52\xe3\x4f\x0c\x56\xe9\xcf\x0f\xde\xb3\xde\xcd\xf7\xdd\x99\x25\x0f\xf0\x58\x9a\x07\xd9\xb3\x16\xea\x2e\x14\x98\x31\x70\xe8\x00\x04\x2f\x34\x80\x56\x12\x9f\xf8\x2d\xf6\xf4\x11\x64\x82\x25\x8b\x77\xd6\x0a\xf9\x52\xda\x03\x56\x7e\x32\x6d\xd5\x07\x78\x4d\xe9\xd0\x01\x00\x18\xb9\x36\x1b\x69\xcd\x9c\x6c\xe6\x91\xfc\xfb\x6c\x26\x3b\xae\xf9\x89\x22\xed\xd2\x3b\xc6\x38\xa5\x5d\x2a\xec\x91\x0e\xd7\x89\x55\xf3\x0d\x35\x92\x5c\x95\x25\xfe\x49\x2e\xb3\xc8\x32\xb6\x6f\xe1\x2c\x27\x23\xf7\x5b\xbe\x5e\x34\xff\xe5\x56\xe0\xc7\xdb\x80\x4c\x5b\x50\xff\xfb\x99\x3d\xeb\x16\xbd\xb6\xae\xbb\xea\xd7\x00\x9d\x18\x61\x14\x65\x02\x00\x00")
 
 func assetsExternaldnsClusterRoleYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -108,12 +138,132 @@ func assetsExternaldnsClusterRoleYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "assets/externaldns/cluster-role.yaml", size: 424, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7d, 0x11, 0xee, 0x29, 0xfa, 0x4e, 0xb, 0xef, 0x30, 0x92, 0x38, 0xfc, 0x87, 0xec, 0xec, 0xe2, 0x39, 0x49, 0xdf, 0x8a, 0xda, 0xc6, 0xb5, 0xaf, 0x67, 0xb2, 0xee, 0xdd, 0x1c, 0x61, 0x7a, 0x17}}
+	info := bindataFileInfo{name: "assets/externaldns/cluster-role.yaml", size: 613, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd6, 0x44, 0x80, 0xb6, 0xe0, 0x69, 0xed, 0x4d, 0x1d, 0x20, 0x5b, 0xa6, 0xdb, 0x19, 0x2d, 0xb2, 0x8a, 0xdc, 0x90, 0x12, 0x2d, 0xfd, 0x1f, 0x65, 0xf2, 0x4d, 0x54, 0x20, 0x75, 0x58, 0xa0, 0xf7}}
+	return a, nil
+}
+
+var _assetsExternaldnsContourHttpproxySourceClusterRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x8e\xc1\x4a\x43\x31\x10\x45\xf7\xf9\x8a\x01\xd7\x79\xe2\x4e\xb2\x53\xff\xa0\x82\xfb\x34\xef\xd6\x37\x36\x9d\x09\x33\x93\x52\xfd\x7a\x51\x51\x04\xdd\x5e\xce\x3d\x9c\x2b\x92\x7a\x02\xb1\x93\x23\xa8\x06\xd9\x94\xe0\x13\x96\x54\x07\x3f\xc1\x9c\x55\x0a\xd9\xbe\xb6\xa5\xce\xd8\xd4\xf8\xad\x06\xab\x2c\xc7\x5b\x5f\x58\xaf\xcf\x37\xe9\xc8\xb2\x16\x7a\xe8\xd3\x03\xb6\xd3\x8e\x7b\x96\x95\xe5\x39\xf9\xdc\xbf\xa0\x85\x97\x44\x94\xe9\x0b\x7b\x84\x9d\xb9\xe1\xae\x35\x9d\x12\x89\x88\x3e\x03\x0a\xe1\x12\x30\xa9\x7d\x15\xff\x59\x7d\xd4\x86\x42\x3a\x20\xbe\xf1\x21\xf2\x6f\xc8\xb4\x63\x87\xc3\x87\xfc\x4f\x41\xfa\xb6\xfe\x7b\xcd\x4d\x25\x74\x5a\xde\x22\xc6\x30\xbd\xbc\x66\xd7\x69\x0d\xe9\x7d\x00\xca\x0f\x08\x88\x0e\x01\x00\x00")
+
+func assetsExternaldnsContourHttpproxySourceClusterRoleBindingYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsContourHttpproxySourceClusterRoleBindingYaml,
+		"assets/externaldns/contour-httpproxy-source-cluster-role-binding.yaml",
+	)
+}
+
+func assetsExternaldnsContourHttpproxySourceClusterRoleBindingYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsContourHttpproxySourceClusterRoleBindingYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/contour-httpproxy-source-cluster-role-binding.yaml", size: 270, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x6e, 0x6, 0x54, 0x1b, 0xd6, 0xc2, 0x9c, 0x33, 0xa9, 0x7b, 0x4e, 0xff, 0x79, 0x6c, 0x78, 0xd9, 0xe8, 0xfc, 0xa9, 0x33, 0xcd, 0xe8, 0xba, 0x78, 0x21, 0x47, 0xbc, 0x35, 0x2c, 0x39, 0xc5, 0x68}}
+	return a, nil
+}
+
+var _assetsExternaldnsContourHttpproxySourceClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x8f\x41\x4f\x22\x41\x10\x85\xef\xfd\x2b\x2a\xc3\x95\x99\xcd\xde\x36\x73\x5c\x30\x7a\x32\x04\x89\x17\xc3\xa1\x98\x29\xed\x92\xa6\xab\x53\x55\x0d\xe8\xaf\x37\x03\x98\x98\x78\xfe\xde\xf7\xf2\xde\x0c\xfe\x4b\xcd\x23\xb8\x80\x47\x02\x3a\x3b\x69\xc6\x34\x66\x03\x23\x3d\xf2\x40\x80\xc3\x20\x35\x3b\x9c\x22\x65\xc0\x0c\x77\xb7\xcc\xf2\xf1\x09\xaa\x91\x4d\x62\x98\xc1\x20\xd9\xa5\x6a\x1b\xdd\x4b\x51\x39\x7f\x80\x49\xd5\xc9\x9f\xea\x23\xc1\xc3\x66\xb3\x5a\x5d\xc0\x62\xbd\x04\x36\xe0\x6c\x8e\x29\xd1\xd8\x05\x2c\xfc\x4c\x6a\x2c\xb9\x07\xdd\xe1\xd0\x61\xf5\x28\xca\x9f\xe8\x2c\xb9\xdb\xff\xb3\x8e\xe5\xcf\xf1\x6f\xd8\x73\x1e\x7b\x58\xa4\x6a\x4e\xba\x96\x44\xe1\x40\x8e\x23\x3a\xf6\x01\x20\xe3\x81\x7a\x90\x42\xd9\x22\xbf\x7a\xfb\xe3\x4e\xfb\x6b\x5f\x7b\xdd\x17\xb4\x26\xb2\xc9\x6e\x01\x0b\xdf\xab\xd4\x62\x3d\xbc\x34\x45\xe5\x9d\x06\xbf\x79\x1d\x4b\xb3\x0d\x00\x00\x4a\x57\xf1\x12\xfa\x6e\x63\xb2\x1b\x3e\x92\xee\x2e\xe8\x8d\xbc\x99\x37\x27\xf4\x21\x36\xf3\x26\xb1\x79\xb3\x0d\x5f\x03\x00\x4e\x2a\xc0\x51\x70\x01\x00\x00")
+
+func assetsExternaldnsContourHttpproxySourceClusterRoleYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsContourHttpproxySourceClusterRoleYaml,
+		"assets/externaldns/contour-httpproxy-source-cluster-role.yaml",
+	)
+}
+
+func assetsExternaldnsContourHttpproxySourceClusterRoleYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsContourHttpproxySourceClusterRoleYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/contour-httpproxy-source-cluster-role.yaml", size: 368, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf2, 0x83, 0x4a, 0x90, 0x6b, 0xd3, 0xd1, 0x26, 0x9c, 0x6b, 0x98, 0xa3, 0xe1, 0xa7, 0xeb, 0xbe, 0x35, 0x8e, 0xd, 0x23, 0x7f, 0x78, 0x68, 0x2c, 0xda, 0x1, 0x3e, 0x48, 0x73, 0x4e, 0xfd, 0xa2}}
+	return a, nil
+}
+
+var _assetsExternaldnsCrdSourceClusterRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x8e\xc1\x4a\x03\x51\x0c\x45\xf7\xef\x2b\x02\xae\x67\xc4\x9d\xbc\x9d\xfa\x07\x15\xdc\xa7\x99\x5b\x1b\x3b\x4d\x86\x24\xaf\x88\x5f\x2f\x2a\x8a\xa0\xdb\xcb\xb9\x87\x73\x45\xc6\x67\x90\x26\x25\x8a\xb8\x28\x86\x95\x9e\x31\x37\xde\xf4\x09\x91\xea\xd6\x29\xf6\x2c\x33\x8f\x3a\x7a\xe8\x1b\x97\xba\xcd\xa7\xdb\x9c\xd5\xaf\x2f\x37\xed\xa4\xb6\x74\x7a\x58\x47\x16\x62\xe7\x2b\xee\xd5\x16\xb5\xe7\x96\x63\xff\x02\xa9\xec\x8d\x68\xa2\x2f\xec\x11\x71\x51\xc1\x9d\x88\x0f\xab\x46\x44\x9f\x01\x9d\xf0\x5a\x08\xe3\x75\xb1\xfc\x59\x73\x63\x41\x27\xdf\x60\x79\xd4\x43\x4d\xbf\xa1\xf0\x15\x3b\x1c\x3e\xe4\x7f\x0a\xda\xb7\xf5\xdf\xeb\x24\xb1\x4c\xe9\x23\x04\xed\x7d\x00\x23\x83\xee\x3c\x00\x01\x00\x00")
+
+func assetsExternaldnsCrdSourceClusterRoleBindingYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsCrdSourceClusterRoleBindingYaml,
+		"assets/externaldns/crd-source-cluster-role-binding.yaml",
+	)
+}
+
+func assetsExternaldnsCrdSourceClusterRoleBindingYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsCrdSourceClusterRoleBindingYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/crd-source-cluster-role-binding.yaml", size: 256, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xcd, 0xac, 0x67, 0x7f, 0x9a, 0x2d, 0x1e, 0x7e, 0x28, 0x28, 0xe6, 0xd6, 0x3f, 0x7d, 0x62, 0xc, 0x4f, 0x6d, 0x10, 0x14, 0xa1, 0xea, 0x65, 0x3e, 0xd3, 0xbc, 0x6c, 0xd9, 0x70, 0x9a, 0xbd, 0xfb}}
+	return a, nil
+}
+
+var _assetsExternaldnsCrdSourceClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x90\xb1\x6e\xdc\x40\x0c\x44\x7b\x7d\xc5\x40\xd7\x9e\x64\xa4\x0b\x54\x26\x36\xd2\xb9\x88\x83\x34\x81\x0b\xde\x2e\x6d\x11\x96\x49\x81\xe4\xde\x05\xf9\xfa\xc0\xd2\x15\x4e\x6a\xd7\x1c\xce\xc3\x9b\x03\xbe\x58\xd3\x8a\x34\xe4\xcc\xe0\xdf\xc9\xae\xb4\x54\x0d\x04\xfb\x59\x0a\x83\x4a\xb1\xa6\x79\x04\x4b\xce\xec\x28\x4b\x8b\x64\x1f\x2e\x52\x19\xe6\xdd\x01\x17\xc9\x59\x14\x04\xa5\x57\x8e\x95\x0a\x1f\x71\x99\x59\x41\x8a\xbb\x6b\xe3\xed\xfd\x03\x5a\x70\x6c\x98\xe2\x15\x61\xcd\x0b\x8f\x1d\xad\xf2\x93\x3d\xc4\x74\x82\x9f\xa8\x8c\xd4\x72\x36\x97\x3f\x94\x62\x3a\xbe\x7c\x8e\x51\xec\xe6\xfc\xa9\x7b\x11\xad\x13\xbe\xee\xf4\xef\xb6\x70\xf7\xca\x49\x95\x92\xa6\x0e\x1b\x7a\x82\xad\xac\x31\xcb\x53\x0e\xef\x4c\x86\xe2\x75\xd8\x71\x9d\xb7\x85\xe3\x2d\x3f\x80\x56\xf9\xe6\xd6\xd6\x98\xf0\xab\x7f\x17\xbf\x22\xfb\xc7\x0e\x00\x9c\xf7\xcf\x2d\x55\x35\x58\xeb\x6a\xa2\x19\xd7\xfb\x99\xfd\xb4\xdd\x9e\x39\xfb\x63\x7f\xa1\x2c\x73\x7f\xec\x17\x89\xdc\x12\x07\xfc\x98\xf9\x9f\x15\x8a\x69\xba\x2d\x0b\x3b\x9c\x8b\x79\xdd\x37\xb1\xd3\xdb\xe0\x5c\xf1\xcc\xca\xbe\xc9\xc3\x9e\xc0\x54\xe6\xad\xe6\xf6\xfe\xe1\xee\xca\x86\x28\x24\x03\x91\x94\x2d\xc6\x8f\xb0\xb9\xd9\xbb\xfe\x97\x6a\x6b\xa5\xe4\xfe\xb1\xfb\x3b\x00\x5b\x50\x0f\x26\x28\x02\x00\x00")
+
+func assetsExternaldnsCrdSourceClusterRoleYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsCrdSourceClusterRoleYaml,
+		"assets/externaldns/crd-source-cluster-role.yaml",
+	)
+}
+
+func assetsExternaldnsCrdSourceClusterRoleYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsCrdSourceClusterRoleYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/crd-source-cluster-role.yaml", size: 552, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x16, 0xc7, 0xc9, 0x24, 0xfe, 0xa3, 0x57, 0x38, 0x75, 0xd4, 0xc2, 0x18, 0xdc, 0x60, 0x9f, 0xaf, 0x39, 0x1c, 0xa1, 0x9a, 0x73, 0xf, 0xd6, 0x5b, 0x87, 0xaa, 0x6e, 0x43, 0x53, 0x6e, 0xc8, 0xd}}
+	return a, nil
+}
+
+var _assetsExternaldnsCrdSourceReadOnlyClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\x8f\xbf\x6e\xe2\x40\x10\xc6\xfb\x7d\x8a\x4f\xa6\xc5\x3e\x5d\x77\x72\x79\x07\xba\x8e\x22\x48\x69\x22\x8a\x61\x77\x88\x57\x2c\xb3\xd6\xcc\x2c\x24\x79\xfa\x28\xe0\x82\xfa\xfb\xfb\x5b\xe1\x6f\x6d\x92\xe0\x15\x3e\x31\xf8\xc3\x59\x85\x4a\x12\x83\xb1\x5e\x73\x64\x50\x8c\xb5\x89\x23\x0b\xe6\x42\x91\x51\x4f\x77\x6f\xd4\x04\xab\x4d\x23\x87\x15\xfe\x95\x66\xce\xfa\x52\x0b\xe3\x36\xb1\x80\x04\xdb\xa5\x6c\xb3\xdb\x23\x65\xa3\x63\x61\xc3\x66\xb7\xdf\x4a\x9a\x6b\x16\x87\x39\x79\x33\xb4\x39\x91\xb3\x0d\x81\xe6\xfc\xca\x6a\xb9\xca\x08\x3d\x52\x1c\xa8\xf9\x54\x35\x7f\x91\xe7\x2a\xc3\xf9\x8f\x0d\xb9\xfe\xba\xfe\x0e\xe7\x2c\x69\x7c\xde\x0c\x17\x76\x4a\xe4\x34\x06\x40\xe8\xc2\x23\xea\xcc\x62\x53\x3e\x79\xff\x04\xd5\x47\x4d\xfd\xe3\x74\xaf\x4c\xa9\xaf\x52\x3e\x83\xb6\xc2\xf6\x93\xec\x41\x73\xfe\xaf\xb5\xcd\x36\xe2\xad\x7b\x0a\x2e\xe3\xdd\x21\x00\x80\xf2\xa3\xe3\xee\x4a\x62\xbc\x10\xd9\xa2\x5f\x59\x8f\x77\xed\x9d\xbd\x5b\x77\x37\xf2\x38\x75\xeb\xae\x64\xf3\xee\x10\xbe\x07\x00\x45\x80\x39\x4d\x74\x01\x00\x00")
+
+func assetsExternaldnsCrdSourceReadOnlyClusterRoleYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsCrdSourceReadOnlyClusterRoleYaml,
+		"assets/externaldns/crd-source-read-only-cluster-role.yaml",
+	)
+}
+
+func assetsExternaldnsCrdSourceReadOnlyClusterRoleYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsCrdSourceReadOnlyClusterRoleYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/crd-source-read-only-cluster-role.yaml", size: 372, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x4e, 0x83, 0x96, 0x21, 0xad, 0x15, 0xc4, 0x38, 0xf5, 0xce, 0xb1, 0x8a, 0xb, 0xf8, 0x84, 0xfb, 0x9f, 0xee, 0x82, 0x1a, 0x9, 0x35, 0x79, 0xc6, 0xb1, 0xb6, 0xb9, 0x69, 0xbc, 0x6a, 0xc1, 0xee}}
 	return a, nil
 }
 
-var _assetsExternaldnsDeploymentYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\xcd\xae\xda\x40\x0c\x85\xf7\x79\x0a\x4b\x59\xd3\x42\xa5\x16\x31\xbb\x0a\xba\xe8\xa2\x28\x52\xab\xee\xad\x89\xa1\x56\xe7\xaf\xb6\x87\xde\xbc\xfd\x55\x42\x10\x01\x31\x2b\xeb\xf8\xf8\xf8\xf3\xb4\x70\xa0\x12\xf2\x10\x29\x19\xfc\x67\xfb\x03\x3d\x9d\xb0\x06\x83\x0b\x86\x4a\xda\xb4\xf0\xed\xcd\x48\x12\x86\xc3\xf1\x27\x68\x21\xcf\x27\xf6\x73\x17\x50\x08\xb0\x94\xc0\xd4\x03\x1a\x48\x4d\xc6\x91\x3e\x34\x7f\x39\xf5\x6e\x11\xdd\x60\xe1\xdf\x24\xca\x39\xb9\x71\x40\x3f\x5e\x36\x4d\x0b\x09\x23\x01\xa6\x7e\x2a\xb4\xa0\xa7\x29\x51\xc9\x1e\xd2\xc6\xad\xae\x01\x30\x8a\x25\xa0\xd1\x58\x03\xdc\xd4\xa9\x26\xb9\xb0\xa7\xaf\xde\xe7\x9a\xec\x88\x91\x1c\xd0\xcc\xdd\x27\x9d\x5d\x45\x38\x0b\xdb\xb0\x0f\xa8\x7a\x35\xe9\xa0\x46\x71\xe5\x43\x55\x23\x59\x79\x61\x63\x8f\x61\x1e\xf0\x39\x19\x72\x22\xd1\xdb\x22\x80\xd5\x04\xfb\x2a\x7e\x7c\x2d\x70\xc4\xf3\xf5\x28\x94\xb3\xbe\xbc\xe7\x6e\x9f\xcc\x5d\x0d\xa1\xcb\x81\xfd\xe0\xe0\xfb\xe9\x98\xad\x13\xd2\xf1\xd3\xee\xbe\x92\xc5\x16\x0c\x77\x8a\x48\x26\xec\x97\x04\x0b\xea\x2e\x8b\x39\xd8\xee\xb6\xbb\x87\x7e\x91\x6c\xd9\xe7\xe0\xe0\xd7\xbe\x5b\x74\x84\x34\x57\xf1\xf4\xb0\x68\x94\xff\x55\x52\x7b\x52\x01\x7c\xa9\x0e\x36\xeb\x75\x7c\xd2\x23\xc5\x2c\x83\x83\x4f\x9f\xbf\xfc\xe0\xe6\x3d\x00\x00\xff\xff\x6a\x20\xe7\xd7\x61\x02\x00\x00")
+var _assetsExternaldnsCrdSourceRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x8e\x31\x4e\x03\x41\x0c\x45\xfb\x39\x85\x25\xea\x5d\x44\x87\xa6\x03\x6e\x10\x24\x7a\x67\xe6\x87\x98\x6c\xec\x95\xed\x89\x10\xa7\x47\xd9\x08\x44\x41\x67\xd9\xfe\xef\xbf\x3b\x52\x3e\x83\x58\xfb\x36\xc4\xca\x0d\xc4\x0e\x0a\x24\x71\x92\x0f\x4d\x39\x63\x2e\xbc\xca\x1b\x3c\xc4\xb4\x92\xef\xb9\xcd\x3c\xf2\x68\x2e\x5f\x9c\x62\x3a\x9f\x1e\x63\x16\xbb\xbf\x3c\x94\x93\x68\xaf\xb4\xb3\x05\xcf\xa2\x5d\xf4\xbd\xc4\xd8\x7f\xa0\x65\xd4\x42\x34\xd1\xed\xfe\x0a\xbf\x48\xc3\x53\x6b\x36\x34\x0b\x11\x6d\xfd\x95\xf0\x99\x70\xe5\xa5\x6b\xfc\x6e\x37\xab\x4a\xb6\x42\xe3\x28\x87\x9c\xfe\x3e\xb9\x2d\xd8\xe1\x70\x85\xdf\xd0\x2f\xcb\x88\x84\x5f\x0d\xca\x0f\xf5\xdf\xe8\xd4\xbc\x4f\x61\xc3\x1b\xca\xf7\x00\x52\xaf\x8a\x95\x08\x01\x00\x00")
+
+func assetsExternaldnsCrdSourceRoleBindingYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsCrdSourceRoleBindingYaml,
+		"assets/externaldns/crd-source-role-binding.yaml",
+	)
+}
+
+func assetsExternaldnsCrdSourceRoleBindingYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsCrdSourceRoleBindingYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/crd-source-role-binding.yaml", size: 264, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x34, 0xe2, 0xb0, 0x2f, 0x10, 0x54, 0x74, 0xdb, 0x77, 0x25, 0x7, 0xbd, 0x6c, 0x27, 0xcf, 0xd4, 0x25, 0x49, 0x44, 0xea, 0xae, 0xd0, 0x9, 0x56, 0xe6, 0x6b, 0x49, 0x60, 0x89, 0x40, 0xa6, 0x8d}}
+	return a, nil
+}
+
+var _assetsExternaldnsDeploymentYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x53\x4d\x6f\x1b\x47\x0c\xbd\xeb\x57\x3c\x40\xd7\x3a\x89\x5b\xb8\x41\xf6\x16\xc4\x45\x9b\x43\x0d\xa1\x36\x7a\x67\x66\x29\x2d\xd1\xd9\xe1\x94\xe4\xca\xde\xfe\xfa\x62\xf4\x91\xec\x06\xc9\x3d\x18\x1d\x84\x79\x8f\x9c\xc7\xf7\x96\x5b\xdc\x73\xcd\x3a\x8f\x5c\x02\xcf\x12\x03\x7a\xde\xd3\x94\x03\x47\xca\x13\xfb\x66\x8b\xdf\x5e\x82\xad\x50\xbe\x7f\x78\x84\x57\x4e\xb2\x97\x74\x41\x41\xc6\xa0\x5a\xb3\x70\x0f\x0a\xd8\x54\x42\x46\x7e\xb5\xf9\x47\x4a\xdf\x2d\x5a\x6f\xa8\xca\xdf\x6c\x2e\x5a\xba\x56\xe0\xaf\x8f\xb7\x9b\x2d\x0a\x8d\x0c\x2a\xfd\xe9\x8f\x57\x4a\x7c\xea\xe8\x1c\xab\x6e\xed\xd5\x6e\x03\x6c\xf1\x18\x5a\x11\x03\x43\x73\x8f\xaa\x3d\x3e\xf1\x5e\x5b\x45\x90\x85\x94\x03\x24\x1c\xc6\x35\x53\xe2\x36\xd2\x4f\x70\x45\x0c\x14\x20\x98\xe6\xac\x53\x9c\xfa\x14\x3e\xb2\x35\xb9\x8e\x78\xd6\xd5\x88\x49\x4b\x34\x2a\x9b\x9f\x0d\x69\xcf\x79\x13\x1a\x2f\x01\x7d\x2e\x6c\x90\xfe\xd5\x06\xf0\x30\x0a\x3e\xcc\x4d\x1a\x10\x73\xe5\x0e\x7f\x71\x32\xa6\xe0\x0d\x10\x3c\xd6\x4c\xc1\x67\xf8\x3a\x43\x3b\xce\x76\x94\xc4\xef\x53\xd2\xa9\xc4\x03\x8d\xdc\x81\x2f\x12\xfa\xe2\x17\x56\x35\x51\x93\x98\x3f\x64\x72\x3f\x93\x7c\xf6\xe0\xf1\x26\xe5\xc9\x83\xed\x26\x99\x84\x24\xca\x97\x82\xa6\x9c\xa4\xb0\xf9\xf5\x21\xe0\x06\xe5\x3b\xed\xdb\x6f\x0b\x19\xe9\x70\x8e\x80\xec\xe0\xdf\x74\xff\x4a\xc6\x99\xbc\x9b\x72\xde\x69\x96\x34\x77\xf8\xb8\x7f\xd0\xd8\x19\x7b\x8b\xf8\xca\x02\xaa\x5a\x2c\x34\x7c\x51\x31\x72\x98\xa4\xa5\x82\x85\xea\x9d\x5a\x74\x78\xfb\xee\xed\xbb\x15\x5e\x4d\x43\x93\xe6\x0e\x4f\x1f\x76\x0b\x64\x8b\x9d\xe9\x27\xbe\x7e\xaf\x67\xed\xfc\x52\xb3\x24\x89\xcf\xb1\xb7\xf0\xb4\xb2\x51\xa8\xa1\x57\x76\x14\x5d\x4a\xdd\xa2\xe7\xe0\x14\xe8\x4d\xf6\x01\x3a\x90\x14\x0f\xbc\xdf\x7d\x3c\xa5\xc4\x76\xed\x2f\xe5\xb0\xb4\x22\xcb\x91\x0b\xbb\x9f\x34\x2c\x47\x05\x86\x88\xfa\x3b\xc7\xfa\x12\xa8\x14\x43\x87\xd7\x03\x53\x8e\xe1\xbf\xaf\xc1\xd3\xec\xdf\xb2\x07\xf0\x34\x70\x33\xef\x8f\xa7\xa7\xe5\xfc\x80\x14\x09\xa1\x7c\xcf\x99\xe6\x47\x4e\x5a\x7a\xef\x70\xfb\x66\xc5\x69\xeb\xa8\x53\x7c\x86\xef\x56\x68\x65\x13\xed\xbf\x57\xeb\x53\x4a\xec\xfe\x34\x18\xfb\xa0\xb9\xef\x70\xbb\xc2\xf7\x24\x79\x32\x5e\xe0\xbf\x2c\x70\x63\xea\xe5\x87\xb4\xe8\xee\x87\x71\xc8\x75\xb2\xc4\xab\x55\x69\xd7\xff\x4e\xec\xeb\x05\x6a\x27\xd5\xa9\xc5\xfb\x66\xfc\xea\x7e\xe4\x51\x6d\xee\xf0\xf3\xdd\xaf\x7f\xca\xe6\xff\x01\x00\x16\x6b\x97\x25\xd1\x05\x00\x00")
 
 func assetsExternaldnsDeploymentYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -128,8 +278,48 @@ func assetsExternaldnsDeploymentYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "assets/externaldns/deployment.yaml", size: 609, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb3, 0xd3, 0xe7, 0xd2, 0xea, 0xb5, 0xe7, 0x9d, 0x47, 0x7a, 0xc2, 0x9, 0x29, 0x3e, 0x7f, 0xca, 0x0, 0xdf, 0x79, 0xda, 0xde, 0x8c, 0xab, 0x3d, 0x69, 0xbc, 0xde, 0xdd, 0x1d, 0x28, 0x2d, 0xd9}}
+	info := bindataFileInfo{name: "assets/externaldns/deployment.yaml", size: 1489, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb4, 0x33, 0x69, 0x86, 0x5a, 0x4d, 0xad, 0xf5, 0x11, 0xd3, 0x87, 0xef, 0xa5, 0xea, 0x2b, 0x94, 0xab, 0xd9, 0x40, 0xaa, 0x12, 0xdf, 0x57, 0xda, 0x2b, 0x34, 0x89, 0xbb, 0xff, 0x9c, 0xf2, 0x7c}}
+	return a, nil
+}
+
+var _assetsExternaldnsHorizontalPodAutoscalerYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x8f\x41\x4b\xc3\x40\x10\x85\xef\xfb\x2b\x1e\xe4\x5e\xf5\x26\xb9\x89\x15\x3c\x49\xd0\xd6\xfb\x90\x4c\xd2\xc1\xcd\xec\x32\x3b\xa9\xda\x5f\x2f\x9b\x16\x41\xb0\xb7\xe5\x5b\xe6\xbd\xef\x35\x78\x4e\x26\xa7\xa4\x4e\xb1\x4b\xc3\xc3\xe2\xa9\xf4\x14\xd9\xf0\x29\x7e\xc0\xc0\x23\x2d\xd1\x71\xa4\xb8\x70\xc1\x98\x0c\xa4\x78\xfa\x72\x36\xa5\xb8\x7d\x79\x0b\x0d\x06\xce\x31\x7d\xcf\xac\xbe\xc1\xee\xc0\x58\xef\xe1\x64\x13\x3b\x48\x07\x18\xe7\x28\x3d\x21\xca\x2c\x5e\x40\xc6\x28\xf5\xcb\x61\x8b\xba\xcc\xbc\x09\x1f\xa2\x43\x7b\x4d\x25\x50\x96\x77\xb6\x22\x49\x5b\xd0\x05\x8b\x4e\x37\xc7\xbb\xd0\x40\x69\xe6\xb5\xa6\x3e\x4a\xa6\x9e\xff\x6d\x28\x99\xfb\x36\xe0\x6c\xb7\x5b\xe5\x5e\x79\xac\x04\xf8\x93\x9f\x73\xa9\xc1\x95\x9f\xad\xb6\xbf\xfb\x02\x2e\xb3\x1e\xbb\xfd\xde\x25\xca\x89\x5c\x92\x76\x6c\x3d\xab\xd3\xc4\x2d\xee\x6f\xc3\xcf\x00\xa3\xca\xd3\x39\x53\x01\x00\x00")
+
+func assetsExternaldnsHorizontalPodAutoscalerYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsHorizontalPodAutoscalerYaml,
+		"assets/externaldns/horizontal-pod-autoscaler.yaml",
+	)
+}
+
+func assetsExternaldnsHorizontalPodAutoscalerYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsHorizontalPodAutoscalerYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/horizontal-pod-autoscaler.yaml", size: 339, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2f, 0x1c, 0x76, 0x32, 0x32, 0x4, 0xa, 0x8c, 0x79, 0x42, 0x21, 0xa8, 0x5, 0x4d, 0xa1, 0x4a, 0x99, 0x3a, 0x82, 0xda, 0x94, 0x2f, 0x26, 0xa6, 0x99, 0xb0, 0xe2, 0x5c, 0x3e, 0x9f, 0x31, 0x18}}
+	return a, nil
+}
+
+var _assetsExternaldnsJobYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x54\xce\xb1\x4a\x05\x31\x10\x85\xe1\x3e\x4f\x71\x60\xfb\x2b\x62\xb7\xb5\x36\x22\x36\x8a\xfd\x6c\x72\xc2\x86\x9b\xcc\x84\x64\x76\xd5\xb7\x97\x2d\xed\xfe\xea\xe3\x5f\xf0\x6a\x1b\xbe\x8b\xef\x48\xcc\x72\x54\xc7\x29\xf5\xe0\x44\xb6\x01\x51\xbc\xfc\x38\x87\x4a\x7d\x7e\xff\xc0\x38\x14\xa6\x91\x37\x7c\xee\x44\xb7\x04\x67\xeb\x55\x9c\x61\x41\x99\x48\x1c\xe5\x64\x42\x1e\xd6\xe0\x3b\x61\x9d\x43\x34\x21\xb1\x57\xfb\x6d\x54\x87\xf8\xc5\x78\x69\xbc\x85\x7b\xd1\xb4\x5e\x03\x41\x7a\xf9\xe2\x98\xc5\x74\xc5\x26\x1e\xf7\x87\xf3\x31\x2c\x50\x69\xc4\x05\x5c\x31\xbb\x44\x42\x06\x31\xf9\xdf\x99\x9d\x71\x0d\xc0\x26\xf1\x6e\x39\xbf\x95\x56\x7c\xc5\x53\xf8\x1b\x00\xfb\x24\xd2\xa7\xdd\x00\x00\x00")
+
+func assetsExternaldnsJobYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsJobYaml,
+		"assets/externaldns/job.yaml",
+	)
+}
+
+func assetsExternaldnsJobYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsJobYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/job.yaml", size: 221, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x3c, 0x47, 0xf9, 0x2, 0x6a, 0xe8, 0x22, 0x96, 0x24, 0xd2, 0x3a, 0x4f, 0xb7, 0xa9, 0x29, 0xe6, 0xfe, 0x40, 0xa8, 0x73, 0xbd, 0xf6, 0x30, 0xf5, 0x50, 0x6b, 0x9b, 0xc2, 0xc2, 0x92, 0x62, 0xf}}
 	return a, nil
 }
 
@@ -153,6 +343,26 @@ func assetsExternaldnsNamespaceYaml() (*asset, error) {
 	return a, nil
 }
 
+var _assetsExternaldnsNetworkPolicyYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x90\x3f\x6f\xdb\x40\x0c\xc5\x77\x7d\x8a\x07\x68\x4d\x55\x14\x82\x90\x46\x5b\x50\x67\xc8\x62\x18\x88\xdb\xfd\x72\xa2\xed\x83\xcf\xe4\x81\xa4\xec\xfa\xdb\x17\x3a\xa3\x43\xd0\xa2\xf5\xc6\xbf\xef\xfd\xc8\x16\x6b\xf2\x8b\xe8\x71\x23\x39\xc5\x2b\x2e\xc9\x0f\x98\x68\x17\xe6\xec\x38\x87\x3c\x93\x61\x27\x0a\x3f\x10\x8a\x4c\x06\xd9\x21\x30\x5e\x7e\x3a\x29\x87\xbc\x5a\xbf\x35\x2d\x26\x2a\x59\xae\x27\x62\xef\xb0\xbd\x0d\xc2\x28\x53\x74\x51\x04\x9e\xea\x36\xed\x95\xcc\xe0\x52\xb3\xa2\x72\x4e\x13\xdd\xda\xc7\xf9\x9d\x9a\x16\xcf\x9b\x57\x43\x50\x82\x91\x23\x38\x74\x66\x4f\x27\xea\x9a\x63\xe2\x69\xfc\x08\xda\x84\x92\x7e\x90\x5a\x12\x1e\xc1\xb7\x4e\xe2\x7d\x77\xfc\x6a\x5d\x92\xcf\xe7\x2f\x4d\x0b\x0e\x27\xaa\xfa\x4b\x60\x25\x44\xfa\xab\xba\x15\x8a\x63\x03\x94\xfa\x81\xed\xb5\x90\x2d\xe9\x27\xbc\x72\x45\xae\xf1\xcb\xef\x30\xdd\x8a\xcb\x44\x8b\xe7\x9c\xe5\x52\xef\x39\x91\x6b\x8a\x86\x22\xea\xcb\x8d\xef\x04\x8b\x1a\x0a\x4d\x5d\xdd\x5f\xea\x55\xb6\x26\x2a\x2e\x51\xf2\x88\xed\xb7\x4d\xad\x2d\xee\xea\x23\x1e\x9f\x1e\x9f\x1a\x80\xfe\xf0\x58\x4e\x80\x92\x49\x9e\x3d\x09\x3f\x20\x71\xcc\xf3\x94\x78\x5f\xed\x63\x9e\xcd\x49\xb1\x5a\xbf\x2d\xdf\xb7\x07\x5c\x0e\x29\x1e\x90\x93\x39\x71\x65\x15\xae\x1e\x18\xfa\xa1\xff\x27\xd3\xf7\xd5\x47\xa6\xa1\xff\x2f\xf6\xd0\xdf\xa1\x72\x97\xce\xd0\x37\xbf\x06\x00\x4b\xe2\xca\x13\x93\x02\x00\x00")
+
+func assetsExternaldnsNetworkPolicyYamlBytes() ([]byte, error) {
+	return bindataRead(
+		_assetsExternaldnsNetworkPolicyYaml,
+		"assets/externaldns/network-policy.yaml",
+	)
+}
+
+func assetsExternaldnsNetworkPolicyYaml() (*asset, error) {
+	bytes, err := assetsExternaldnsNetworkPolicyYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "assets/externaldns/network-policy.yaml", size: 659, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa6, 0xf, 0x61, 0xc1, 0xf6, 0x8f, 0x5c, 0x59, 0x61, 0x8d, 0x7e, 0xe, 0x6b, 0xb7, 0xbf, 0x2f, 0x67, 0xc2, 0x2, 0x6f, 0x96, 0x7c, 0x19, 0x64, 0x9d, 0x7f, 0xf, 0xab, 0x5, 0x10, 0xcf, 0xec}}
+	return a, nil
+}
+
 var _assetsExternaldnsServiceAccountYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\xca\x21\x0e\xc3\x30\x0c\x05\x50\x9e\x53\xf8\x02\x03\xa3\x66\x3b\xc3\xa4\x71\xcb\xf9\xd3\xac\x35\x4e\x14\xbb\x51\x8f\x5f\x52\x50\xfa\xf4\x64\xd8\x07\x33\xac\x3b\xd3\x7a\x96\xbf\x79\x65\x7a\x63\x2e\x53\xbc\x54\xfb\xee\x59\x1a\x52\xaa\xa4\x70\x21\x72\x69\x60\xc2\x91\x98\x2e\x5b\xf5\xb8\x2c\x86\x28\x98\xfa\x80\xc7\xcf\xbe\xf9\xb8\x97\x33\x00\x00\xff\xff\x5a\xef\xe8\x33\x65\x00\x00\x00")
 
 func assetsExternaldnsServiceAccountYamlBytes() ([]byte, error) {
@@ -264,14 +474,34 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
+	"assets/externaldns/cleanup-job.yaml": assetsExternaldnsCleanupJobYaml,
+
 	"assets/externaldns/cluster-role-binding.yaml": assetsExternaldnsClusterRoleBindingYaml,
 
 	"assets/externaldns/cluster-role.yaml": assetsExternaldnsClusterRoleYaml,
 
+	"assets/externaldns/contour-httpproxy-source-cluster-role-binding.yaml": assetsExternaldnsContourHttpproxySourceClusterRoleBindingYaml,
+
+	"assets/externaldns/contour-httpproxy-source-cluster-role.yaml": assetsExternaldnsContourHttpproxySourceClusterRoleYaml,
+
+	"assets/externaldns/crd-source-cluster-role-binding.yaml": assetsExternaldnsCrdSourceClusterRoleBindingYaml,
+
+	"assets/externaldns/crd-source-cluster-role.yaml": assetsExternaldnsCrdSourceClusterRoleYaml,
+
+	"assets/externaldns/crd-source-read-only-cluster-role.yaml": assetsExternaldnsCrdSourceReadOnlyClusterRoleYaml,
+
+	"assets/externaldns/crd-source-role-binding.yaml": assetsExternaldnsCrdSourceRoleBindingYaml,
+
 	"assets/externaldns/deployment.yaml": assetsExternaldnsDeploymentYaml,
 
+	"assets/externaldns/horizontal-pod-autoscaler.yaml": assetsExternaldnsHorizontalPodAutoscalerYaml,
+
+	"assets/externaldns/job.yaml": assetsExternaldnsJobYaml,
+
 	"assets/externaldns/namespace.yaml": assetsExternaldnsNamespaceYaml,
 
+	"assets/externaldns/network-policy.yaml": assetsExternaldnsNetworkPolicyYaml,
+
 	"assets/externaldns/service-account.yaml": assetsExternaldnsServiceAccountYaml,
 }
 
@@ -318,11 +548,21 @@ type bintree struct {
 var _bintree = &bintree{nil, map[string]*bintree{
 	"assets": {nil, map[string]*bintree{
 		"externaldns": {nil, map[string]*bintree{
-			"cluster-role-binding.yaml": {assetsExternaldnsClusterRoleBindingYaml, map[string]*bintree{}},
-			"cluster-role.yaml":         {assetsExternaldnsClusterRoleYaml, map[string]*bintree{}},
-			"deployment.yaml":           {assetsExternaldnsDeploymentYaml, map[string]*bintree{}},
-			"namespace.yaml":            {assetsExternaldnsNamespaceYaml, map[string]*bintree{}},
-			"service-account.yaml":      {assetsExternaldnsServiceAccountYaml, map[string]*bintree{}},
+			"cleanup-job.yaml":                                   {assetsExternaldnsCleanupJobYaml, map[string]*bintree{}},
+			"cluster-role-binding.yaml":                          {assetsExternaldnsClusterRoleBindingYaml, map[string]*bintree{}},
+			"cluster-role.yaml":                                  {assetsExternaldnsClusterRoleYaml, map[string]*bintree{}},
+			"contour-httpproxy-source-cluster-role-binding.yaml": {assetsExternaldnsContourHttpproxySourceClusterRoleBindingYaml, map[string]*bintree{}},
+			"contour-httpproxy-source-cluster-role.yaml":         {assetsExternaldnsContourHttpproxySourceClusterRoleYaml, map[string]*bintree{}},
+			"crd-source-cluster-role-binding.yaml":               {assetsExternaldnsCrdSourceClusterRoleBindingYaml, map[string]*bintree{}},
+			"crd-source-cluster-role.yaml":                       {assetsExternaldnsCrdSourceClusterRoleYaml, map[string]*bintree{}},
+			"crd-source-read-only-cluster-role.yaml":             {assetsExternaldnsCrdSourceReadOnlyClusterRoleYaml, map[string]*bintree{}},
+			"crd-source-role-binding.yaml":                       {assetsExternaldnsCrdSourceRoleBindingYaml, map[string]*bintree{}},
+			"deployment.yaml":                                    {assetsExternaldnsDeploymentYaml, map[string]*bintree{}},
+			"horizontal-pod-autoscaler.yaml":                     {assetsExternaldnsHorizontalPodAutoscalerYaml, map[string]*bintree{}},
+			"job.yaml":                                           {assetsExternaldnsJobYaml, map[string]*bintree{}},
+			"namespace.yaml":                                     {assetsExternaldnsNamespaceYaml, map[string]*bintree{}},
+			"network-policy.yaml":                                {assetsExternaldnsNetworkPolicyYaml, map[string]*bintree{}},
+			"service-account.yaml":                               {assetsExternaldnsServiceAccountYaml, map[string]*bintree{}},
 		}},
 	}},
 }}
@@ -0,0 +1,46 @@
+package manifests
+
+import (
+	"os"
+	"testing"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// TestCustomResourceDefinition ensures the ExternalDNS CRD defines the
+// status subresource used by the operator to update status and the
+// printer columns shown by "oc get externaldns".
+func TestCustomResourceDefinition(t *testing.T) {
+	f, err := os.Open("../../manifests/00-custom-resource-definition.yaml")
+	if err != nil {
+		t.Fatalf("failed to open crd manifest: %v", err)
+	}
+	defer f.Close()
+
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 100).Decode(crd); err != nil {
+		t.Fatalf("failed to decode crd manifest: %v", err)
+	}
+	if crd.Spec.Subresources == nil || crd.Spec.Subresources.Status == nil {
+		t.Errorf("expected crd %s to define the status subresource", crd.Name)
+	}
+
+	expected := map[string]string{
+		"Provider":   ".status.provider",
+		"BaseDomain": ".status.baseDomain",
+		"Zone":       ".spec.zoneType",
+	}
+	for _, c := range crd.Spec.AdditionalPrinterColumns {
+		if path, ok := expected[c.Name]; ok {
+			if c.JSONPath != path {
+				t.Errorf("expected printer column %q to use JSONPath %q, got %q", c.Name, path, c.JSONPath)
+			}
+			delete(expected, c.Name)
+		}
+	}
+	for name := range expected {
+		t.Errorf("expected crd %s to define printer column %q", crd.Name, name)
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"io"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -17,6 +20,18 @@ const (
 	ExternalDNSClusterRoleAsset        = "assets/externaldns/cluster-role.yaml"
 	ExternalDNSClusterRoleBindingAsset = "assets/externaldns/cluster-role-binding.yaml"
 	ExternalDNSDeploymentAsset         = "assets/externaldns/deployment.yaml"
+	ExternalDNSCleanupJobAsset         = "assets/externaldns/cleanup-job.yaml"
+	ExternalDNSJobAsset                = "assets/externaldns/job.yaml"
+	ExternalDNSHPAAsset                = "assets/externaldns/horizontal-pod-autoscaler.yaml"
+	ExternalDNSNetworkPolicyAsset      = "assets/externaldns/network-policy.yaml"
+
+	ExternalDNSCRDSourceClusterRoleAsset         = "assets/externaldns/crd-source-cluster-role.yaml"
+	ExternalDNSCRDSourceClusterRoleBindingAsset  = "assets/externaldns/crd-source-cluster-role-binding.yaml"
+	ExternalDNSCRDSourceRoleBindingAsset         = "assets/externaldns/crd-source-role-binding.yaml"
+	ExternalDNSCRDSourceReadOnlyClusterRoleAsset = "assets/externaldns/crd-source-read-only-cluster-role.yaml"
+
+	ExternalDNSContourHTTPProxySourceClusterRoleAsset        = "assets/externaldns/contour-httpproxy-source-cluster-role.yaml"
+	ExternalDNSContourHTTPProxySourceClusterRoleBindingAsset = "assets/externaldns/contour-httpproxy-source-cluster-role-binding.yaml"
 
 	// OwningExternalDNSLabel should be applied to any objects "owned by"
 	// a dns to aid in selection (especially in cases where an ownerref
@@ -68,6 +83,86 @@ func ExternalDNSDeployment() *appsv1.Deployment {
 	return deploy
 }
 
+func ExternalDNSCleanupJob() *batchv1.Job {
+	job, err := NewJob(MustAssetReader(ExternalDNSCleanupJobAsset))
+	if err != nil {
+		panic(err)
+	}
+	return job
+}
+
+func ExternalDNSJob() *batchv1.Job {
+	job, err := NewJob(MustAssetReader(ExternalDNSJobAsset))
+	if err != nil {
+		panic(err)
+	}
+	return job
+}
+
+func ExternalDNSHorizontalPodAutoscaler() *autoscalingv1.HorizontalPodAutoscaler {
+	hpa, err := NewHorizontalPodAutoscaler(MustAssetReader(ExternalDNSHPAAsset))
+	if err != nil {
+		panic(err)
+	}
+	return hpa
+}
+
+func ExternalDNSNetworkPolicy() *networkingv1.NetworkPolicy {
+	np, err := NewNetworkPolicy(MustAssetReader(ExternalDNSNetworkPolicyAsset))
+	if err != nil {
+		panic(err)
+	}
+	return np
+}
+
+func ExternalDNSCRDSourceClusterRole() *rbacv1.ClusterRole {
+	cr, err := NewClusterRole(MustAssetReader(ExternalDNSCRDSourceClusterRoleAsset))
+	if err != nil {
+		panic(err)
+	}
+	return cr
+}
+
+func ExternalDNSCRDSourceReadOnlyClusterRole() *rbacv1.ClusterRole {
+	cr, err := NewClusterRole(MustAssetReader(ExternalDNSCRDSourceReadOnlyClusterRoleAsset))
+	if err != nil {
+		panic(err)
+	}
+	return cr
+}
+
+func ExternalDNSCRDSourceClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	crb, err := NewClusterRoleBinding(MustAssetReader(ExternalDNSCRDSourceClusterRoleBindingAsset))
+	if err != nil {
+		panic(err)
+	}
+	return crb
+}
+
+func ExternalDNSCRDSourceRoleBinding() *rbacv1.RoleBinding {
+	rb, err := NewRoleBinding(MustAssetReader(ExternalDNSCRDSourceRoleBindingAsset))
+	if err != nil {
+		panic(err)
+	}
+	return rb
+}
+
+func ExternalDNSContourHTTPProxySourceClusterRole() *rbacv1.ClusterRole {
+	cr, err := NewClusterRole(MustAssetReader(ExternalDNSContourHTTPProxySourceClusterRoleAsset))
+	if err != nil {
+		panic(err)
+	}
+	return cr
+}
+
+func ExternalDNSContourHTTPProxySourceClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	crb, err := NewClusterRoleBinding(MustAssetReader(ExternalDNSContourHTTPProxySourceClusterRoleBindingAsset))
+	if err != nil {
+		panic(err)
+	}
+	return crb
+}
+
 func NewServiceAccount(manifest io.Reader) (*corev1.ServiceAccount, error) {
 	sa := corev1.ServiceAccount{}
 	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&sa); err != nil {
@@ -92,6 +187,14 @@ func NewClusterRoleBinding(manifest io.Reader) (*rbacv1.ClusterRoleBinding, erro
 	return &crb, nil
 }
 
+func NewRoleBinding(manifest io.Reader) (*rbacv1.RoleBinding, error) {
+	rb := rbacv1.RoleBinding{}
+	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&rb); err != nil {
+		return nil, err
+	}
+	return &rb, nil
+}
+
 func NewDeployment(manifest io.Reader) (*appsv1.Deployment, error) {
 	deploy := appsv1.Deployment{}
 	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&deploy); err != nil {
@@ -100,6 +203,30 @@ func NewDeployment(manifest io.Reader) (*appsv1.Deployment, error) {
 	return &deploy, nil
 }
 
+func NewJob(manifest io.Reader) (*batchv1.Job, error) {
+	job := batchv1.Job{}
+	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func NewHorizontalPodAutoscaler(manifest io.Reader) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	hpa := autoscalingv1.HorizontalPodAutoscaler{}
+	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&hpa); err != nil {
+		return nil, err
+	}
+	return &hpa, nil
+}
+
+func NewNetworkPolicy(manifest io.Reader) (*networkingv1.NetworkPolicy, error) {
+	np := networkingv1.NetworkPolicy{}
+	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&np); err != nil {
+		return nil, err
+	}
+	return &np, nil
+}
+
 func NewNamespace(manifest io.Reader) (*corev1.Namespace, error) {
 	ns := corev1.Namespace{}
 	if err := yaml.NewYAMLOrJSONDecoder(manifest, 100).Decode(&ns); err != nil {
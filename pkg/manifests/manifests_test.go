@@ -2,6 +2,8 @@ package manifests
 
 import (
 	"testing"
+
+	"github.com/danehans/external-dns-operator/pkg/util/slice"
 )
 
 func TestManifests(t *testing.T) {
@@ -10,4 +12,35 @@ func TestManifests(t *testing.T) {
 	ExternalDNSClusterRoleBinding()
 	ExternalDNSNamespace()
 	ExternalDNSDeployment()
+	ExternalDNSCleanupJob()
+	ExternalDNSJob()
+	ExternalDNSHorizontalPodAutoscaler()
+	ExternalDNSNetworkPolicy()
+	ExternalDNSCRDSourceClusterRole()
+	ExternalDNSCRDSourceReadOnlyClusterRole()
+	ExternalDNSCRDSourceClusterRoleBinding()
+	ExternalDNSCRDSourceRoleBinding()
+	ExternalDNSContourHTTPProxySourceClusterRole()
+	ExternalDNSContourHTTPProxySourceClusterRoleBinding()
+}
+
+func TestCRDSourceClusterRoleGrantsStatusUpdate(t *testing.T) {
+	cr := ExternalDNSCRDSourceClusterRole()
+	for _, rule := range cr.Rules {
+		if slice.ContainsString(rule.APIGroups, "externaldns.k8s.io") &&
+			slice.ContainsString(rule.Resources, "dnsendpoints/status") &&
+			slice.ContainsString(rule.Verbs, "update") {
+			return
+		}
+	}
+	t.Errorf("expected cluster role %s to grant update of dnsendpoints/status", cr.Name)
+}
+
+func TestCRDSourceReadOnlyClusterRoleDeniesStatusUpdate(t *testing.T) {
+	cr := ExternalDNSCRDSourceReadOnlyClusterRole()
+	for _, rule := range cr.Rules {
+		if slice.ContainsString(rule.Resources, "dnsendpoints/status") {
+			t.Errorf("expected cluster role %s not to grant access to dnsendpoints/status", cr.Name)
+		}
+	}
 }
@@ -4,6 +4,8 @@ import (
 	operatorv1 "github.com/danehans/api/operator/v1"
 
 	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Config is configuration for the operator and should include things like
@@ -19,9 +21,68 @@ type Config struct {
 	ExternalDNSImage string
 
 	// Credentials is the Kubernetes secret containing the cloud
-	// provider authentication credentials.
+	// provider authentication credentials. It may hold the keys of any
+	// provider, not only those of Provider.
 	Credentials *corev1.Secret
 
-	// Provider is the cloud provider running the OpenShift cluster.
+	// CredentialsSecretName is the name of the secret in Namespace the
+	// Credentials were read from. When set, the secret is watched so that
+	// rotated credentials are rolled out to the operands.
+	CredentialsSecretName string
+
+	// OperandNamespace is the namespace of the ExternalDNS controller
+	// deployments and their supporting resources. If empty,
+	// openshift-externaldns is used.
+	OperandNamespace string
+
+	// Provider is the provider of the cloud platform running the OpenShift
+	// cluster, used by ExternalDNSes without spec.provider.type. Empty on
+	// platforms without a DNS provider.
 	Provider operatorv1.ProviderType
+
+	// RoleARN is the cloud role bound to the operand service account,
+	// used in place of static credentials when set.
+	RoleARN string
+
+	// ResolveZoneIDFromTags resolves the ID of the private zone of the
+	// default private zone ExternalDNS from its tags, instead of passing
+	// the tags to the ExternalDNS controller as a zone filter. The tags are
+	// still passed when the zone can't be resolved, e.g. without access to
+	// the AWS resource groups tagging API.
+	ResolveZoneIDFromTags bool
+
+	// VerifyZoneFilter verifies that the zone IDs in the zoneFilter of AWS
+	// ExternalDNSes exist in Route 53, reporting missing or inaccessible
+	// zones in the ZonesAvailable condition.
+	VerifyZoneFilter bool
+
+	// DetectZoneType derives the zone type of AWS ExternalDNSes from the
+	// visibility of the zones in their zoneFilter as reported by Route 53,
+	// at the cost of a Route 53 API call per zone on every reconcile.
+	DetectZoneType bool
+
+	// SyncPeriod is how often watched resources are resynced, requeueing
+	// every ExternalDNS. If nil, the controller-runtime default is used.
+	SyncPeriod *metav1.Duration
+
+	// OperandContainerName is the name of the ExternalDNS controller
+	// container in the operand deployment manifest. If empty, the name of
+	// the bundled manifest's container is used.
+	OperandContainerName string
+
+	// OperandPriorityClassName, when set, is the default priority class of
+	// the operand pods, overridden by spec.priorityClassName of an
+	// ExternalDNS. If nil, system-cluster-critical is used.
+	OperandPriorityClassName *string
+
+	// ValidateOperandFlags adds an init container to the operand pods that
+	// fails when the ExternalDNS image doesn't recognize a configured flag,
+	// at the cost of a slower operand startup. The ExternalDNS image must
+	// provide /bin/sh and grep. The default externaldnses created on AWS
+	// only cache the Route 53 hosted zones with it.
+	ValidateOperandFlags bool
+
+	// CleanupOperandNamespace deletes the operand namespace and the shared
+	// operand RBAC when the last ExternalDNS is deleted.
+	CleanupOperandNamespace bool
 }
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// azureConfigFileName is the name of the Azure provider config file.
+	azureConfigFileName = "azure.json"
+
+	// azureCloudName is the Azure cloud of the Azure DNS provider.
+	azureCloudName = "AzurePublicCloud"
+)
+
+// azureConfig is the azure.json config file of the Azure DNS provider of the
+// ExternalDNS controller.
+type azureConfig struct {
+	Cloud           string `json:"cloud"`
+	TenantID        string `json:"tenantId"`
+	SubscriptionID  string `json:"subscriptionId"`
+	ResourceGroup   string `json:"resourceGroup"`
+	Location        string `json:"location,omitempty"`
+	AADClientID     string `json:"aadClientId"`
+	AADClientSecret string `json:"aadClientSecret"`
+}
+
+// azureConfigFile returns the Azure provider config file for the service
+// principal of creds, the secret provisioned for an Azure CredentialsRequest.
+func azureConfigFile(creds *corev1.Secret) ([]byte, error) {
+	config := azureConfig{
+		Cloud:           azureCloudName,
+		TenantID:        string(creds.Data["azure_tenant_id"]),
+		SubscriptionID:  string(creds.Data["azure_subscription_id"]),
+		ResourceGroup:   string(creds.Data["azure_resourcegroup"]),
+		Location:        string(creds.Data["azure_region"]),
+		AADClientID:     string(creds.Data["azure_client_id"]),
+		AADClientSecret: string(creds.Data["azure_client_secret"]),
+	}
+	for key, value := range map[string]string{
+		"azure_tenant_id":       config.TenantID,
+		"azure_subscription_id": config.SubscriptionID,
+		"azure_resourcegroup":   config.ResourceGroup,
+		"azure_client_id":       config.AADClientID,
+		"azure_client_secret":   config.AADClientSecret,
+	} {
+		if len(value) == 0 {
+			return nil, fmt.Errorf("azure credentials are missing key %q", key)
+		}
+	}
+	return json.Marshal(config)
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAzureConfigFile(t *testing.T) {
+	creds := &corev1.Secret{
+		Data: map[string][]byte{
+			"azure_tenant_id":       []byte("tenant"),
+			"azure_subscription_id": []byte("subscription"),
+			"azure_resourcegroup":   []byte("cluster-rg"),
+			"azure_region":          []byte("centralus"),
+			"azure_client_id":       []byte("client"),
+			"azure_client_secret":   []byte("secret"),
+		},
+	}
+	file, err := azureConfigFile(creds)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config := azureConfig{}
+	if err := json.Unmarshal(file, &config); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", file, err)
+	}
+	expected := azureConfig{
+		Cloud:           azureCloudName,
+		TenantID:        "tenant",
+		SubscriptionID:  "subscription",
+		ResourceGroup:   "cluster-rg",
+		Location:        "centralus",
+		AADClientID:     "client",
+		AADClientSecret: "secret",
+	}
+	if config != expected {
+		t.Errorf("expected %+v, got %+v", expected, config)
+	}
+
+	delete(creds.Data, "azure_client_secret")
+	if _, err := azureConfigFile(creds); err == nil {
+		t.Error("expected an error for credentials without a client secret")
+	}
+}
+
+func TestDesiredExternalDNSDeploymentAzure(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.AzureProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "azure"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	podSpec := deployment.Spec.Template.Spec
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Secret == nil ||
+		podSpec.Volumes[0].Secret.SecretName != ExternalDNSCredentialsSecretNamespacedName(DefaultOperandNamespace, edns).Name {
+		t.Fatalf("expected the azure config secret volume, got %v", podSpec.Volumes)
+	}
+	container := operandContainer(&podSpec, defaultOperandContainerName)
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != operandCredentialsMountPath {
+		t.Errorf("expected the azure config mounted at %s, got %v", operandCredentialsMountPath, container.VolumeMounts)
+	}
+	for _, expected := range []string{"--provider=azure", "--azure-config-file=/etc/externaldns/cloud-credentials/azure.json"} {
+		found := false
+		for _, arg := range container.Args {
+			found = found || arg == expected
+		}
+		if !found {
+			t.Errorf("expected arg %q in %v", expected, container.Args)
+		}
+	}
+}
+
+func TestOperandCredentialsHash(t *testing.T) {
+	creds := &corev1.Secret{
+		Data: map[string][]byte{
+			"azure_tenant_id":       []byte("tenant"),
+			"azure_subscription_id": []byte("subscription"),
+			"azure_resourcegroup":   []byte("group"),
+			"azure_client_id":       []byte("client"),
+			"azure_client_secret":   []byte("secret"),
+		},
+	}
+	hash := operandCredentialsHash(operatorv1.AzureProvider, creds)
+	if len(hash) == 0 {
+		t.Fatal("expected a hash of the azure config file")
+	}
+	rotated := creds.DeepCopy()
+	rotated.Data["azure_client_secret"] = []byte("rotated")
+	if operandCredentialsHash(operatorv1.AzureProvider, rotated) == hash {
+		t.Error("expected rotated credentials to change the hash")
+	}
+	if actual := operandCredentialsHash(operatorv1.AWSProvider, creds); len(actual) != 0 {
+		t.Errorf("expected no hash for credentials that aren't mounted, got %q", actual)
+	}
+}
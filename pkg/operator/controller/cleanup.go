@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	"github.com/sirupsen/logrus"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ensureExternalDNSRecordsCleanedUp runs a one-shot operand that deletes all
+// records owned by edns. It returns true once the cleanup has completed and
+// its Job has been removed.
+func (r *reconciler) ensureExternalDNSRecordsCleanedUp(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS,
+	infraConfig *configv1.Infrastructure) (bool, error) {
+	desired := r.desiredExternalDNSCleanupJob(edns, dnsConfig, infraConfig)
+	current := &batchv1.Job{}
+	if err := r.kclient.Get(context.TODO(), ExternalDNSCleanupJobNamespacedName(r.OperandNamespace, edns), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get record cleanup job %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.kclient.Create(context.TODO(), desired); err != nil {
+			return false, fmt.Errorf("failed to create record cleanup job %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		logrus.Infof("created record cleanup job %s/%s", desired.Namespace, desired.Name)
+		return false, nil
+	}
+
+	for _, c := range current.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			if err := r.kclient.Delete(context.TODO(), current, kclient.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+				return false, fmt.Errorf("failed to delete record cleanup job %s/%s: %v", current.Namespace, current.Name, err)
+			}
+			logrus.Infof("completed record cleanup for externaldns %s", edns.Name)
+			return true, nil
+		case batchv1.JobFailed:
+			return false, fmt.Errorf("record cleanup job %s/%s failed: %s; set spec.cleanupRecordsOnDeletion to false to skip the cleanup",
+				current.Namespace, current.Name, c.Message)
+		}
+	}
+	logrus.Infof("waiting for record cleanup job %s/%s to complete", current.Namespace, current.Name)
+	return false, nil
+}
+
+// desiredExternalDNSCleanupJob returns the Job that removes the records owned
+// by edns. It runs the operand once with the sync policy against a source
+// that yields no endpoints, so every owned record is deleted.
+func (r *reconciler) desiredExternalDNSCleanupJob(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS,
+	infraConfig *configv1.Infrastructure) *batchv1.Job {
+	// The cleanup runs a single pod, which must not wait for the txt owner
+	// id lease of scaled operands.
+	single := edns.DeepCopy()
+	single.Spec.Replicas = nil
+	single.Spec.Autoscaling = nil
+	deployment := r.desiredExternalDNSDeployment(single, r.Config.ExternalDNSImage, dnsConfig, infraConfig)
+
+	job := manifests.ExternalDNSCleanupJob()
+	name := ExternalDNSCleanupJobNamespacedName(r.OperandNamespace, edns)
+	job.Name = name.Name
+	job.Namespace = name.Namespace
+	job.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	job.Spec.Template = *deployment.Spec.Template.DeepCopy()
+	job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	// The operand runs once, so don't select the pods of the deployment.
+	job.Spec.Template.Labels = nil
+	job.Spec.Template.Spec.Affinity = nil
+
+	container := operandContainer(&job.Spec.Template.Spec, r.OperandContainerName)
+	container.LivenessProbe = nil
+	container.ReadinessProbe = nil
+	args := []string{}
+	for _, arg := range container.Args {
+		switch {
+		case strings.HasPrefix(arg, "--source="), strings.HasPrefix(arg, "--namespace="), strings.HasPrefix(arg, "--policy="):
+			continue
+		}
+		args = append(args, arg)
+	}
+	// The operand namespace contains no services, so the source yields no
+	// endpoints and the sync policy deletes every owned record.
+	container.Args = append(args, "--source=service", "--namespace="+name.Namespace, "--policy=sync", "--once")
+
+	return job
+}
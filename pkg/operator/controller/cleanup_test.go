@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDesiredExternalDNSCleanupJobWithoutLease(t *testing.T) {
+	r := &reconciler{Config: Config{OperandNamespace: DefaultOperandNamespace, OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	three := int32(3)
+	testCases := []struct {
+		description string
+		spec        operatorv1.ExternalDNSSpec
+	}{
+		{"single replica", operatorv1.ExternalDNSSpec{}},
+		{"multiple replicas", operatorv1.ExternalDNSSpec{Replicas: &three}},
+		{"autoscaled", operatorv1.ExternalDNSSpec{Autoscaling: &operatorv1.AutoscalingSpec{MaxReplicas: 3}}},
+	}
+	for _, tc := range testCases {
+		tc.spec.Sources = []*operatorv1.SourceType{&service}
+		tc.spec.CleanupRecordsOnDeletion = true
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "cleanup"},
+			Spec:       tc.spec,
+			Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		job := r.desiredExternalDNSCleanupJob(edns, &configv1.DNS{}, &configv1.Infrastructure{})
+		container := operandContainer(&job.Spec.Template.Spec, defaultOperandContainerName)
+		if len(container.Command) != 0 {
+			t.Errorf("%q: expected the cleanup to run the ExternalDNS controller directly, got command %v", tc.description, container.Command)
+		}
+		for _, v := range job.Spec.Template.Spec.Volumes {
+			if v.Name == operandLeaseVolumeName {
+				t.Errorf("%q: expected no lease volume, got %v", tc.description, v)
+			}
+		}
+		for _, m := range container.VolumeMounts {
+			if m.Name == operandLeaseVolumeName {
+				t.Errorf("%q: expected no lease volume mount, got %v", tc.description, m)
+			}
+		}
+		// The cleanup must not be left in dry run mode.
+		for _, arg := range append(container.Command, container.Args...) {
+			if arg == "--dry-run" {
+				t.Errorf("%q: expected the cleanup not to run dry, got args %v", tc.description, container.Args)
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ensureExternalDNSConfigMap ensures the ConfigMap documenting the effective
+// configuration of the operand of edns exists and is up to date, so the
+// configuration can be inspected even when no operand pod runs.
+func (r *reconciler) ensureExternalDNSConfigMap(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS,
+	infraConfig *configv1.Infrastructure) error {
+	deployment := r.desiredExternalDNSDeployment(edns, r.Config.ExternalDNSImage, dnsConfig, infraConfig)
+	args := operandContainer(&deployment.Spec.Template.Spec, r.OperandContainerName).Args
+	desired := desiredExternalDNSConfigMap(r.OperandNamespace, edns, args)
+
+	current := &corev1.ConfigMap{}
+	if err := r.kclient.Get(context.TODO(), ExternalDNSConfigMapNamespacedName(r.OperandNamespace, edns), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get externaldns configmap %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.kclient.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create externaldns configmap %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		logrus.Infof("created externaldns configmap %s/%s", desired.Namespace, desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Data, desired.Data) && reflect.DeepEqual(current.Labels, desired.Labels) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Labels = desired.Labels
+	updated.Data = desired.Data
+	if err := r.kclient.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update externaldns configmap %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	logrus.Infof("updated externaldns configmap %s/%s", updated.Namespace, updated.Name)
+	return nil
+}
+
+// ensureExternalDNSConfigMapDeleted ensures the configuration ConfigMap of
+// edns is deleted.
+func (r *reconciler) ensureExternalDNSConfigMapDeleted(edns *operatorv1.ExternalDNS) error {
+	cm := &corev1.ConfigMap{}
+	name := ExternalDNSConfigMapNamespacedName(r.OperandNamespace, edns)
+	cm.Name = name.Name
+	cm.Namespace = name.Namespace
+	if err := r.kclient.Delete(context.TODO(), cm); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete externaldns configmap %s/%s: %v", cm.Namespace, cm.Name, err)
+	}
+	return nil
+}
+
+// desiredExternalDNSConfigMap returns the ConfigMap documenting the effective
+// configuration of edns, whose operand runs with the given args.
+func desiredExternalDNSConfigMap(operandNamespace string, edns *operatorv1.ExternalDNS, args []string) *corev1.ConfigMap {
+	name := ExternalDNSConfigMapNamespacedName(operandNamespace, edns)
+	cm := &corev1.ConfigMap{}
+	cm.Name = name.Name
+	cm.Namespace = name.Namespace
+	setExternalDNSOwnerReference(cm, edns)
+	cm.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+
+	provider := ""
+	if edns.Status.ProviderType != nil {
+		provider = string(*edns.Status.ProviderType)
+	}
+	sources := []string{}
+	for _, s := range edns.Spec.Sources {
+		if s != nil {
+			sources = append(sources, string(*s))
+		}
+	}
+	zoneType := ""
+	if edns.Spec.ZoneType != nil {
+		zoneType = string(*edns.Spec.ZoneType)
+	}
+	runMode := edns.Spec.RunMode
+	if len(runMode) == 0 {
+		runMode = operatorv1.ContinuousRunMode
+	}
+
+	cm.Data = map[string]string{
+		"provider":          provider,
+		"sources":           strings.Join(sources, ","),
+		"zoneType":          zoneType,
+		"baseDomain":        edns.Status.BaseDomain,
+		"regexDomainFilter": edns.Spec.RegexDomainFilter,
+		"zones":             strings.Join(zoneFilterDescriptions(edns.Spec.Provider.ZoneFilter), "\n"),
+		"txtOwnerID":        edns.Status.TextOwnerID,
+		"runMode":           string(runMode),
+		"args":              strings.Join(args, "\n"),
+	}
+	return cm
+}
+
+// zoneFilterDescriptions returns a human-readable description of each zone
+// of zones, by ID or by sorted tags.
+func zoneFilterDescriptions(zones []*configv1.DNSZone) []string {
+	descriptions := []string{}
+	for _, z := range zones {
+		if z == nil {
+			continue
+		}
+		if len(z.ID) != 0 {
+			descriptions = append(descriptions, "id="+z.ID)
+			continue
+		}
+		tags := make([]string, 0, len(z.Tags))
+		for k, v := range z.Tags {
+			tags = append(tags, k+"="+v)
+		}
+		sort.Strings(tags)
+		descriptions = append(descriptions, "tags="+strings.Join(tags, ","))
+	}
+	return descriptions
+}
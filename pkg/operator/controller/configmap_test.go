@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDesiredExternalDNSConfigMap(t *testing.T) {
+	provider := operatorv1.AWSProvider
+	service := operatorv1.ServiceType
+	private := operatorv1.PrivateZoneType
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "mine"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:  []*operatorv1.SourceType{&service},
+			ZoneType: &private,
+			Provider: operatorv1.ProviderSpec{
+				ZoneFilter: []*configv1.DNSZone{
+					{ID: "Z123"},
+					{Tags: map[string]string{"owner": "me", "kubernetes.io/cluster/abc": "owned"}},
+				},
+			},
+		},
+		Status: operatorv1.ExternalDNSStatus{
+			BaseDomain:   "example.com",
+			ProviderType: &provider,
+			TextOwnerID:  "abc/aws/Private/ns/mine",
+		},
+	}
+	cm := desiredExternalDNSConfigMap(DefaultOperandNamespace, edns, []string{"--provider=aws", "--source=service"})
+	if expected := ExternalDNSConfigMapNamespacedName(DefaultOperandNamespace, edns); cm.Name != expected.Name || cm.Namespace != expected.Namespace {
+		t.Errorf("expected %s, got %s/%s", expected, cm.Namespace, cm.Name)
+	}
+	if owner := cm.Labels[manifests.OwningExternalDNSLabel]; owner != edns.Name {
+		t.Errorf("expected owning externaldns label %q, got %q", edns.Name, owner)
+	}
+	expected := map[string]string{
+		"provider":          "aws",
+		"sources":           "service",
+		"zoneType":          string(private),
+		"baseDomain":        "example.com",
+		"regexDomainFilter": "",
+		"zones":             "id=Z123\ntags=kubernetes.io/cluster/abc=owned,owner=me",
+		"txtOwnerID":        "abc/aws/Private/ns/mine",
+		"runMode":           string(operatorv1.ContinuousRunMode),
+		"args":              "--provider=aws\n--source=service",
+	}
+	for k, v := range expected {
+		if cm.Data[k] != v {
+			t.Errorf("expected %s %q, got %q", k, v, cm.Data[k])
+		}
+	}
+}
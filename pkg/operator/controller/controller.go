@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	operatorv1 "github.com/danehans/api/operator/v1"
 	configv1 "github.com/openshift/api/config/v1"
@@ -16,8 +17,11 @@ import (
 	"github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
@@ -44,6 +48,15 @@ const (
 
 	// Unknown release version
 	UnknownReleaseVersionName = "unknown"
+
+	// ServiceAccountRoleARNAnnotation is the annotation used to bind a
+	// cloud role to the operand service account.
+	ServiceAccountRoleARNAnnotation = "eks.amazonaws.com/role-arn"
+
+	// textOwnerMigrationWindow is how long the ExternalDNS controller
+	// re-adopts records of a previous txt owner id. It spans many sync
+	// intervals of the ExternalDNS controller.
+	textOwnerMigrationWindow = 30 * time.Minute
 )
 
 // New creates the operator controller from configuration. This is the
@@ -57,6 +70,16 @@ func New(mgr manager.Manager, config Config) (controller.Controller, error) {
 		return nil, fmt.Errorf("failed to create kube kclient: %v", err)
 	}
 
+	if len(config.OperandNamespace) == 0 {
+		config.OperandNamespace = DefaultOperandNamespace
+	}
+	if len(config.OperandContainerName) == 0 {
+		config.OperandContainerName = defaultOperandContainerName
+	}
+	if operandContainer(&manifests.ExternalDNSDeployment().Spec.Template.Spec, config.OperandContainerName) == nil {
+		return nil, fmt.Errorf("operand deployment manifest has no container named %q", config.OperandContainerName)
+	}
+
 	reconciler := &reconciler{
 		Config:  config,
 		kclient: kubeClient,
@@ -68,6 +91,15 @@ func New(mgr manager.Manager, config Config) (controller.Controller, error) {
 	if err := c.Watch(&source.Kind{Type: &operatorv1.ExternalDNS{}}, &handler.EnqueueRequestForObject{}); err != nil {
 		return nil, err
 	}
+	if len(config.CredentialsSecretName) != 0 {
+		// Requeue every externaldns when the credentials change, since
+		// all the operands use them.
+		if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(reconciler.externalDNSesForCredentials),
+		}); err != nil {
+			return nil, err
+		}
+	}
 
 	return c, nil
 }
@@ -78,6 +110,47 @@ type Config struct {
 	Namespace        string
 	ExternalDNSImage string
 	Credentials      *corev1.Secret
+	RoleARN          string
+
+	// CredentialsSecretName, when set, is the name of the secret in
+	// Namespace holding the Credentials. The secret is watched and re-read
+	// on every reconcile, so rotated credentials reach the operands.
+	CredentialsSecretName string
+
+	// OperandNamespace is the namespace of the operand resources of all
+	// externaldnses. If empty, it defaults to DefaultOperandNamespace.
+	OperandNamespace string
+
+	// OperandContainerName is the name of the ExternalDNS controller
+	// container in the operand deployment manifest. If empty, it defaults
+	// to defaultOperandContainerName.
+	OperandContainerName string
+
+	// OperandPriorityClassName, when set, is the priority class of the
+	// operand pods of externaldnses that don't specify one, in place of the
+	// priority class of the operand deployment manifest. An empty name
+	// disables pod priority.
+	OperandPriorityClassName *string
+
+	// ValidateOperandFlags, when set, adds an init container to the operand
+	// pods checking that the ExternalDNS image recognizes the configured
+	// flags, so unsupported flags fail fast instead of crash-looping. The
+	// init container needs /bin/sh and grep in the ExternalDNS image.
+	ValidateOperandFlags bool
+
+	// CleanupOperandNamespace, when set, deletes the operand namespace and
+	// the shared cluster RBAC of the operands when the last externaldns is
+	// deleted.
+	CleanupOperandNamespace bool
+
+	// ZoneChecker, when set, is used to verify that the zones in the
+	// zoneFilter of an AWS externaldns exist.
+	ZoneChecker ZoneChecker
+
+	// ZoneTypeDetector, when set, is used to derive the zone type of an AWS
+	// externaldns from the visibility of the zones in its zoneFilter,
+	// overriding a declared zoneType that doesn't match them.
+	ZoneTypeDetector ZoneTypeDetector
 }
 
 // reconciler handles the actual externaldns reconciliation logic in response to
@@ -116,6 +189,7 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 	}
 
 	if edns != nil {
+		r.refreshCredentials()
 		dnsConfig := &configv1.DNS{}
 		if err := r.kclient.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, dnsConfig); err != nil {
 			errs = append(errs, fmt.Errorf("failed to get dns.config 'cluster': %v", err))
@@ -132,22 +206,36 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 				errs = append(errs, fmt.Errorf("failed to ensure externaldns namespace: %v", err))
 			} else if err := r.enforceEffectiveSourceType(edns); err != nil {
 				errs = append(errs, fmt.Errorf("failed to enforce the effective sourceType for %s: %v", edns.Name, err))
+			} else if err := r.enforceEffectivePolicy(edns); err != nil {
+				errs = append(errs, fmt.Errorf("failed to enforce the effective policy for %s: %v", edns.Name, err))
 			} else if err := r.enforceEffectiveBaseDomain(edns, dnsConfig); err != nil {
 				errs = append(errs, fmt.Errorf("failed to enforce the effective externaldns baseDomain for %s: %v", edns.Name, err))
 			} else if IsStatusBaseDomainSet(edns) {
-				if err := r.enforceEffectiveProvider(edns, infraConfig); err != nil {
+				if err := r.enforceEffectiveProvider(edns); err != nil {
 					errs = append(errs, fmt.Errorf("failed to enforce the effective provider for externaldns %s: %v", edns.Name, err))
 				} else if err := r.enforceEffectiveZoneFilter(edns, dnsConfig); err != nil {
 					errs = append(errs, fmt.Errorf("failed to enforce the effective zoneFilter for externaldns %s: %v", edns.Name, err))
+				} else if requeueAfter, err := r.enforceEffectiveTextOwnerID(edns, infraConfig); err != nil {
+					errs = append(errs, fmt.Errorf("failed to enforce the effective txt owner id for externaldns %s: %v", edns.Name, err))
 				} else if edns.DeletionTimestamp != nil {
 					// Handle deletion.
-					if err := r.ensureExternalDNSDeleted(edns); err != nil {
+					if err := r.ensureExternalDNSDeleted(edns, dnsConfig, infraConfig); err != nil {
 						errs = append(errs, fmt.Errorf("failed to ensure deletion for externaldns %s: %v", edns.Name, err))
 					}
 				} else if err := r.enforceExternalDNSFinalizer(edns); err != nil {
 					errs = append(errs, fmt.Errorf("failed to enforce finalizer for externaldns %s: %v", edns.Name, err))
+				} else if err := validateExternalDNS(edns); err != nil {
+					// The configuration can't succeed until the spec
+					// changes, which queues the externaldns again, so
+					// report it in status instead of retrying.
+					logrus.Errorf("invalid configuration for externaldns %s: %v", edns.Name, err)
+					invalid := invalidConfigurationCondition(err)
+					if err := r.syncExternalDNSStatus(edns, []operatorv1.OperatorCondition{*invalid}); err != nil {
+						errs = append(errs, fmt.Errorf("failed to report invalid configuration for externaldns %s: %v", edns.Name, err))
+					}
 				} else {
 					// Handle everything else.
+					result.RequeueAfter = requeueAfter
 					if err := r.ensureExternalDNS(edns, dnsConfig, infraConfig); err != nil {
 						errs = append(errs, fmt.Errorf("failed to ensure dns %s: %v", edns.Name, err))
 					}
@@ -165,10 +253,36 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 	return result, utilerrors.NewAggregate(errs)
 }
 
+// desiredExternalDNSNamespace returns the operand namespace.
+func desiredExternalDNSNamespace(operandNamespace string) *corev1.Namespace {
+	ns := manifests.ExternalDNSNamespace()
+	ns.Name = operandNamespace
+	return ns
+}
+
+// desiredExternalDNSServiceAccount returns the service account of the
+// operands in operandNamespace.
+func desiredExternalDNSServiceAccount(operandNamespace string) *corev1.ServiceAccount {
+	sa := manifests.ExternalDNSServiceAccount()
+	sa.Namespace = operandNamespace
+	return sa
+}
+
+// desiredExternalDNSClusterRoleBinding returns the ClusterRoleBinding
+// granting the service account of the operands in operandNamespace the
+// shared operand ClusterRole. It is named after the operand namespace, so
+// that operators with different operand namespaces don't share it.
+func desiredExternalDNSClusterRoleBinding(operandNamespace string) *rbacv1.ClusterRoleBinding {
+	crb := manifests.ExternalDNSClusterRoleBinding()
+	crb.Name = operandNamespace
+	crb.Subjects[0].Namespace = operandNamespace
+	return crb
+}
+
 // ensureExternalDNSNamespace ensures all the necessary scaffolding exists
 // for externaldns generally, including a namespace and all RBAC setup.
 func (r *reconciler) ensureExternalDNSNamespace(edns *operatorv1.ExternalDNS) error {
-	ns := manifests.ExternalDNSNamespace()
+	ns := desiredExternalDNSNamespace(r.OperandNamespace)
 	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Name: ns.Name}, ns); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get externaldns namespace %q: %v", ns.Name, err)
@@ -177,6 +291,10 @@ func (r *reconciler) ensureExternalDNSNamespace(edns *operatorv1.ExternalDNS) er
 			return fmt.Errorf("failed to create externaldns namespace %s: %v", ns.Name, err)
 		}
 		logrus.Infof("created externaldns namespace: %s", ns.Name)
+	} else if ns.DeletionTimestamp != nil {
+		// The namespace is being cleaned up after the deletion of the last
+		// externaldns; retry until it is gone to recreate it.
+		return fmt.Errorf("externaldns namespace %s is terminating", ns.Name)
 	}
 
 	cr := manifests.ExternalDNSClusterRole()
@@ -190,7 +308,7 @@ func (r *reconciler) ensureExternalDNSNamespace(edns *operatorv1.ExternalDNS) er
 		logrus.Infof("created externaldns cluster role: %s", cr.Name)
 	}
 
-	crb := manifests.ExternalDNSClusterRoleBinding()
+	crb := desiredExternalDNSClusterRoleBinding(r.OperandNamespace)
 	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Name: crb.Name}, crb); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get externaldns cluster role binding %s: %v", crb.Name, err)
@@ -201,20 +319,86 @@ func (r *reconciler) ensureExternalDNSNamespace(edns *operatorv1.ExternalDNS) er
 		logrus.Infof("created externaldns cluster role binding: %s", crb.Name)
 	}
 
-	sa := manifests.ExternalDNSServiceAccount()
+	sa := desiredExternalDNSServiceAccount(r.OperandNamespace)
 	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Namespace: sa.Namespace, Name: sa.Name}, sa); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get externaldns service account %s/%s: %v", sa.Namespace, sa.Name, err)
 		}
+		if len(r.RoleARN) != 0 {
+			sa.Annotations = map[string]string{ServiceAccountRoleARNAnnotation: r.RoleARN}
+		}
 		if err := r.kclient.Create(context.TODO(), sa); err != nil {
 			return fmt.Errorf("failed to create externaldns service account %s/%s: %v", sa.Namespace, sa.Name, err)
 		}
 		logrus.Infof("created externaldns service account: %s/%s", sa.Namespace, sa.Name)
 	}
+	if len(r.RoleARN) != 0 && sa.Annotations[ServiceAccountRoleARNAnnotation] != r.RoleARN {
+		updated := sa.DeepCopy()
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[ServiceAccountRoleARNAnnotation] = r.RoleARN
+		if err := r.kclient.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update externaldns service account %s/%s: %v", sa.Namespace, sa.Name, err)
+		}
+		logrus.Infof("updated role annotation of externaldns service account: %s/%s", sa.Namespace, sa.Name)
+	}
+
+	return nil
+}
+
+// ensureExternalDNSNamespaceDeletedIfUnused deletes the scaffolding created
+// by ensureExternalDNSNamespace, and the shared source cluster roles, when
+// edns is the last externaldns. Reconciles are serialized, so an externaldns
+// created meanwhile is reconciled afterwards and waits for the namespace to
+// terminate before recreating the scaffolding.
+func (r *reconciler) ensureExternalDNSNamespaceDeletedIfUnused(edns *operatorv1.ExternalDNS) error {
+	dnses := &operatorv1.ExternalDNSList{}
+	if err := r.kclient.List(context.TODO(), dnses, kclient.InNamespace(r.Namespace)); err != nil {
+		return fmt.Errorf("failed to list externaldnses: %v", err)
+	}
+	if others := otherExternalDNSNames(dnses.Items, edns); len(others) != 0 {
+		logrus.Infof("keeping externaldns namespace in use by externaldnses: %v", others)
+		return nil
+	}
 
+	sa := desiredExternalDNSServiceAccount(r.OperandNamespace)
+	crb := desiredExternalDNSClusterRoleBinding(r.OperandNamespace)
+	ns := desiredExternalDNSNamespace(r.OperandNamespace)
+	for _, obj := range []runtime.Object{
+		sa,
+		crb,
+		manifests.ExternalDNSClusterRole(),
+		manifests.ExternalDNSCRDSourceClusterRole(),
+		manifests.ExternalDNSCRDSourceReadOnlyClusterRole(),
+		manifests.ExternalDNSContourHTTPProxySourceClusterRole(),
+		ns,
+	} {
+		if err := r.kclient.Delete(context.TODO(), obj, kclient.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to delete %T: %v", obj, err)
+		}
+	}
+	logrus.Infof("deleted externaldns namespace %s and its shared rbac after deleting the last externaldns %s", ns.Name, edns.Name)
 	return nil
 }
 
+// otherExternalDNSNames returns the names of the externaldnses in dnses other
+// than edns, including those being deleted, which may still need the
+// namespace to clean up their records.
+func otherExternalDNSNames(dnses []operatorv1.ExternalDNS, edns *operatorv1.ExternalDNS) []string {
+	names := []string{}
+	for _, dns := range dnses {
+		if dns.UID == edns.UID && dns.Name == edns.Name {
+			continue
+		}
+		names = append(names, dns.Name)
+	}
+	return names
+}
+
 // enforceEffectiveSourceType determines the effective sourceType for
 // the given edns.
 func (r *reconciler) enforceEffectiveSourceType(edns *operatorv1.ExternalDNS) error {
@@ -232,6 +416,23 @@ func (r *reconciler) enforceEffectiveSourceType(edns *operatorv1.ExternalDNS) er
 	return nil
 }
 
+// enforceEffectivePolicy defaults the policy of the given edns to
+// upsert-only, so records aren't deleted unless explicitly requested.
+func (r *reconciler) enforceEffectivePolicy(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.Policy != nil {
+		return nil
+	}
+	upsertOnly := operatorv1.UpsertOnlyPolicyType
+	updated := edns.DeepCopy()
+	updated.Spec.Policy = &upsertOnly
+
+	if err := r.kclient.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update ExternalDNS %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+
+	return nil
+}
+
 // enforceEffectiveZoneType determines the effective zoneType for
 // the given edns.
 func (r *reconciler) enforceEffectiveZoneType(edns *operatorv1.ExternalDNS) error {
@@ -253,7 +454,8 @@ func (r *reconciler) enforceEffectiveZoneType(edns *operatorv1.ExternalDNS) erro
 // given edns and publishes it to edns's status.
 func (r *reconciler) enforceEffectiveBaseDomain(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS) error {
 	// An externaldns' baseDomain is immutable, so if has
-	// been published to status, continue using it.
+	// been published to status, continue using it. A changed
+	// spec.baseDomain is reported through the Degraded condition.
 	if IsStatusBaseDomainSet(edns) {
 		return nil
 	}
@@ -266,16 +468,15 @@ func (r *reconciler) enforceEffectiveBaseDomain(edns *operatorv1.ExternalDNS, dn
 	default:
 		domain = dnsConfig.Spec.BaseDomain
 	}
-	unique, err := r.isBaseDomainUniqueForZoneType(domain, edns)
+	conflict, err := r.baseDomainConflictForZoneType(domain, edns)
 	if err != nil {
 		return err
 	}
-	if !unique {
+	if conflict != nil {
 		logrus.Infof("baseDomain not unique, not setting ExternalDNS .status.baseDomain for %s/%s", edns.Namespace, edns.Name)
-		return nil
-	} else {
-		updated.Status.BaseDomain = domain
+		return r.syncExternalDNSStatus(edns, []operatorv1.OperatorCondition{*duplicateBaseDomainCondition(domain, conflict)})
 	}
+	updated.Status.BaseDomain = domain
 
 	if err := r.kclient.Status().Update(context.TODO(), updated); err != nil {
 		return fmt.Errorf("failed to update status of ExternalDNS %s/%s: %v", updated.Namespace, updated.Name, err)
@@ -284,24 +485,35 @@ func (r *reconciler) enforceEffectiveBaseDomain(edns *operatorv1.ExternalDNS, dn
 	return nil
 }
 
-// isBaseDomainUnique compares baseDomain with spec.domain of all
-// externalDNSes and returns false if a conflict exists of the same
-// ZoneType or an error if the externalDNS list operation returns an error.
-func (r *reconciler) isBaseDomainUniqueForZoneType(domain string, edns *operatorv1.ExternalDNS) (bool, error) {
+// baseDomainConflictForZoneType compares baseDomain with status.baseDomain
+// of all externalDNSes and returns the externalDNS of the same ZoneType it
+// conflicts with, if any, or an error if the externalDNS list operation
+// returns an error.
+func (r *reconciler) baseDomainConflictForZoneType(domain string, edns *operatorv1.ExternalDNS) (*operatorv1.ExternalDNS, error) {
 	dnses := &operatorv1.ExternalDNSList{}
 	if err := r.kclient.List(context.TODO(), dnses, kclient.InNamespace(r.Namespace)); err != nil {
-		return false, fmt.Errorf("failed to list externaldnses: %v", err)
+		return nil, fmt.Errorf("failed to list externaldnses: %v", err)
 	}
 
 	// Compare domain with all externaldnses for a conflict.
-	for _, dns := range dnses.Items {
-		if domain == dns.Status.BaseDomain && dns.Spec.ZoneType == edns.Spec.ZoneType {
+	for i := range dnses.Items {
+		dns := &dnses.Items[i]
+		if domain == dns.Status.BaseDomain && zoneTypesEqual(dns.Spec.ZoneType, edns.Spec.ZoneType) {
 			logrus.Infof("baseDomain %q conflicts with existing ExternalDNS: %s/%s", domain, dns.Namespace, dns.Name)
-			return false, nil
+			return dns, nil
 		}
 	}
 
-	return true, nil
+	return nil, nil
+}
+
+// zoneTypesEqual returns true if a and b are both unset or are the same
+// zone type.
+func zoneTypesEqual(a, b *operatorv1.ZoneType) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 // IsStatusBaseDomainSet checks whether status.baseDomain of edns is set.
@@ -312,12 +524,13 @@ func IsStatusBaseDomainSet(edns *operatorv1.ExternalDNS) bool {
 	return true
 }
 
-// providerTypeForInfra returns the appropriate provider
-// type for the given infraConfig.
-func providerTypeForInfra(infraConfig *configv1.Infrastructure) *operatorv1.ProviderType {
+// ProviderTypeForPlatform returns the provider type of externaldnses
+// without spec.provider.type on the given platform, or an empty provider
+// type for platforms without a DNS provider.
+func ProviderTypeForPlatform(platform configv1.PlatformType) operatorv1.ProviderType {
 	var provider operatorv1.ProviderType
 
-	switch infraConfig.Status.Platform {
+	switch platform {
 	case configv1.AWSPlatformType:
 		provider = operatorv1.AWSProvider
 	case configv1.AzurePlatformType:
@@ -326,7 +539,7 @@ func providerTypeForInfra(infraConfig *configv1.Infrastructure) *operatorv1.Prov
 		provider = operatorv1.GoogleProvider
 	}
 
-	return &provider
+	return provider
 }
 
 // enforceEffectiveZoneFilter uses the dnsConfig to determine the
@@ -336,6 +549,10 @@ func (r *reconciler) enforceEffectiveZoneFilter(edns *operatorv1.ExternalDNS, dn
 	switch {
 	case edns.Spec.Provider.ZoneFilter != nil:
 		return nil
+	case dnsConfig.Spec.PrivateZone == nil:
+		// Without a private zone, the zones are only filtered by the
+		// base domain.
+		return nil
 	default:
 		updated.Spec.Provider.ZoneFilter = []*configv1.DNSZone{dnsConfig.Spec.PrivateZone}
 	}
@@ -349,7 +566,7 @@ func (r *reconciler) enforceEffectiveZoneFilter(edns *operatorv1.ExternalDNS, dn
 // enforceEffectiveProvider uses the infrastructure config to
 // determine the appropriate provider configuration for the
 // given edns and publishes it to the externaldns' status.
-func (r *reconciler) enforceEffectiveProvider(edns *operatorv1.ExternalDNS, infraConfig *configv1.Infrastructure) error {
+func (r *reconciler) enforceEffectiveProvider(edns *operatorv1.ExternalDNS) error {
 	// The externaldns' provider is immutable, so
 	// if we have previously published a strategy in status, we must
 	// continue to use that strategy it.
@@ -362,7 +579,12 @@ func (r *reconciler) enforceEffectiveProvider(edns *operatorv1.ExternalDNS, infr
 	case edns.Spec.Provider.Type != nil:
 		updated.Status.ProviderType = edns.Spec.Provider.Type
 	default:
-		updated.Status.ProviderType = providerTypeForInfra(infraConfig)
+		platform, err := PlatformType(r.kclient)
+		if err != nil {
+			return err
+		}
+		provider := ProviderTypeForPlatform(platform)
+		updated.Status.ProviderType = &provider
 	}
 	if err := r.kclient.Status().Update(context.TODO(), updated); err != nil {
 		return fmt.Errorf("failed to update status of externaldns %s/%s: %v", updated.Namespace, updated.Name, err)
@@ -379,6 +601,81 @@ func IsStatusProviderSet(edns *operatorv1.ExternalDNS) bool {
 	return false
 }
 
+// enforceEffectiveTextOwnerID publishes the txt owner id of edns to its
+// status. When the owner id differs from the one previously published,
+// e.g. after an operator upgrade changed its format, the previous owner id
+// is kept in status for textOwnerMigrationWindow so the ExternalDNS
+// controller re-adopts the records it owns instead of orphaning them. The
+// ExternalDNS controller re-adopts the records of a single previous owner
+// id, so an owner id change during a migration is only published once the
+// migration completes. An externaldns without a published owner id was
+// last reconciled by an operator using the legacy owner id, so its records
+// are migrated from the legacy owner id. The returned duration is the time
+// left in an ongoing migration.
+func (r *reconciler) enforceEffectiveTextOwnerID(edns *operatorv1.ExternalDNS, infraConfig *configv1.Infrastructure) (time.Duration, error) {
+	owner := TextOwnerID(infraConfig, edns)
+	updated := edns.DeepCopy()
+	var remaining time.Duration
+	switch {
+	case len(edns.Status.TextOwnerID) == 0:
+		updated.Status.TextOwnerID = owner
+		// Owner ids were not published before, so the records are
+		// owned by the legacy owner id.
+		if legacy := legacyTextOwnerID(infraConfig, edns); legacy != owner {
+			logrus.Infof("txt owner id of externaldns %s changed from the legacy %q to %q; migrating records",
+				edns.Name, legacy, owner)
+			updated.Status.PreviousTextOwnerID = legacy
+			updated.Status.Conditions = setExternalDNSCondition(updated.Status.Conditions, operatorv1.OperatorCondition{
+				Type:    operatorv1.TextOwnerMigratingConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "OwnerIDChanged",
+				Message: fmt.Sprintf("Re-adopting records owned by %q.", legacy),
+			})
+			remaining = textOwnerMigrationWindow
+		}
+	case len(edns.Status.PreviousTextOwnerID) != 0:
+		if c := findExternalDNSCondition(edns.Status.Conditions, operatorv1.TextOwnerMigratingConditionType); c != nil {
+			remaining = textOwnerMigrationWindow - time.Since(c.LastTransitionTime.Time)
+		}
+		if remaining > 0 {
+			if edns.Status.TextOwnerID != owner {
+				logrus.Infof("txt owner id of externaldns %s changed to %q during the migration from %q; deferring until it completes",
+					edns.Name, owner, edns.Status.PreviousTextOwnerID)
+			}
+			return remaining, nil
+		}
+		// A deferred owner id change starts its own migration on the
+		// reconcile following this status update.
+		logrus.Infof("txt owner id migration of externaldns %s from %q completed", edns.Name, edns.Status.PreviousTextOwnerID)
+		updated.Status.PreviousTextOwnerID = ""
+		updated.Status.Conditions = setExternalDNSCondition(updated.Status.Conditions, operatorv1.OperatorCondition{
+			Type:   operatorv1.TextOwnerMigratingConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "MigrationComplete",
+		})
+		remaining = 0
+	case edns.Status.TextOwnerID != owner:
+		logrus.Infof("txt owner id of externaldns %s changed from %q to %q; migrating records",
+			edns.Name, edns.Status.TextOwnerID, owner)
+		updated.Status.PreviousTextOwnerID = edns.Status.TextOwnerID
+		updated.Status.TextOwnerID = owner
+		updated.Status.Conditions = setExternalDNSCondition(updated.Status.Conditions, operatorv1.OperatorCondition{
+			Type:    operatorv1.TextOwnerMigratingConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "OwnerIDChanged",
+			Message: fmt.Sprintf("Re-adopting records owned by %q.", edns.Status.TextOwnerID),
+		})
+		remaining = textOwnerMigrationWindow
+	default:
+		return 0, nil
+	}
+	if err := r.kclient.Status().Update(context.TODO(), updated); err != nil {
+		return 0, fmt.Errorf("failed to update status of externaldns %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+
+	return remaining, nil
+}
+
 // enforceExternalDNSFinalizer adds ExternalDNSControllerFinalizer to externaldns
 // if it doesn't exist.
 func (r *reconciler) enforceExternalDNSFinalizer(edns *operatorv1.ExternalDNS) error {
@@ -405,11 +702,69 @@ func (r *reconciler) removeExternalDNSFinalizer(edns *operatorv1.ExternalDNS) er
 	return nil
 }
 
+// setExternalDNSOwnerReference makes edns the controller owner of obj, so
+// that obj is garbage collected along with edns, and returns true. Owner
+// references can't cross namespaces and a namespaced owner can't own a
+// cluster-scoped object, so obj is left unchanged and false is returned
+// unless it lives in the namespace of edns. The operand resources in the
+// operand namespace and the cluster-scoped bindings therefore rely on
+// ensureExternalDNSDeleted, run before ExternalDNSControllerFinalizer is
+// removed, to be cleaned up.
+func setExternalDNSOwnerReference(obj metav1.Object, edns *operatorv1.ExternalDNS) bool {
+	if len(obj.GetNamespace()) == 0 || obj.GetNamespace() != edns.Namespace {
+		return false
+	}
+	owner := metav1.NewControllerRef(edns, operatorv1.GroupVersion.WithKind("ExternalDNS"))
+	obj.SetOwnerReferences([]metav1.OwnerReference{*owner})
+	return true
+}
+
 // ensureExternalDNSDeleted tries to delete externaldns dependent resources.
-func (r *reconciler) ensureExternalDNSDeleted(edns *operatorv1.ExternalDNS) error {
+func (r *reconciler) ensureExternalDNSDeleted(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS,
+	infraConfig *configv1.Infrastructure) error {
 	if err := r.ensureExternalDNSDeploymentDeleted(edns); err != nil {
 		return fmt.Errorf("failed to delete deployment for externaldns %s: %v", edns.Name, err)
 	}
+	if err := r.ensureExternalDNSJobDeleted(edns); err != nil {
+		return fmt.Errorf("failed to delete job for externaldns %s: %v", edns.Name, err)
+	}
+	if err := r.ensureExternalDNSHPADeleted(edns); err != nil {
+		return fmt.Errorf("failed to delete horizontal pod autoscaler for externaldns %s: %v", edns.Name, err)
+	}
+	if err := r.ensureExternalDNSConfigMapDeleted(edns); err != nil {
+		return fmt.Errorf("failed to delete configmap for externaldns %s: %v", edns.Name, err)
+	}
+	if err := r.ensureExternalDNSNetworkPolicyDeleted(edns); err != nil {
+		return fmt.Errorf("failed to delete network policy for externaldns %s: %v", edns.Name, err)
+	}
+	if err := validateCleanupRecordsOnDeletion(edns); err != nil {
+		logrus.Errorf("skipping record cleanup for externaldns %s: %v", edns.Name, err)
+	} else if edns.Spec.CleanupRecordsOnDeletion {
+		done, err := r.ensureExternalDNSRecordsCleanedUp(edns, dnsConfig, infraConfig)
+		if err != nil {
+			return fmt.Errorf("failed to clean up records for externaldns %s: %v", edns.Name, err)
+		}
+		if !done {
+			// The cleanup job's status changes requeue the externaldns.
+			return nil
+		}
+	}
+	if err := r.ensureExternalDNSCRDSourceRBACDeleted(edns); err != nil {
+		return fmt.Errorf("failed to delete crd source rbac for externaldns %s: %v", edns.Name, err)
+	}
+	if err := r.ensureExternalDNSContourHTTPProxySourceRBACDeleted(edns); err != nil {
+		return fmt.Errorf("failed to delete contour httpproxy source rbac for externaldns %s: %v", edns.Name, err)
+	}
+	// The record cleanup job reads the credentials, so they're deleted last.
+	if err := r.ensureOperandCredentialsSecretDeleted(edns); err != nil {
+		return fmt.Errorf("failed to delete credentials secret for externaldns %s: %v", edns.Name, err)
+	}
+	if r.CleanupOperandNamespace {
+		// Clean up before removing the finalizer, so a failure is retried.
+		if err := r.ensureExternalDNSNamespaceDeletedIfUnused(edns); err != nil {
+			return fmt.Errorf("failed to clean up externaldns namespace: %v", err)
+		}
+	}
 	if err := r.removeExternalDNSFinalizer(edns); err != nil {
 		return fmt.Errorf("failed to remove finalizer from externaldns %s: %v", edns.Name, err)
 
@@ -421,8 +776,138 @@ func (r *reconciler) ensureExternalDNSDeleted(edns *operatorv1.ExternalDNS) erro
 // for a given externaldns.
 func (r *reconciler) ensureExternalDNS(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS,
 	infraConfig *configv1.Infrastructure) error {
-	if err := r.ensureExternalDNSDeployment(edns, dnsConfig, infraConfig); err != nil {
-		return fmt.Errorf("failed to ensure deployment for externaldns %s: %v", edns.Name, err)
+	if err := r.validateProviderEnvSecrets(edns); err != nil {
+		return err
+	}
+	if err := r.ensureExternalDNSCRDSourceRBAC(edns); err != nil {
+		return fmt.Errorf("failed to ensure crd source rbac for externaldns %s: %v", edns.Name, err)
+	}
+	if err := r.ensureExternalDNSContourHTTPProxySourceRBAC(edns); err != nil {
+		return fmt.Errorf("failed to ensure contour httpproxy source rbac for externaldns %s: %v", edns.Name, err)
+	}
+	if err := r.ensureOperandCredentialsSecret(edns); err != nil {
+		return fmt.Errorf("failed to ensure credentials secret for externaldns %s: %v", edns.Name, err)
+	}
+	switch edns.Spec.RunMode {
+	case operatorv1.OnceRunMode:
+		if err := r.ensureExternalDNSDeploymentDeleted(edns); err != nil {
+			return fmt.Errorf("failed to delete deployment for externaldns %s: %v", edns.Name, err)
+		}
+		if err := r.ensureExternalDNSJob(edns, dnsConfig, infraConfig); err != nil {
+			return fmt.Errorf("failed to ensure job for externaldns %s: %v", edns.Name, err)
+		}
+	default:
+		if err := r.ensureExternalDNSJobDeleted(edns); err != nil {
+			return fmt.Errorf("failed to delete job for externaldns %s: %v", edns.Name, err)
+		}
+		if err := r.ensureExternalDNSDeployment(edns, dnsConfig, infraConfig); err != nil {
+			err = fmt.Errorf("failed to ensure deployment for externaldns %s: %v", edns.Name, err)
+			degraded := degradedCondition(nil, err)
+			if statusErr := r.syncExternalDNSStatus(edns, []operatorv1.OperatorCondition{*degraded}); statusErr != nil {
+				logrus.Errorf("failed to report degraded externaldns %s: %v", edns.Name, statusErr)
+			}
+			return err
+		}
+		if err := r.ensureOperandLease(edns); err != nil {
+			return fmt.Errorf("failed to ensure txt owner id lease for externaldns %s: %v", edns.Name, err)
+		}
+	}
+	if err := r.ensureExternalDNSHPA(edns); err != nil {
+		return fmt.Errorf("failed to ensure horizontal pod autoscaler for externaldns %s: %v", edns.Name, err)
+	}
+	if err := r.ensureExternalDNSConfigMap(edns, dnsConfig, infraConfig); err != nil {
+		return fmt.Errorf("failed to ensure configmap for externaldns %s: %v", edns.Name, err)
+	}
+	if err := r.ensureExternalDNSNetworkPolicy(edns); err != nil {
+		return fmt.Errorf("failed to ensure network policy for externaldns %s: %v", edns.Name, err)
+	}
+
+	conditions := []operatorv1.OperatorCondition{}
+	credsCondition, err := r.computeCredentialsAvailableCondition(edns)
+	if err != nil {
+		return fmt.Errorf("failed to compute credentials condition for externaldns %s: %v", edns.Name, err)
+	}
+	if credsCondition != nil {
+		conditions = append(conditions, *credsCondition)
+	}
+	imageCondition, err := r.computeOperandImageUnavailableCondition(edns)
+	if err != nil {
+		return fmt.Errorf("failed to compute image condition for externaldns %s: %v", edns.Name, err)
+	}
+	conditions = append(conditions, *imageCondition)
+	overlapCondition, err := r.computeZoneOverlapCondition(edns)
+	if err != nil {
+		return fmt.Errorf("failed to compute zone overlap condition for externaldns %s: %v", edns.Name, err)
+	}
+	conditions = append(conditions, *overlapCondition)
+	switch edns.Spec.RunMode {
+	case operatorv1.OnceRunMode:
+		job, err := r.currentExternalDNSJob(edns)
+		if err != nil {
+			return fmt.Errorf("failed to get job of externaldns %s: %v", edns.Name, err)
+		}
+		conditions = append(conditions,
+			*operandPausedCondition(nil),
+			*operandRolloutFailedCondition(nil),
+			*jobProgressingCondition(job),
+			*jobAvailableCondition(job),
+			*externalDNSDegradedCondition(edns, nil))
+	default:
+		deployment, err := r.currentExternalDNSDeployment(edns)
+		if err != nil {
+			return fmt.Errorf("failed to get deployment of externaldns %s: %v", edns.Name, err)
+		}
+		conditions = append(conditions,
+			*operandPausedCondition(deployment),
+			*operandRolloutFailedCondition(deployment),
+			*progressingCondition(deployment),
+			*availableCondition(deployment),
+			*externalDNSDegradedCondition(edns, deployment))
+	}
+	conditions = append(conditions, *experimentalArgsInUseCondition(edns))
+	conditions = append(conditions, *excessReplicasCondition(edns))
+	if zonesCondition := r.computeZonesAvailableCondition(edns); zonesCondition != nil {
+		conditions = append(conditions, *zonesCondition)
+	}
+	if err := r.syncExternalDNSStatus(edns, conditions); err != nil {
+		return fmt.Errorf("failed to sync status of externaldns %s: %v", edns.Name, err)
 	}
 	return nil
 }
+
+// externalDNSesForCredentials returns a request for every externaldns when
+// the given object is the credentials secret.
+func (r *reconciler) externalDNSesForCredentials(o handler.MapObject) []reconcile.Request {
+	if o.Meta.GetNamespace() != r.Namespace || o.Meta.GetName() != r.CredentialsSecretName {
+		return nil
+	}
+	dnses := &operatorv1.ExternalDNSList{}
+	if err := r.kclient.List(context.TODO(), dnses, kclient.InNamespace(r.Namespace)); err != nil {
+		logrus.Errorf("failed to list externaldnses to requeue for credentials %s/%s: %v", r.Namespace, r.CredentialsSecretName, err)
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, dns := range dnses.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: dns.Namespace, Name: dns.Name},
+		})
+	}
+	logrus.Infof("queueing %d externaldnses for updated credentials %s/%s", len(requests), r.Namespace, r.CredentialsSecretName)
+	return requests
+}
+
+// refreshCredentials re-reads the credentials secret, if configured, so that
+// rotated credentials are used. The last read credentials are kept when the
+// secret can't be read.
+func (r *reconciler) refreshCredentials() {
+	if len(r.CredentialsSecretName) == 0 {
+		return
+	}
+	creds := &corev1.Secret{}
+	name := types.NamespacedName{Namespace: r.Namespace, Name: r.CredentialsSecretName}
+	if err := r.kclient.Get(context.TODO(), name, creds); err != nil {
+		logrus.Warningf("failed to get credentials %s, using the last read credentials: %v", name, err)
+		return
+	}
+	r.Credentials = creds
+}
@@ -0,0 +1,1014 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/util/slice"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestOtherExternalDNSNames(t *testing.T) {
+	edns := operatorv1.ExternalDNS{ObjectMeta: metav1.ObjectMeta{Name: "last", UID: "1"}}
+	deleting := metav1.Now()
+	other := operatorv1.ExternalDNS{ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "2", DeletionTimestamp: &deleting}}
+	if names := otherExternalDNSNames([]operatorv1.ExternalDNS{edns}, &edns); len(names) != 0 {
+		t.Errorf("expected no other externaldnses, got %v", names)
+	}
+	if names := otherExternalDNSNames([]operatorv1.ExternalDNS{edns, other}, &edns); !reflect.DeepEqual(names, []string{"other"}) {
+		t.Errorf("expected the externaldns being deleted to be counted, got %v", names)
+	}
+}
+
+// secretGetter is a client serving Get requests from secrets. Calls to
+// other methods panic.
+type secretGetter struct {
+	kclient.Client
+	secrets map[types.NamespacedName]*corev1.Secret
+}
+
+func (c *secretGetter) Get(ctx context.Context, key kclient.ObjectKey, obj runtime.Object) error {
+	secret, ok := c.secrets[key]
+	if !ok {
+		return errors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+	}
+	secret.DeepCopyInto(obj.(*corev1.Secret))
+	return nil
+}
+
+func TestRefreshCredentialsRollsOutRotatedCredentials(t *testing.T) {
+	name := types.NamespacedName{Namespace: "openshift-externaldns-operator", Name: "cloud-credentials"}
+	awsCreds := func(key string) *corev1.Secret {
+		return &corev1.Secret{Data: map[string][]byte{"aws_access_key_id": []byte(key), "aws_secret_access_key": []byte("secret")}}
+	}
+	client := &secretGetter{secrets: map[types.NamespacedName]*corev1.Secret{}}
+	r := &reconciler{
+		Config: Config{
+			Namespace:             name.Namespace,
+			Credentials:           awsCreds("old"),
+			CredentialsSecretName: name.Name,
+			OperandContainerName:  defaultOperandContainerName,
+		},
+		kclient: client,
+	}
+	service := operatorv1.ServiceType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "rotated"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	current := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+
+	// The last read credentials are kept while the secret can't be read.
+	r.refreshCredentials()
+	if string(r.Credentials.Data["aws_access_key_id"]) != "old" {
+		t.Fatalf("expected the last read credentials to be kept, got %v", r.Credentials.Data)
+	}
+
+	client.secrets[name] = awsCreds("new")
+	r.refreshCredentials()
+	expected := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+	if !changed {
+		t.Fatal("expected rotated credentials to update the deployment")
+	}
+	found := false
+	for _, env := range operandContainer(&updated.Spec.Template.Spec, defaultOperandContainerName).Env {
+		found = found || (env.Name == "AWS_ACCESS_KEY_ID" && env.Value == "new")
+	}
+	if !found {
+		t.Errorf("expected the rotated access key in the operand env")
+	}
+}
+
+// deletionRecorder is a client recording deletions and finalizer removals.
+// Only the operand deployment exists, lists are empty and calls to other
+// methods panic.
+type deletionRecorder struct {
+	kclient.Client
+	deleted []string
+	// deletedOnUpdate is the number of deletions when the externaldns is
+	// updated.
+	deletedOnUpdate int
+	updated         *operatorv1.ExternalDNS
+}
+
+func (c *deletionRecorder) Get(ctx context.Context, key kclient.ObjectKey, obj runtime.Object) error {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return errors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+	deployment.Namespace = key.Namespace
+	deployment.Name = key.Name
+	return nil
+}
+
+func (c *deletionRecorder) List(ctx context.Context, list runtime.Object, opts ...kclient.ListOptionFunc) error {
+	return nil
+}
+
+func (c *deletionRecorder) Delete(ctx context.Context, obj runtime.Object, opts ...kclient.DeleteOptionFunc) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	c.deleted = append(c.deleted, fmt.Sprintf("%T %s/%s", obj, accessor.GetNamespace(), accessor.GetName()))
+	return nil
+}
+
+func (c *deletionRecorder) Update(ctx context.Context, obj runtime.Object, opts ...kclient.UpdateOptionFunc) error {
+	c.deletedOnUpdate = len(c.deleted)
+	c.updated = obj.(*operatorv1.ExternalDNS).DeepCopy()
+	return nil
+}
+
+func TestEnsureExternalDNSDeletedRemovesFinalizerLast(t *testing.T) {
+	client := &deletionRecorder{}
+	r := &reconciler{Config: Config{OperandNamespace: DefaultOperandNamespace}, kclient: client}
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "deleted",
+			Namespace:  "openshift-externaldns-operator",
+			Finalizers: []string{ExternalDNSControllerFinalizer},
+		},
+	}
+	if err := r.ensureExternalDNSDeleted(edns, &configv1.DNS{}, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"*v1.Deployment " + ExternalDNSDeploymentNamespacedName(DefaultOperandNamespace, edns).String(),
+		"*v1.Job " + ExternalDNSJobNamespacedName(DefaultOperandNamespace, edns).String(),
+		"*v1.HorizontalPodAutoscaler " + ExternalDNSHPANamespacedName(DefaultOperandNamespace, edns).String(),
+		"*v1.ConfigMap " + ExternalDNSConfigMapNamespacedName(DefaultOperandNamespace, edns).String(),
+		"*v1.NetworkPolicy " + ExternalDNSNetworkPolicyNamespacedName(DefaultOperandNamespace, edns).String(),
+		"*v1.ClusterRoleBinding /" + ExternalDNSCRDSourceBindingName(edns),
+		"*v1.ClusterRoleBinding /" + ExternalDNSContourHTTPProxySourceBindingName(edns),
+		"*v1.Secret " + ExternalDNSCredentialsSecretNamespacedName(DefaultOperandNamespace, edns).String(),
+	}
+	if !reflect.DeepEqual(client.deleted, expected) {
+		t.Errorf("expected deletions %v, got %v", expected, client.deleted)
+	}
+	if client.updated == nil || len(client.updated.Finalizers) != 0 {
+		t.Fatalf("expected the finalizer to be removed, got %v", client.updated)
+	}
+	if client.deletedOnUpdate != len(expected) {
+		t.Errorf("expected the finalizer to be removed after all deletions, got %d deletions first", client.deletedOnUpdate)
+	}
+}
+
+func TestSetExternalDNSOwnerReference(t *testing.T) {
+	edns := &operatorv1.ExternalDNS{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "openshift-externaldns-operator", UID: "1"}}
+	testCases := []struct {
+		description string
+		namespace   string
+		expectOwned bool
+	}{
+		{"same namespace", edns.Namespace, true},
+		{"operand namespace", ExternalDNSDeploymentNamespacedName(DefaultOperandNamespace, edns).Namespace, false},
+		{"cluster-scoped", "", false},
+	}
+	for _, tc := range testCases {
+		rb := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "binding", Namespace: tc.namespace}}
+		owned := setExternalDNSOwnerReference(rb, edns)
+		if owned != tc.expectOwned {
+			t.Errorf("%q: expected owned %t, got %t", tc.description, tc.expectOwned, owned)
+			continue
+		}
+		if !owned {
+			if len(rb.OwnerReferences) != 0 {
+				t.Errorf("%q: expected no owner references, got %v", tc.description, rb.OwnerReferences)
+			}
+			continue
+		}
+		if len(rb.OwnerReferences) != 1 {
+			t.Fatalf("%q: expected one owner reference, got %v", tc.description, rb.OwnerReferences)
+		}
+		ref := rb.OwnerReferences[0]
+		if ref.Kind != "ExternalDNS" || ref.Name != edns.Name || ref.UID != edns.UID || ref.Controller == nil || !*ref.Controller {
+			t.Errorf("%q: expected a controller reference to the externaldns, got %v", tc.description, ref)
+		}
+	}
+}
+
+func TestEnsureExternalDNSNamespaceOperandNamespace(t *testing.T) {
+	recorder := &createRecorder{}
+	r := &reconciler{Config: Config{OperandNamespace: "custom-externaldns"}, kclient: recorder}
+	if err := r.ensureExternalDNSNamespace(&operatorv1.ExternalDNS{ObjectMeta: metav1.ObjectMeta{Name: "custom"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ns *corev1.Namespace
+	var sa *corev1.ServiceAccount
+	var crb *rbacv1.ClusterRoleBinding
+	for _, obj := range recorder.created {
+		switch o := obj.(type) {
+		case *corev1.Namespace:
+			ns = o
+		case *corev1.ServiceAccount:
+			sa = o
+		case *rbacv1.ClusterRoleBinding:
+			crb = o
+		}
+	}
+	if ns == nil || ns.Name != "custom-externaldns" {
+		t.Errorf("expected the operand namespace to be created, got %v", ns)
+	}
+	if sa == nil || sa.Namespace != "custom-externaldns" {
+		t.Fatalf("expected the service account to be created in the operand namespace, got %v", sa)
+	}
+	if crb == nil || crb.Name != "custom-externaldns" || crb.Subjects[0].Namespace != sa.Namespace || crb.Subjects[0].Name != sa.Name {
+		t.Errorf("expected a cluster role binding of the operand service account, got %v", crb)
+	}
+}
+
+func TestEnforceEffectivePolicy(t *testing.T) {
+	recorder := &updateRecorder{}
+	r := &reconciler{kclient: recorder}
+	edns := &operatorv1.ExternalDNS{ObjectMeta: metav1.ObjectMeta{Name: "unset"}}
+	if err := r.enforceEffectivePolicy(edns); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.updated) != 1 {
+		t.Fatalf("expected the externaldns to be updated once, got %d updates", len(recorder.updated))
+	}
+	if policy := recorder.updated[0].(*operatorv1.ExternalDNS).Spec.Policy; policy == nil || *policy != operatorv1.UpsertOnlyPolicyType {
+		t.Errorf("expected the policy to default to %q, got %v", operatorv1.UpsertOnlyPolicyType, policy)
+	}
+
+	recorder.updated = nil
+	sync := operatorv1.SyncPolicyType
+	edns.Spec.Policy = &sync
+	if err := r.enforceEffectivePolicy(edns); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.updated) != 0 {
+		t.Errorf("expected a set policy to be left alone, got %d updates", len(recorder.updated))
+	}
+}
+
+// externalDNSLister is a client listing the given externaldnses and
+// recording status updates. Calls to other methods panic.
+type externalDNSLister struct {
+	kclient.Client
+	items         []operatorv1.ExternalDNS
+	statusUpdated *operatorv1.ExternalDNS
+}
+
+func (c *externalDNSLister) List(ctx context.Context, list runtime.Object, opts ...kclient.ListOptionFunc) error {
+	list.(*operatorv1.ExternalDNSList).Items = c.items
+	return nil
+}
+
+func (c *externalDNSLister) Status() kclient.StatusWriter {
+	return externalDNSStatusWriter{c}
+}
+
+// externalDNSStatusWriter records the status updates of an
+// externalDNSLister.
+type externalDNSStatusWriter struct {
+	lister *externalDNSLister
+}
+
+func (w externalDNSStatusWriter) Update(ctx context.Context, obj runtime.Object) error {
+	w.lister.statusUpdated = obj.(*operatorv1.ExternalDNS).DeepCopy()
+	return nil
+}
+
+func TestEnforceEffectiveBaseDomainDuplicate(t *testing.T) {
+	public := operatorv1.PublicZoneType
+	first := operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "first"},
+		Spec:       operatorv1.ExternalDNSSpec{BaseDomain: "example.com", ZoneType: &public},
+		Status:     operatorv1.ExternalDNSStatus{BaseDomain: "example.com"},
+	}
+	second := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "second"},
+		Spec:       operatorv1.ExternalDNSSpec{BaseDomain: "example.com", ZoneType: &public},
+	}
+	client := &externalDNSLister{items: []operatorv1.ExternalDNS{first, *second}}
+	r := &reconciler{kclient: client}
+	if err := r.enforceEffectiveBaseDomain(second, &configv1.DNS{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.statusUpdated == nil {
+		t.Fatal("expected the status of the second externaldns to be updated")
+	}
+	if len(client.statusUpdated.Status.BaseDomain) != 0 {
+		t.Errorf("expected no base domain to be published, got %q", client.statusUpdated.Status.BaseDomain)
+	}
+	condition := findExternalDNSCondition(client.statusUpdated.Status.Conditions, operatorv1.DegradedConditionType)
+	if condition == nil || condition.Status != operatorv1.ConditionTrue || condition.Reason != "DuplicateBaseDomain" {
+		t.Fatalf("expected a Degraded condition with reason DuplicateBaseDomain, got %v", condition)
+	}
+	if !strings.Contains(condition.Message, "openshift-externaldns-operator/first") {
+		t.Errorf("expected the condition to name the conflicting externaldns, got %q", condition.Message)
+	}
+
+	// A different zone type doesn't conflict.
+	private := operatorv1.PrivateZoneType
+	second.Spec.ZoneType = &private
+	client.statusUpdated = nil
+	if err := r.enforceEffectiveBaseDomain(second, &configv1.DNS{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.statusUpdated == nil || client.statusUpdated.Status.BaseDomain != "example.com" {
+		t.Errorf("expected base domain example.com to be published, got %v", client.statusUpdated)
+	}
+}
+
+func TestBaseDomainConflictForZoneType(t *testing.T) {
+	existingZoneType, newZoneType := operatorv1.PublicZoneType, operatorv1.PublicZoneType
+	existing := operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing"},
+		Spec:       operatorv1.ExternalDNSSpec{ZoneType: &existingZoneType},
+		Status:     operatorv1.ExternalDNSStatus{BaseDomain: "example.com"},
+	}
+	private := operatorv1.PrivateZoneType
+	testCases := []struct {
+		description    string
+		zoneType       *operatorv1.ZoneType
+		expectConflict bool
+	}{
+		{"same zone type through a distinct pointer", &newZoneType, true},
+		{"different zone type", &private, false},
+		{"unset zone type", nil, false},
+	}
+	r := &reconciler{kclient: &externalDNSLister{items: []operatorv1.ExternalDNS{existing}}}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{ZoneType: tc.zoneType}}
+		conflict, err := r.baseDomainConflictForZoneType("example.com", edns)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.description, err)
+		}
+		if (conflict != nil) != tc.expectConflict {
+			t.Errorf("%q: expected conflict %t, got %v", tc.description, tc.expectConflict, conflict)
+		}
+	}
+}
+
+func TestEnforceEffectiveTextOwnerIDHashedIsStable(t *testing.T) {
+	aws := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "hashed"},
+		Spec:       operatorv1.ExternalDNSSpec{TXTOwnerIDFormat: operatorv1.HashedTXTOwnerIDFormat},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &aws},
+	}
+	infraConfig := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{InfrastructureName: "cluster-abc"}}
+	client := &externalDNSLister{}
+	r := &reconciler{kclient: client}
+	if _, err := r.enforceEffectiveTextOwnerID(edns, infraConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.statusUpdated == nil || client.statusUpdated.Status.TextOwnerID != TextOwnerID(infraConfig, edns) {
+		t.Fatalf("expected the hashed txt owner id %q to be published, got %v", TextOwnerID(infraConfig, edns), client.statusUpdated)
+	}
+
+	// Later reconciles keep the published owner id.
+	edns = client.statusUpdated
+	client.statusUpdated = nil
+	if _, err := r.enforceEffectiveTextOwnerID(edns, infraConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.statusUpdated != nil {
+		t.Errorf("expected the published txt owner id to be kept, got %q", client.statusUpdated.Status.TextOwnerID)
+	}
+}
+
+// infraGetter is an externalDNSLister serving Get requests for the
+// infrastructure config of the given platform.
+type infraGetter struct {
+	externalDNSLister
+	platform configv1.PlatformType
+}
+
+func (c *infraGetter) Get(ctx context.Context, key kclient.ObjectKey, obj runtime.Object) error {
+	infra := obj.(*unstructured.Unstructured)
+	return unstructured.SetNestedField(infra.Object, string(c.platform), "status", "platformStatus", "type")
+}
+
+func (c *infraGetter) Status() kclient.StatusWriter {
+	return externalDNSStatusWriter{&c.externalDNSLister}
+}
+
+func TestEnforceEffectiveProviderOverridesPlatform(t *testing.T) {
+	azure := operatorv1.AzureProvider
+	inMemory := operatorv1.InMemoryProvider
+	testCases := []struct {
+		description string
+		platform    configv1.PlatformType
+		specType    *operatorv1.ProviderType
+		expected    operatorv1.ProviderType
+	}{
+		{"aws platform", configv1.AWSPlatformType, nil, operatorv1.AWSProvider},
+		{"azure provider on aws", configv1.AWSPlatformType, &azure, operatorv1.AzureProvider},
+		{"inmemory provider on gcp", configv1.GCPPlatformType, &inMemory, operatorv1.InMemoryProvider},
+		{"azure provider without platform provider", configv1.NonePlatformType, &azure, operatorv1.AzureProvider},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "test"},
+			Spec:       operatorv1.ExternalDNSSpec{Provider: operatorv1.ProviderSpec{Type: tc.specType}},
+		}
+		client := &infraGetter{platform: tc.platform}
+		r := &reconciler{kclient: client}
+		if err := r.enforceEffectiveProvider(edns); err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.description, err)
+		}
+		if client.statusUpdated == nil || client.statusUpdated.Status.ProviderType == nil {
+			t.Fatalf("%q: expected the provider to be published", tc.description)
+		}
+		if actual := *client.statusUpdated.Status.ProviderType; actual != tc.expected {
+			t.Errorf("%q: expected provider %q, got %q", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestOperandCredentialsFilesOverriddenProvider(t *testing.T) {
+	// The credentials secret of an AWS cluster also holding the keys of
+	// an externaldns selecting the azure provider.
+	creds := &corev1.Secret{Data: map[string][]byte{
+		"aws_access_key_id":     []byte("key"),
+		"aws_secret_access_key": []byte("secret"),
+		"azure_tenant_id":       []byte("tenant"),
+		"azure_subscription_id": []byte("subscription"),
+		"azure_resourcegroup":   []byte("group"),
+		"azure_client_id":       []byte("client"),
+		"azure_client_secret":   []byte("client-secret"),
+	}}
+	files, err := operandCredentialsFiles(operatorv1.AzureProvider, creds)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := files[azureConfigFileName]; !ok || len(files) != 1 {
+		t.Errorf("expected only the azure config file, got %v", files)
+	}
+	if !strings.Contains(string(files[azureConfigFileName]), "subscription") {
+		t.Errorf("expected the azure config file to be built from the azure keys, got %s", files[azureConfigFileName])
+	}
+	if files, err := operandCredentialsFiles(operatorv1.AWSProvider, creds); err != nil || files != nil {
+		t.Errorf("expected no credentials files for the aws provider, got %v, %v", files, err)
+	}
+}
+
+// serviceAccountClient is a client for which only the given service
+// account exists, recording the objects it creates and updates. Calls to
+// other methods panic.
+type serviceAccountClient struct {
+	kclient.Client
+	sa      *corev1.ServiceAccount
+	created []runtime.Object
+	updated []runtime.Object
+}
+
+func (c *serviceAccountClient) Get(ctx context.Context, key kclient.ObjectKey, obj runtime.Object) error {
+	if sa, ok := obj.(*corev1.ServiceAccount); ok && c.sa != nil {
+		c.sa.DeepCopyInto(sa)
+		return nil
+	}
+	return errors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (c *serviceAccountClient) Create(ctx context.Context, obj runtime.Object, opts ...kclient.CreateOptionFunc) error {
+	c.created = append(c.created, obj)
+	return nil
+}
+
+func (c *serviceAccountClient) Update(ctx context.Context, obj runtime.Object, opts ...kclient.UpdateOptionFunc) error {
+	c.updated = append(c.updated, obj)
+	return nil
+}
+
+func TestEnsureExternalDNSNamespaceRoleARN(t *testing.T) {
+	const role = "arn:aws:iam::123456789012:role/externaldns"
+	annotated := func(arn string) *corev1.ServiceAccount {
+		sa := desiredExternalDNSServiceAccount(DefaultOperandNamespace)
+		if len(arn) != 0 {
+			sa.Annotations = map[string]string{ServiceAccountRoleARNAnnotation: arn}
+		}
+		return sa
+	}
+	testCases := []struct {
+		description string
+		roleARN     string
+		current     *corev1.ServiceAccount
+		expectedARN string
+		expectWrite bool
+	}{
+		{"created with the role", role, nil, role, true},
+		{"created without a role", "", nil, "", true},
+		{"annotation missing", role, annotated(""), role, true},
+		{"annotation drifted", role, annotated("arn:aws:iam::123456789012:role/other"), role, true},
+		{"annotation matching", role, annotated(role), "", false},
+		{"no role configured", "", annotated(""), "", false},
+	}
+	for _, tc := range testCases {
+		client := &serviceAccountClient{sa: tc.current}
+		r := &reconciler{Config: Config{OperandNamespace: DefaultOperandNamespace, RoleARN: tc.roleARN}, kclient: client}
+		if err := r.ensureExternalDNSNamespace(&operatorv1.ExternalDNS{ObjectMeta: metav1.ObjectMeta{Name: "test"}}); err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.description, err)
+		}
+		var written *corev1.ServiceAccount
+		for _, obj := range append(client.created, client.updated...) {
+			if sa, ok := obj.(*corev1.ServiceAccount); ok {
+				written = sa
+			}
+		}
+		if (written != nil) != tc.expectWrite {
+			t.Fatalf("%q: expected service account write %t, got %v", tc.description, tc.expectWrite, written)
+		}
+		if written != nil && written.Annotations[ServiceAccountRoleARNAnnotation] != tc.expectedARN {
+			t.Errorf("%q: expected role annotation %q, got %q", tc.description, tc.expectedARN, written.Annotations[ServiceAccountRoleARNAnnotation])
+		}
+	}
+}
+
+func TestEnforceEffectiveTextOwnerIDMigration(t *testing.T) {
+	migrating := func(age time.Duration) []operatorv1.OperatorCondition {
+		return []operatorv1.OperatorCondition{{
+			Type:               operatorv1.TextOwnerMigratingConditionType,
+			Status:             operatorv1.ConditionTrue,
+			Reason:             "OwnerIDChanged",
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-age)),
+		}}
+	}
+	testCases := []struct {
+		description       string
+		owner             string
+		status            operatorv1.ExternalDNSStatus
+		expectUpdate      bool
+		expectedOwner     string
+		expectedPrevious  string
+		expectedCondition operatorv1.ConditionStatus
+		expectRemaining   bool
+	}{
+		{
+			description:   "first publication of the legacy owner id",
+			owner:         "/openshift-externaldns-operator/migrating",
+			expectUpdate:  true,
+			expectedOwner: "/openshift-externaldns-operator/migrating",
+		},
+		{
+			description:       "first publication migrates from the legacy owner id",
+			owner:             "new",
+			expectUpdate:      true,
+			expectedOwner:     "new",
+			expectedPrevious:  "/openshift-externaldns-operator/migrating",
+			expectedCondition: operatorv1.ConditionTrue,
+			expectRemaining:   true,
+		},
+		{
+			description:   "unchanged owner id",
+			owner:         "new",
+			status:        operatorv1.ExternalDNSStatus{TextOwnerID: "new"},
+			expectedOwner: "new",
+		},
+		{
+			description:       "owner id changed",
+			owner:             "new",
+			status:            operatorv1.ExternalDNSStatus{TextOwnerID: "old"},
+			expectUpdate:      true,
+			expectedOwner:     "new",
+			expectedPrevious:  "old",
+			expectedCondition: operatorv1.ConditionTrue,
+			expectRemaining:   true,
+		},
+		{
+			description:       "migration within the window",
+			owner:             "new",
+			status:            operatorv1.ExternalDNSStatus{TextOwnerID: "new", PreviousTextOwnerID: "old", Conditions: migrating(10 * time.Minute)},
+			expectedOwner:     "new",
+			expectedPrevious:  "old",
+			expectedCondition: operatorv1.ConditionTrue,
+			expectRemaining:   true,
+		},
+		{
+			description:       "migration window ended",
+			owner:             "new",
+			status:            operatorv1.ExternalDNSStatus{TextOwnerID: "new", PreviousTextOwnerID: "old", Conditions: migrating(textOwnerMigrationWindow + time.Minute)},
+			expectUpdate:      true,
+			expectedOwner:     "new",
+			expectedCondition: operatorv1.ConditionFalse,
+		},
+		{
+			description:       "owner id changed again during the migration",
+			owner:             "newer",
+			status:            operatorv1.ExternalDNSStatus{TextOwnerID: "new", PreviousTextOwnerID: "old", Conditions: migrating(10 * time.Minute)},
+			expectedOwner:     "new",
+			expectedPrevious:  "old",
+			expectedCondition: operatorv1.ConditionTrue,
+			expectRemaining:   true,
+		},
+		{
+			description:       "owner id changed again after the window",
+			owner:             "newer",
+			status:            operatorv1.ExternalDNSStatus{TextOwnerID: "new", PreviousTextOwnerID: "old", Conditions: migrating(textOwnerMigrationWindow + time.Minute)},
+			expectUpdate:      true,
+			expectedOwner:     "new",
+			expectedCondition: operatorv1.ConditionFalse,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "migrating"},
+			Spec:       operatorv1.ExternalDNSSpec{TXTOwnerID: tc.owner},
+			Status:     tc.status,
+		}
+		client := &externalDNSLister{}
+		r := &reconciler{kclient: client}
+		remaining, err := r.enforceEffectiveTextOwnerID(edns, &configv1.Infrastructure{})
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.description, err)
+		}
+		if (client.statusUpdated != nil) != tc.expectUpdate {
+			t.Fatalf("%q: expected status update %t, got %v", tc.description, tc.expectUpdate, client.statusUpdated)
+		}
+		status := edns.Status
+		if client.statusUpdated != nil {
+			status = client.statusUpdated.Status
+		}
+		if status.TextOwnerID != tc.expectedOwner || status.PreviousTextOwnerID != tc.expectedPrevious {
+			t.Errorf("%q: expected owner id %q migrating from %q, got %q migrating from %q",
+				tc.description, tc.expectedOwner, tc.expectedPrevious, status.TextOwnerID, status.PreviousTextOwnerID)
+		}
+		condition := findExternalDNSCondition(status.Conditions, operatorv1.TextOwnerMigratingConditionType)
+		switch {
+		case len(tc.expectedCondition) == 0 && condition != nil:
+			t.Errorf("%q: expected no TextOwnerMigrating condition, got %v", tc.description, condition)
+		case len(tc.expectedCondition) != 0 && (condition == nil || condition.Status != tc.expectedCondition):
+			t.Errorf("%q: expected TextOwnerMigrating=%s, got %v", tc.description, tc.expectedCondition, condition)
+		}
+		if (remaining > 0) != tc.expectRemaining || remaining > textOwnerMigrationWindow {
+			t.Errorf("%q: expected remaining migration time %t, got %s", tc.description, tc.expectRemaining, remaining)
+		}
+	}
+}
+
+// objectStore is an in-memory client storing objects by type, namespace
+// and name, and recording the last externaldns status update.
+type objectStore struct {
+	kclient.Client
+	objects       map[string]runtime.Object
+	statusUpdated *operatorv1.ExternalDNS
+}
+
+func objectStoreKey(obj runtime.Object, namespace, name string) string {
+	return fmt.Sprintf("%T/%s/%s", obj, namespace, name)
+}
+
+func (c *objectStore) Get(ctx context.Context, key kclient.ObjectKey, obj runtime.Object) error {
+	stored, ok := c.objects[objectStoreKey(obj, key.Namespace, key.Name)]
+	if !ok {
+		return errors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(stored.DeepCopyObject()).Elem())
+	return nil
+}
+
+func (c *objectStore) List(ctx context.Context, list runtime.Object, opts ...kclient.ListOptionFunc) error {
+	options := (&kclient.ListOptions{}).ApplyOptions(opts)
+	prefix := strings.TrimSuffix(fmt.Sprintf("%T", list), "List") + "/"
+	if len(options.Namespace) != 0 {
+		prefix += options.Namespace + "/"
+	}
+	keys := []string{}
+	for k := range c.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	items := []runtime.Object{}
+	for _, k := range keys {
+		accessor, err := meta.Accessor(c.objects[k])
+		if err != nil {
+			return err
+		}
+		if options.LabelSelector == nil || options.LabelSelector.Matches(labels.Set(accessor.GetLabels())) {
+			items = append(items, c.objects[k].DeepCopyObject())
+		}
+	}
+	return meta.SetList(list, items)
+}
+
+func (c *objectStore) Create(ctx context.Context, obj runtime.Object, opts ...kclient.CreateOptionFunc) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	key := objectStoreKey(obj, accessor.GetNamespace(), accessor.GetName())
+	if _, ok := c.objects[key]; ok {
+		return errors.NewAlreadyExists(schema.GroupResource{}, accessor.GetName())
+	}
+	c.objects[key] = obj.DeepCopyObject()
+	return nil
+}
+
+func (c *objectStore) Update(ctx context.Context, obj runtime.Object, opts ...kclient.UpdateOptionFunc) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	key := objectStoreKey(obj, accessor.GetNamespace(), accessor.GetName())
+	if _, ok := c.objects[key]; !ok {
+		return errors.NewNotFound(schema.GroupResource{}, accessor.GetName())
+	}
+	c.objects[key] = obj.DeepCopyObject()
+	return nil
+}
+
+func (c *objectStore) Delete(ctx context.Context, obj runtime.Object, opts ...kclient.DeleteOptionFunc) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	key := objectStoreKey(obj, accessor.GetNamespace(), accessor.GetName())
+	if _, ok := c.objects[key]; !ok {
+		return errors.NewNotFound(schema.GroupResource{}, accessor.GetName())
+	}
+	delete(c.objects, key)
+	return nil
+}
+
+func (c *objectStore) Status() kclient.StatusWriter {
+	return objectStoreStatusWriter{c}
+}
+
+// objectStoreStatusWriter records the externaldns status updates of an
+// objectStore.
+type objectStoreStatusWriter struct {
+	store *objectStore
+}
+
+func (w objectStoreStatusWriter) Update(ctx context.Context, obj runtime.Object) error {
+	w.store.statusUpdated = obj.(*operatorv1.ExternalDNS).DeepCopy()
+	return w.store.Update(ctx, obj)
+}
+
+func TestEnsureExternalDNSExcessReplicas(t *testing.T) {
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	three, two := int32(3), int32(2)
+	testCases := []struct {
+		description string
+		replicas    *int32
+		threshold   *int32
+		expected    operatorv1.ConditionStatus
+	}{
+		{"default replicas", nil, nil, operatorv1.ConditionFalse},
+		{"replicas over the default threshold", &three, nil, operatorv1.ConditionTrue},
+		{"replicas over a configured threshold", &three, &two, operatorv1.ConditionTrue},
+		{"replicas at a configured threshold", &three, &three, operatorv1.ConditionFalse},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "scaled"},
+			Spec: operatorv1.ExternalDNSSpec{
+				Sources:                 []*operatorv1.SourceType{&service},
+				Replicas:                tc.replicas,
+				ExcessReplicasThreshold: tc.threshold,
+			},
+			Status: operatorv1.ExternalDNSStatus{BaseDomain: "example.com", ProviderType: &provider, TextOwnerID: "owner"},
+		}
+		if err := validateExternalDNS(edns); err != nil {
+			t.Errorf("%q: expected a valid spec, got %v", tc.description, err)
+			continue
+		}
+		store := &objectStore{objects: map[string]runtime.Object{}}
+		store.objects[objectStoreKey(edns, edns.Namespace, edns.Name)] = edns.DeepCopy()
+		r := &reconciler{
+			Config: Config{
+				Namespace:            edns.Namespace,
+				OperandNamespace:     DefaultOperandNamespace,
+				OperandContainerName: defaultOperandContainerName,
+				ExternalDNSImage:     "externaldns:latest",
+			},
+			kclient: store,
+		}
+		if err := r.ensureExternalDNS(edns, &configv1.DNS{}, &configv1.Infrastructure{}); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+
+		deployment := &appsv1.Deployment{}
+		if err := store.Get(context.TODO(), ExternalDNSDeploymentNamespacedName(DefaultOperandNamespace, edns), deployment); err != nil {
+			t.Errorf("%q: expected the deployment to be ensured, got %v", tc.description, err)
+			continue
+		}
+		expectedReplicas := int32(1)
+		if tc.replicas != nil {
+			expectedReplicas = *tc.replicas
+		}
+		if replicas := deployment.Spec.Replicas; replicas == nil || *replicas != expectedReplicas {
+			t.Errorf("%q: expected %d replicas, got %v", tc.description, expectedReplicas, replicas)
+		}
+		if store.statusUpdated == nil {
+			t.Errorf("%q: expected the status to be synced", tc.description)
+			continue
+		}
+		var condition *operatorv1.OperatorCondition
+		for i, c := range store.statusUpdated.Status.Conditions {
+			if c.Type == operatorv1.ExcessReplicasConditionType {
+				condition = &store.statusUpdated.Status.Conditions[i]
+			}
+		}
+		if condition == nil || condition.Status != tc.expected {
+			t.Errorf("%q: expected %s condition %s, got %v", tc.description, operatorv1.ExcessReplicasConditionType, tc.expected, condition)
+		}
+	}
+}
+
+func TestEnsureExternalDNSOnceConditions(t *testing.T) {
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "once"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources: []*operatorv1.SourceType{&service},
+			RunMode: operatorv1.OnceRunMode,
+		},
+		Status: operatorv1.ExternalDNSStatus{BaseDomain: "example.com", ProviderType: &provider, TextOwnerID: "owner"},
+	}
+	store := &objectStore{objects: map[string]runtime.Object{}}
+	store.objects[objectStoreKey(edns, edns.Namespace, edns.Name)] = edns.DeepCopy()
+	r := &reconciler{
+		Config: Config{
+			Namespace:            edns.Namespace,
+			OperandNamespace:     DefaultOperandNamespace,
+			OperandContainerName: defaultOperandContainerName,
+			ExternalDNSImage:     "externaldns:latest",
+		},
+		kclient: store,
+	}
+
+	// The conditions follow the Job as it runs and completes.
+	steps := []struct {
+		description         string
+		status              batchv1.JobStatus
+		expectedAvailable   string
+		expectedProgressing string
+	}{
+		{"job created", batchv1.JobStatus{}, "JobUnavailable", "JobRunning"},
+		{"job running", batchv1.JobStatus{Active: 1}, "JobRunning", "JobRunning"},
+		{"job complete", batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}}, "JobComplete", "AsExpected"},
+	}
+	for _, step := range steps {
+		job, err := r.currentExternalDNSJob(edns)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", step.description, err)
+		}
+		if job != nil {
+			job.Status = step.status
+			if err := store.Update(context.TODO(), job); err != nil {
+				t.Fatalf("%q: unexpected error: %v", step.description, err)
+			}
+		}
+		if err := r.ensureExternalDNS(edns, &configv1.DNS{}, &configv1.Infrastructure{}); err != nil {
+			t.Fatalf("%q: unexpected error: %v", step.description, err)
+		}
+		if store.statusUpdated != nil {
+			edns = store.statusUpdated.DeepCopy()
+		}
+		available := findExternalDNSCondition(edns.Status.Conditions, operatorv1.AvailableConditionType)
+		if available == nil || available.Reason != step.expectedAvailable {
+			t.Errorf("%q: expected %s condition with reason %s, got %v", step.description,
+				operatorv1.AvailableConditionType, step.expectedAvailable, available)
+		}
+		progressing := findExternalDNSCondition(edns.Status.Conditions, operatorv1.ProgressingConditionType)
+		if progressing == nil || progressing.Reason != step.expectedProgressing {
+			t.Errorf("%q: expected %s condition with reason %s, got %v", step.description,
+				operatorv1.ProgressingConditionType, step.expectedProgressing, progressing)
+		}
+	}
+}
+
+func TestEnforceEffectiveTextOwnerIDUpgrade(t *testing.T) {
+	aws := operatorv1.AWSProvider
+	public := operatorv1.PublicZoneType
+	// An externaldns last reconciled by an operator that didn't publish
+	// the owner id.
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "default-public"},
+		Spec:       operatorv1.ExternalDNSSpec{ZoneType: &public},
+		Status:     operatorv1.ExternalDNSStatus{BaseDomain: "example.com", ProviderType: &aws},
+	}
+	infraConfig := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{InfrastructureName: "cluster-abc"}}
+	client := &externalDNSLister{}
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}, kclient: client}
+	remaining, err := r.enforceEffectiveTextOwnerID(edns, infraConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.statusUpdated == nil {
+		t.Fatal("expected the owner id to be published")
+	}
+	status := client.statusUpdated.Status
+	if expected := "cluster-abc/aws/public/openshift-externaldns-operator/default-public"; status.TextOwnerID != expected {
+		t.Errorf("expected owner id %q, got %q", expected, status.TextOwnerID)
+	}
+	if expected := "cluster-abc/openshift-externaldns-operator/default-public"; status.PreviousTextOwnerID != expected {
+		t.Errorf("expected the migration from the legacy owner id %q, got %q", expected, status.PreviousTextOwnerID)
+	}
+	if c := findExternalDNSCondition(status.Conditions, operatorv1.TextOwnerMigratingConditionType); c == nil || c.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected TextOwnerMigrating=True, got %v", c)
+	}
+	if remaining != textOwnerMigrationWindow {
+		t.Errorf("expected the migration window %s to remain, got %s", textOwnerMigrationWindow, remaining)
+	}
+
+	// The operand re-adopts the records of the legacy owner id.
+	provider := operatorv1.InMemoryProvider
+	migrated := client.statusUpdated.DeepCopy()
+	migrated.Status.ProviderType = &provider
+	service := operatorv1.ServiceType
+	migrated.Spec.Sources = []*operatorv1.SourceType{&service}
+	deployment := r.desiredExternalDNSDeployment(migrated, "externaldns:latest", &configv1.DNS{}, infraConfig)
+	args := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args
+	if !slice.ContainsString(args, "--migrate-from-txt-owner=cluster-abc/openshift-externaldns-operator/default-public") {
+		t.Errorf("expected the operand to migrate from the legacy owner id, got %v", args)
+	}
+}
+
+func TestReconcileInvalidConfiguration(t *testing.T) {
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	negative := int32(-1)
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "invalid"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:  []*operatorv1.SourceType{&service},
+			Provider: operatorv1.ProviderSpec{Type: &provider},
+			Replicas: &negative,
+		},
+		Status: operatorv1.ExternalDNSStatus{BaseDomain: "example.com"},
+	}
+	dnsConfig := &configv1.DNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.DNSSpec{
+			BaseDomain: "example.com",
+			PublicZone: &configv1.DNSZone{ID: "public"},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{InfrastructureName: "cluster-abc", Platform: configv1.AWSPlatformType},
+	}
+	store := &objectStore{objects: map[string]runtime.Object{}}
+	for _, obj := range []runtime.Object{edns, dnsConfig, infraConfig} {
+		if err := store.Create(context.TODO(), obj); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	r := &reconciler{
+		Config: Config{
+			Namespace:            edns.Namespace,
+			OperandNamespace:     DefaultOperandNamespace,
+			OperandContainerName: defaultOperandContainerName,
+			ExternalDNSImage:     "externaldns:latest",
+		},
+		kclient: store,
+	}
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: edns.Namespace, Name: edns.Name}})
+	if err != nil {
+		t.Fatalf("expected an invalid configuration not to be retried, got %v", err)
+	}
+	if result.Requeue || result.RequeueAfter != 0 {
+		t.Errorf("expected no requeue of an invalid configuration, got %v", result)
+	}
+	if store.statusUpdated == nil {
+		t.Fatal("expected the invalid configuration to be reported in status")
+	}
+	condition := findExternalDNSCondition(store.statusUpdated.Status.Conditions, operatorv1.DegradedConditionType)
+	if condition == nil || condition.Status != operatorv1.ConditionTrue || condition.Reason != "InvalidConfiguration" ||
+		!strings.Contains(condition.Message, "invalid replicas -1") {
+		t.Errorf("expected Degraded=True with reason InvalidConfiguration, got %v", condition)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := store.Get(context.TODO(), ExternalDNSDeploymentNamespacedName(DefaultOperandNamespace, edns), deployment); !errors.IsNotFound(err) {
+		t.Errorf("expected no deployment for an invalid configuration, got %v", err)
+	}
+}
@@ -0,0 +1,300 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// operandCredentialsVolumeName is the name of the operand volume holding
+	// the credentials files built from the operator credentials.
+	operandCredentialsVolumeName = "cloud-credentials"
+
+	// operandCredentialsMountPath is the directory the credentials files
+	// built from the operator credentials are mounted in.
+	operandCredentialsMountPath = "/etc/externaldns/cloud-credentials"
+
+	// credentialsHashAnnotation is the operand pod template annotation
+	// holding a hash of the mounted credentials files. The ExternalDNS
+	// controller only reads them at startup, so rotated credentials roll
+	// out new pods.
+	credentialsHashAnnotation = "externaldns.operator.openshift.io/credentials-hash"
+
+	// serviceAccountTokenVolumeName is the name of the operand volume
+	// holding the projected service account token.
+	serviceAccountTokenVolumeName = "service-account-token"
+
+	// defaultServiceAccountTokenMountPath is the directory the projected
+	// service account token is mounted in when none is specified.
+	defaultServiceAccountTokenMountPath = "/var/run/secrets/externaldns/serviceaccount"
+
+	// serviceAccountTokenFileName is the name of the projected service
+	// account token file.
+	serviceAccountTokenFileName = "token"
+
+	// serviceAccountTokenExpirationSeconds is the requested lifetime of the
+	// projected token. It is the API server default, set explicitly so the
+	// desired volume matches the stored one. The kubelet refreshes the
+	// token before it expires.
+	serviceAccountTokenExpirationSeconds int64 = 3600
+
+	// awsWebIdentityTokenFileEnvVar and awsRoleARNEnvVar configure the AWS
+	// SDK to assume a role with a web identity token.
+	awsWebIdentityTokenFileEnvVar = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	awsRoleARNEnvVar              = "AWS_ROLE_ARN"
+
+	// azureFederatedTokenFileEnvVar configures the Azure SDK to
+	// authenticate with a federated token.
+	azureFederatedTokenFileEnvVar = "AZURE_FEDERATED_TOKEN_FILE"
+
+	// credentialsSidecarName is the name of the credentials sidecar
+	// container of the operand.
+	credentialsSidecarName = "credentials"
+
+	// credentialsSidecarVolumeName is the name of the emptyDir volume
+	// shared by the credentials sidecar and the operand.
+	credentialsSidecarVolumeName = "sidecar-credentials"
+
+	// defaultCredentialsSidecarMountPath is the directory the shared
+	// credentials volume is mounted in when none is specified.
+	defaultCredentialsSidecarMountPath = "/var/run/secrets/externaldns/credentials"
+
+	// credentialsSidecarWaitScript holds back the start of the operand
+	// until the credentials sidecar has written the credentials file.
+	credentialsSidecarWaitScript = `until [ -s "$1" ]; do sleep 1; done`
+)
+
+// credentialsSidecarMountPath returns the effective mount path of the shared
+// credentials volume of the given sidecar.
+func credentialsSidecarMountPath(sidecar *operatorv1.ProviderCredentialsSidecar) string {
+	if len(sidecar.MountPath) == 0 {
+		return defaultCredentialsSidecarMountPath
+	}
+	return sidecar.MountPath
+}
+
+// credentialsSidecar returns the credentials sidecar container of edns, the
+// emptyDir volume it shares with the operand and the read-only mount of the
+// volume in the operand, or a nil container if edns has no sidecar. Unless
+// the sidecar has a postStart hook, one is added that waits for the
+// credentials file, so the kubelet only starts the operand once the
+// credentials are written.
+func credentialsSidecar(edns *operatorv1.ExternalDNS) (*corev1.Container, corev1.Volume, corev1.VolumeMount) {
+	spec := edns.Spec.Provider.CredentialsSidecar
+	if spec == nil {
+		return nil, corev1.Volume{}, corev1.VolumeMount{}
+	}
+	mountPath := credentialsSidecarMountPath(spec)
+	volume := corev1.Volume{
+		Name:         credentialsSidecarVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}
+	sidecar := spec.Container.DeepCopy()
+	sidecar.Name = credentialsSidecarName
+	sidecar.VolumeMounts = append(sidecar.VolumeMounts, corev1.VolumeMount{Name: credentialsSidecarVolumeName, MountPath: mountPath})
+	if sidecar.Lifecycle == nil {
+		sidecar.Lifecycle = &corev1.Lifecycle{}
+	}
+	if sidecar.Lifecycle.PostStart == nil {
+		sidecar.Lifecycle.PostStart = &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c", credentialsSidecarWaitScript, credentialsSidecarName,
+					path.Join(mountPath, spec.CredentialsFile)},
+			},
+		}
+	}
+	return sidecar, volume, corev1.VolumeMount{Name: credentialsSidecarVolumeName, MountPath: mountPath, ReadOnly: true}
+}
+
+// operandCredentialsFiles returns the credentials files, keyed by file name,
+// of an operand of the given provider type built from creds, the secret
+// provisioned for the operator CredentialsRequest. Nil is returned for
+// providers whose credentials aren't mounted as files.
+func operandCredentialsFiles(provider operatorv1.ProviderType, creds *corev1.Secret) (map[string][]byte, error) {
+	if provider != operatorv1.AzureProvider && provider != operatorv1.GoogleProvider {
+		return nil, nil
+	}
+	if creds == nil {
+		return nil, fmt.Errorf("no %s credentials", provider)
+	}
+	switch provider {
+	case operatorv1.AzureProvider:
+		config, err := azureConfigFile(creds)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{azureConfigFileName: config}, nil
+	default:
+		key, err := googleCredentialsFile(creds)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{googleCredentialsFileName: key}, nil
+	}
+}
+
+// operandCredentialsHash returns a hash of the credentials files of an
+// operand of the given provider type built from creds, or an empty string
+// if it has none.
+func operandCredentialsHash(provider operatorv1.ProviderType, creds *corev1.Secret) string {
+	files, err := operandCredentialsFiles(provider, creds)
+	if err != nil || len(files) == 0 {
+		// Invalid credentials are reported when ensuring the
+		// credentials secret.
+		return ""
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s\x00%d\x00", name, len(files[name]))
+		hash.Write(files[name])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// ensureOperandCredentialsSecret ensures the secret holding the credentials
+// files of the operand of edns matches the operator credentials, deleting
+// it for providers whose credentials aren't mounted as files.
+func (r *reconciler) ensureOperandCredentialsSecret(edns *operatorv1.ExternalDNS) error {
+	files, err := operandCredentialsFiles(*edns.Status.ProviderType, r.Credentials)
+	if err != nil {
+		return err
+	}
+	if files == nil {
+		return r.ensureOperandCredentialsSecretDeleted(edns)
+	}
+	desired := &corev1.Secret{}
+	name := ExternalDNSCredentialsSecretNamespacedName(r.OperandNamespace, edns)
+	desired.Name = name.Name
+	desired.Namespace = name.Namespace
+	setExternalDNSOwnerReference(desired, edns)
+	desired.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	desired.Data = files
+
+	current := &corev1.Secret{}
+	if err := r.kclient.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get externaldns credentials secret %s/%s: %v", name.Namespace, name.Name, err)
+		}
+		if err := r.kclient.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create externaldns credentials secret %s/%s: %v", name.Namespace, name.Name, err)
+		}
+		logrus.Infof("created externaldns credentials secret %s/%s", name.Namespace, name.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Data, desired.Data) && reflect.DeepEqual(current.Labels, desired.Labels) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Labels = desired.Labels
+	updated.Data = desired.Data
+	if err := r.kclient.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update externaldns credentials secret %s/%s: %v", name.Namespace, name.Name, err)
+	}
+	logrus.Infof("updated externaldns credentials secret %s/%s", name.Namespace, name.Name)
+	return nil
+}
+
+// ensureOperandCredentialsSecretDeleted ensures the credentials secret of the
+// operand of edns is deleted.
+func (r *reconciler) ensureOperandCredentialsSecretDeleted(edns *operatorv1.ExternalDNS) error {
+	secret := &corev1.Secret{}
+	name := ExternalDNSCredentialsSecretNamespacedName(r.OperandNamespace, edns)
+	secret.Name = name.Name
+	secret.Namespace = name.Namespace
+	if err := r.kclient.Delete(context.TODO(), secret); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete externaldns credentials secret %s/%s: %v", secret.Namespace, secret.Name, err)
+	}
+	return nil
+}
+
+// serviceAccountTokenVolume returns the volume and the mount of the service
+// account token projected for the given audience, and the path of the
+// token file.
+func serviceAccountTokenVolume(token *operatorv1.ProviderServiceAccountToken) (corev1.Volume, corev1.VolumeMount, string) {
+	mode := credentialsFilesMode
+	expiration := serviceAccountTokenExpirationSeconds
+	volume := corev1.Volume{
+		Name: serviceAccountTokenVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{
+					ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+						Audience:          token.Audience,
+						ExpirationSeconds: &expiration,
+						Path:              serviceAccountTokenFileName,
+					},
+				}},
+				DefaultMode: &mode,
+			},
+		},
+	}
+	mountPath := token.MountPath
+	if len(mountPath) == 0 {
+		mountPath = defaultServiceAccountTokenMountPath
+	}
+	mount := corev1.VolumeMount{
+		Name:      serviceAccountTokenVolumeName,
+		MountPath: mountPath,
+		ReadOnly:  true,
+	}
+	return volume, mount, path.Join(mountPath, serviceAccountTokenFileName)
+}
+
+// serviceAccountTokenEnv returns the environment variables pointing the
+// cloud SDK of provider at the projected token file. The google provider
+// reads the token file from its credential configuration instead.
+func serviceAccountTokenEnv(provider operatorv1.ProviderType, tokenFile, roleARN string) []corev1.EnvVar {
+	switch provider {
+	case operatorv1.AWSProvider:
+		env := []corev1.EnvVar{{Name: awsWebIdentityTokenFileEnvVar, Value: tokenFile}}
+		if len(roleARN) != 0 {
+			env = append(env, corev1.EnvVar{Name: awsRoleARNEnvVar, Value: roleARN})
+		}
+		return env
+	case operatorv1.AzureProvider:
+		return []corev1.EnvVar{{Name: azureFederatedTokenFileEnvVar, Value: tokenFile}}
+	}
+	return nil
+}
+
+// operandCredentialsVolume returns the volume and the mount of the
+// credentials files of the operand of edns.
+func operandCredentialsVolume(operandNamespace string, edns *operatorv1.ExternalDNS) (corev1.Volume, corev1.VolumeMount) {
+	mode := credentialsFilesMode
+	volume := corev1.Volume{
+		Name: operandCredentialsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  ExternalDNSCredentialsSecretNamespacedName(operandNamespace, edns).Name,
+				DefaultMode: &mode,
+			},
+		},
+	}
+	mount := corev1.VolumeMount{
+		Name:      operandCredentialsVolumeName,
+		MountPath: operandCredentialsMountPath,
+		ReadOnly:  true,
+	}
+	return volume, mount
+}
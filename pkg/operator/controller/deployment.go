@@ -2,13 +2,21 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	operatorv1 "github.com/danehans/api/operator/v1"
 	"github.com/danehans/external-dns-operator/pkg/manifests"
+	"github.com/danehans/external-dns-operator/pkg/util/slice"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -16,16 +24,240 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	configv1 "github.com/openshift/api/config/v1"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	// defaultMetricsAddress is the listen address used by the operand to
+	// serve metrics and health checks when none is specified.
+	defaultMetricsAddress = ":7979"
+
+	// metricsPortName is the name of the operand container's metrics port.
+	metricsPortName = "metrics"
+
+	// defaultProgressDeadlineSeconds is the progress deadline of the
+	// operand deployment when none is specified, the API server default.
+	defaultProgressDeadlineSeconds int32 = 600
+
+	// defaultRevisionHistoryLimit is the number of old ReplicaSets of the
+	// operand deployment kept when none is specified. It is lower than the
+	// API server default since configuration changes roll out new pods.
+	defaultRevisionHistoryLimit int32 = 2
+
+	// defaultAWSAPIRetries is the default number of AWS API call retries
+	// of the operand.
+	defaultAWSAPIRetries int32 = 3
+
+	// configHashAnnotation is the operand pod template annotation holding a
+	// hash of the operand configuration, so every configuration change
+	// rolls out new pods that can be correlated with it.
+	configHashAnnotation = "externaldns.operator.openshift.io/config-hash"
+
+	// awsDynamoDBEndpointEnvVar is the AWS SDK environment variable
+	// overriding the DynamoDB endpoint of the operand.
+	awsDynamoDBEndpointEnvVar = "AWS_ENDPOINT_URL_DYNAMODB"
+
+	// awsUserAgentAppIDEnvVar is the AWS SDK environment variable adding
+	// an application id to the user agent of the operand.
+	awsUserAgentAppIDEnvVar = "AWS_SDK_UA_APP_ID"
+
+	// webhookSidecarName is the name of the webhook provider sidecar
+	// container of the operand.
+	webhookSidecarName = "webhook"
+
+	// defaultWebhookProviderURL is the webhook provider URL used when none
+	// is specified, where webhook sidecars listen by default.
+	defaultWebhookProviderURL = "http://localhost:8888"
+
+	// flagValidationContainerName is the name of the operand init
+	// container validating the ExternalDNS controller flags.
+	flagValidationContainerName = "validate-flags"
+
+	// flagValidationScript checks that every flag name passed as an
+	// argument is listed by "external-dns --help", accepting the "[no-]"
+	// form of boolean flags, and reports the unsupported flags in the
+	// termination message of the init container.
+	flagValidationScript = `help="$(external-dns --help 2>&1)"
+unsupported=""
+for f in "$@"; do
+  if ! printf '%s\n' "$help" | grep -qE -e "--(\[no-\])?${f}([= ]|\$)" -e "--\[no-\]${f#no-}([= ]|\$)"; then
+    unsupported="$unsupported --$f"
+  fi
+done
+if [ -n "$unsupported" ]; then
+  echo "external-dns image does not support flags:$unsupported" | tee /dev/termination-log >&2
+  exit 1
+fi
+`
+
+	// credentialsFilesVolumeName is the name of the operand volume holding
+	// the provider credentials files.
+	credentialsFilesVolumeName = "provider-credentials"
+
+	// defaultCredentialsFilesMountPath is the directory the provider
+	// credentials files are mounted in when none is specified.
+	defaultCredentialsFilesMountPath = "/etc/externaldns/credentials"
+
+	// cacheVolumeName is the name of the operand emptyDir volume holding
+	// the cache and temporary files of the ExternalDNS controller.
+	cacheVolumeName = "cache"
+
+	// defaultCacheVolumeMountPath is the directory the cache volume is
+	// mounted in when none is specified.
+	defaultCacheVolumeMountPath = "/var/cache/externaldns"
+
+	// operandLeaseVolumeName is the name of the operand downward API
+	// volume exposing the pod labels to the lease wrapper.
+	operandLeaseVolumeName = "lease"
+
+	// operandLeaseMountPath is the directory the lease volume is mounted
+	// in, and operandLeaseLabelsFile the file of the pod labels in it.
+	operandLeaseMountPath  = "/etc/externaldns/lease"
+	operandLeaseLabelsFile = "labels"
+
+	// operandLeaseScript runs the ExternalDNS controller with the args
+	// following "--" once the pod is granted the lease of its txt owner
+	// id. Until then an idle ExternalDNS controller runs with the args
+	// preceding "--", see operandLeaseStandbyArgs, so that the pod serves
+	// its probes without calling the provider. The script needs /bin/sh
+	// and grep in the ExternalDNS image.
+	operandLeaseScript = `standby=""
+while [ "$#" -gt 0 ] && [ "$1" != "--" ]; do
+  standby="$standby $1"
+  shift
+done
+shift
+labels="` + operandLeaseMountPath + "/" + operandLeaseLabelsFile + `"
+holder='` + operandLeaseHolderLabel + `="true"'
+pid=""
+trap '[ -n "$pid" ] && kill "$pid"; exit 0' TERM INT
+until grep -qxF "$holder" "$labels" 2>/dev/null; do
+  if [ -z "$pid" ] || ! kill -0 "$pid" 2>/dev/null; then
+    external-dns $standby &
+    pid=$!
+  fi
+  sleep 5
+done
+if [ -n "$pid" ]; then
+  kill "$pid"
+  wait "$pid"
+fi
+exec external-dns "$@"
+`
+
+	// operandLeaseStandbyInterval is the sync interval of the idle
+	// ExternalDNS controller of a pod waiting for the lease.
+	operandLeaseStandbyInterval = "1h"
+
+	// xdgCacheHomeEnvVar and tmpDirEnvVar point the cache and temporary
+	// files of the ExternalDNS controller at the cache volume.
+	xdgCacheHomeEnvVar = "XDG_CACHE_HOME"
+	tmpDirEnvVar       = "TMPDIR"
+
+	// credentialsFilesMode makes the provider credentials files readable
+	// by the owner and the fsGroup of the pod only; the operand doesn't
+	// run as the owner, so it reads them as a member of the fsGroup.
+	credentialsFilesMode int32 = 0440
+
+	// defaultOperandContainerName is the name of the ExternalDNS controller
+	// container in the operand deployment manifest.
+	defaultOperandContainerName = "externaldns"
+)
+
+// providerMetadataPrefixFlags maps the spec.provider.metadata key prefixes
+// known to each provider to the ExternalDNS controller flag that receives
+// the rest of the key and the value as a key=value pair.
+var providerMetadataPrefixFlags = map[operatorv1.ProviderType]map[string]string{
+	operatorv1.AWSProvider: {
+		"serviceTag/": "--aws-sd-create-tag",
+	},
+}
+
+// sidecarNames are the names of the sidecar containers the operator may run
+// alongside the ExternalDNS controller.
+var sidecarNames = []string{credentialsSidecarName, webhookSidecarName}
+
+// initContainerDefaultedFields are the init container fields defaulted by
+// the API server, which are ignored when detecting changes to avoid
+// updating the operand deployment on every reconcile.
+var initContainerDefaultedFields = []string{"TerminationMessagePath", "TerminationMessagePolicy", "ImagePullPolicy"}
+
+// managedFlagNames are the names of the ExternalDNS controller flags set by
+// the operator, which cannot be set through spec.experimentalArgs.
+var managedFlagNames = []string{
+	"always-publish-not-ready-addresses",
+	"annotation-prefix",
+	"aws-api-retries",
+	"aws-assume-role",
+	"aws-assume-role-external-id",
+	"aws-batch-change-size-bytes",
+	"aws-batch-change-size-values",
+	"aws-evaluate-target-health",
+	"aws-prefer-cname",
+	"aws-sd-create-tag",
+	"aws-sd-service-cleanup",
+	"aws-sd-service-type",
+	"aws-zone-match-parent",
+	"aws-zone-tags",
+	"aws-zone-type",
+	"aws-zones-cache-duration",
+	"azure-config-file",
+	"combine-fqdn-annotation",
+	"connector-source-server",
+	"crd-source-status-update",
+	"default-targets",
+	"domain-filter",
+	"dynamodb-region",
+	"dynamodb-table",
+	"events",
+	"exclude-domains",
+	"exclude-unschedulable",
+	"fqdn-template",
+	"google-batch-change-interval",
+	"google-batch-change-size",
+	"google-project",
+	"google-zone-visibility",
+	"ignore-hostname-annotation",
+	"ingress-class",
+	"inmemory-zone",
+	"interval",
+	"label-filter",
+	"metrics-address",
+	"migrate-from-txt-owner",
+	"min-event-sync-interval",
+	"min-ttl",
+	"namespace",
+	"once",
+	"policy",
+	"provider",
+	"provider-cache-time",
+	"publish-host-ip",
+	"regex-domain-exclusion",
+	"regex-domain-filter",
+	"registry",
+	"service-type-filter",
+	"source",
+	"txt-owner-id",
+	"txt-prefix",
+	"webhook-provider-url",
+	"zone-id-filter",
+}
+
+// managedEnvVarNames are the operand environment variables set by the
+// operator, which cannot be overridden through spec.provider.env.
+var managedEnvVarNames = []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", awsDynamoDBEndpointEnvVar, awsUserAgentAppIDEnvVar, googleCredentialsEnvVar,
+	awsWebIdentityTokenFileEnvVar, awsRoleARNEnvVar, azureFederatedTokenFileEnvVar, xdgCacheHomeEnvVar, tmpDirEnvVar}
+
 // ensureExternalDNSDeployment ensures an ExternalDNS deployment exists for the
 // given externalDNS resource.
 func (r *reconciler) ensureExternalDNSDeployment(eds *operatorv1.ExternalDNS, dnsConfig *configv1.DNS,
 	infraConfig *configv1.Infrastructure) error {
-	desired := r.desiredExternalDNSDeployment(eds, r.Config.ExternalDNSImage, infraConfig)
+	desired := r.desiredExternalDNSDeployment(eds, r.Config.ExternalDNSImage, dnsConfig, infraConfig)
 	current, err := r.currentExternalDNSDeployment(eds)
 	if err != nil {
 		return err
@@ -35,7 +267,11 @@ func (r *reconciler) ensureExternalDNSDeployment(eds *operatorv1.ExternalDNS, dn
 		if err := r.createExternalDNSDeployment(desired); err != nil {
 			return err
 		}
-	case current != nil:
+	case current.Spec.Paused:
+		// Leave a paused deployment alone, e.g. while an admin debugs the
+		// operand; the OperandPaused condition reports it.
+		logrus.Infof("ExternalDNS deployment %s/%s is paused; skipping update", current.Namespace, current.Name)
+	default:
 		if err := r.updateExternalDNSDeployment(current, desired); err != nil {
 			return err
 		}
@@ -46,10 +282,13 @@ func (r *reconciler) ensureExternalDNSDeployment(eds *operatorv1.ExternalDNS, dn
 // ensureExternalDNSDeploymentDeleted ensures that any Deployment
 // resources associated with the externaldns are deleted.
 func (r *reconciler) ensureExternalDNSDeploymentDeleted(eds *operatorv1.ExternalDNS) error {
-	deployment := &appsv1.Deployment{}
-	name := ExternalDNSDeploymentNamespacedName(eds)
-	deployment.Name = name.Name
-	deployment.Namespace = name.Namespace
+	deployment, err := r.currentExternalDNSDeployment(eds)
+	if err != nil {
+		return err
+	}
+	if deployment == nil {
+		return nil
+	}
 	if err := r.kclient.Delete(context.TODO(), deployment); err != nil {
 		if !errors.IsNotFound(err) {
 			return err
@@ -60,11 +299,12 @@ func (r *reconciler) ensureExternalDNSDeploymentDeleted(eds *operatorv1.External
 
 // desiredExternalDNSDeployment returns the desired ExternalDNS deployment.
 func (r *reconciler) desiredExternalDNSDeployment(edns *operatorv1.ExternalDNS, ExternalDNSImage string,
-	infraConfig *configv1.Infrastructure) *appsv1.Deployment {
+	dnsConfig *configv1.DNS, infraConfig *configv1.Infrastructure) *appsv1.Deployment {
 	deployment := manifests.ExternalDNSDeployment()
-	name := ExternalDNSDeploymentNamespacedName(edns)
+	name := ExternalDNSDeploymentNamespacedName(r.OperandNamespace, edns)
 	deployment.Name = name.Name
 	deployment.Namespace = name.Namespace
+	setExternalDNSOwnerReference(deployment, edns)
 
 	deployment.Labels = map[string]string{
 		// associate the deployment with the externaldns
@@ -73,7 +313,50 @@ func (r *reconciler) desiredExternalDNSDeployment(edns *operatorv1.ExternalDNS,
 
 	// Ensure the deployment adopts only its own pods.
 	deployment.Spec.Selector = ExternalDNSDeploymentPodSelector(edns)
-	deployment.Spec.Template.Labels = deployment.Spec.Selector.MatchLabels
+	deployment.Spec.Template.Labels = map[string]string{
+		// associate the pods with the externaldns
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	for k, v := range deployment.Spec.Selector.MatchLabels {
+		deployment.Spec.Template.Labels[k] = v
+	}
+	for _, k := range edns.Spec.PropagatedLabels {
+		v, ok := edns.Labels[k]
+		if !ok {
+			continue
+		}
+		if _, managed := deployment.Labels[k]; !managed {
+			deployment.Labels[k] = v
+		}
+		if _, managed := deployment.Spec.Template.Labels[k]; !managed {
+			deployment.Spec.Template.Labels[k] = v
+		}
+	}
+	deployment.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	progressDeadlineSeconds := defaultProgressDeadlineSeconds
+	if edns.Spec.ProgressDeadlineSeconds != nil {
+		progressDeadlineSeconds = *edns.Spec.ProgressDeadlineSeconds
+	}
+	deployment.Spec.ProgressDeadlineSeconds = &progressDeadlineSeconds
+	revisionHistoryLimit := defaultRevisionHistoryLimit
+	if edns.Spec.RevisionHistoryLimit != nil {
+		revisionHistoryLimit = *edns.Spec.RevisionHistoryLimit
+	}
+	deployment.Spec.RevisionHistoryLimit = &revisionHistoryLimit
+	// Leave the replicas of an autoscaled deployment to its HPA.
+	if edns.Spec.Autoscaling == nil {
+		replicas := int32(1)
+		if edns.Spec.Replicas != nil {
+			replicas = *edns.Spec.Replicas
+		}
+		deployment.Spec.Replicas = &replicas
+	}
+	switch {
+	case len(edns.Spec.PriorityClassName) != 0:
+		deployment.Spec.Template.Spec.PriorityClassName = edns.Spec.PriorityClassName
+	case r.OperandPriorityClassName != nil:
+		deployment.Spec.Template.Spec.PriorityClassName = *r.OperandPriorityClassName
+	}
 
 	// Prevent colocation of controller pods to enable simple horizontal scaling
 	deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{
@@ -98,73 +381,621 @@ func (r *reconciler) desiredExternalDNSDeployment(edns *operatorv1.ExternalDNS,
 		},
 	}
 
-	deployment.Spec.Template.Spec.Containers[0].Image = ExternalDNSImage
+	container := operandContainer(&deployment.Spec.Template.Spec, r.OperandContainerName)
+	container.Image = ExternalDNSImage
+	if len(edns.Spec.ImageOverride) != 0 {
+		container.Image = edns.Spec.ImageOverride
+	}
+	if len(edns.Spec.ImagePullPolicy) != 0 {
+		container.ImagePullPolicy = edns.Spec.ImagePullPolicy
+	}
+	if len(edns.Spec.Resources.Requests) != 0 || len(edns.Spec.Resources.Limits) != 0 {
+		container.Resources = *edns.Spec.Resources.DeepCopy()
+	}
 
-	owner := "--txt-owner-id=" + TextOwnerID(infraConfig, edns)
-	deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args,
-		"--registry=txt", owner)
+	metricsAddress := defaultMetricsAddress
+	if len(edns.Spec.MetricsAddress) != 0 {
+		metricsAddress = edns.Spec.MetricsAddress
+	}
+	container.Args = append(container.Args,
+		"--metrics-address="+metricsAddress)
+	// The probes reference the metrics port by name, so only the
+	// container port needs to follow the metrics address.
+	if port, err := metricsPort(metricsAddress); err == nil {
+		for i := range container.Ports {
+			if container.Ports[i].Name == metricsPortName {
+				container.Ports[i].ContainerPort = port
+			}
+		}
+	}
+
+	if edns.Spec.Policy != nil {
+		container.Args = append(container.Args,
+			"--policy="+string(*edns.Spec.Policy))
+	}
+
+	switch edns.Spec.Registry {
+	case operatorv1.NoopRegistryType:
+		// Ownership isn't tracked, so no owner id is needed.
+		container.Args = append(container.Args,
+			"--registry=noop")
+	case operatorv1.DynamoDBRegistryType:
+		container.Args = append(container.Args,
+			"--registry=dynamodb", "--txt-owner-id="+TextOwnerID(infraConfig, edns))
+		args, env := awsDynamoDBArgsAndEnv(edns.Spec.Provider.AWSDynamoDB)
+		container.Args = append(container.Args, args...)
+		container.Env = append(container.Env, env...)
+	default:
+		// The published owner id lags behind an owner id change
+		// deferred by an ongoing migration.
+		id := edns.Status.TextOwnerID
+		if len(id) == 0 {
+			id = TextOwnerID(infraConfig, edns)
+		}
+		owner := "--txt-owner-id=" + id
+		container.Args = append(container.Args,
+			"--registry=txt", owner)
+		if len(edns.Spec.TXTPrefix) != 0 {
+			container.Args = append(container.Args,
+				"--txt-prefix="+edns.Spec.TXTPrefix)
+		}
+		// Re-adopt records owned by the previous owner id during a
+		// txt owner id migration.
+		if prev := edns.Status.PreviousTextOwnerID; len(prev) != 0 {
+			container.Args = append(container.Args,
+				"--migrate-from-txt-owner="+prev)
+		}
+	}
 
 	provider := "--provider=" + string(*edns.Status.ProviderType)
-	deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args, provider)
+	container.Args = append(container.Args, provider)
+	if *edns.Status.ProviderType == operatorv1.InMemoryProvider {
+		container.Args = append(container.Args,
+			"--inmemory-zone="+edns.Status.BaseDomain)
+	}
+	if *edns.Status.ProviderType == operatorv1.WebhookProvider {
+		container.Args = append(container.Args,
+			"--webhook-provider-url="+webhookProviderURL(edns.Spec.Provider.Webhook))
+	}
 
-	//domain := "--domain-filter=" + strings.Trimedns.Status.BaseDomain
-	//deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args, domain)
+	// Scope the operand to the base domain, unless a regular expression
+	// domain filter is set, which the ExternalDNS controller honors instead.
+	if IsStatusBaseDomainSet(edns) && !hasRegexDomainFilter(edns) {
+		container.Args = append(container.Args,
+			"--domain-filter="+strings.TrimSpace(edns.Status.BaseDomain))
+	}
 
-	src := "--source="
 	for _, s := range edns.Spec.Sources {
-		src += string(*s)
-		deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args, src)
+		container.Args = append(container.Args, "--source="+string(*s))
+	}
+
+	// startupProbe is not available in the targeted Kubernetes API, so
+	// delay the liveness probe by the equivalent startup window instead.
+	if edns.Spec.StartupFailureThreshold != nil {
+		if probe := container.LivenessProbe; probe != nil {
+			probe.InitialDelaySeconds = *edns.Spec.StartupFailureThreshold * probe.PeriodSeconds
+		}
+	}
+
+	if len(edns.Spec.RegexDomainFilter) != 0 {
+		container.Args = append(container.Args,
+			"--regex-domain-filter="+edns.Spec.RegexDomainFilter)
+	}
+	if len(edns.Spec.RegexDomainExclusion) != 0 {
+		container.Args = append(container.Args,
+			"--regex-domain-exclusion="+edns.Spec.RegexDomainExclusion)
+	}
+	for _, d := range edns.Spec.ExcludeDomains {
+		container.Args = append(container.Args, "--exclude-domains="+d)
+	}
+
+	if len(edns.Spec.FQDNTemplate) != 0 {
+		container.Args = append(container.Args, "--fqdn-template="+edns.Spec.FQDNTemplate)
+	}
+	if edns.Spec.IgnoreHostnameAnnotation {
+		container.Args = append(container.Args, "--ignore-hostname-annotation")
+	}
+	if edns.Spec.CombineFQDNAnnotation {
+		container.Args = append(container.Args, "--combine-fqdn-annotation")
+	}
+
+	for _, target := range edns.Spec.DefaultTargets {
+		container.Args = append(container.Args, "--default-targets="+target)
+	}
+
+	if len(edns.Spec.AnnotationPrefix) != 0 {
+		container.Args = append(container.Args, "--annotation-prefix="+edns.Spec.AnnotationPrefix)
+	}
+
+	if edns.Spec.MinTTL != nil {
+		container.Args = append(container.Args,
+			"--min-ttl="+strconv.FormatInt(*edns.Spec.MinTTL, 10)+"s")
+	}
+
+	if hasSourceType(edns, operatorv1.ServiceType) {
+		for _, t := range edns.Spec.ServiceTypeFilter {
+			container.Args = append(container.Args,
+				"--service-type-filter="+string(t))
+		}
+		if edns.Spec.PublishHostIP {
+			container.Args = append(container.Args,
+				"--publish-host-ip")
+		}
+		if edns.Spec.AlwaysPublishNotReadyAddresses {
+			container.Args = append(container.Args,
+				"--always-publish-not-ready-addresses")
+		}
+	}
+
+	if hasSourceType(edns, operatorv1.IngressType) {
+		for _, class := range edns.Spec.IngressClassFilter {
+			container.Args = append(container.Args,
+				"--ingress-class="+class)
+		}
+	}
+
+	if edns.Spec.IncludeUnschedulableNodes && hasSourceType(edns, operatorv1.NodeType) {
+		container.Args = append(container.Args,
+			"--no-exclude-unschedulable")
+	}
+
+	if edns.Spec.DisableCRDSourceStatusUpdates && hasSourceType(edns, operatorv1.CRDType) {
+		container.Args = append(container.Args, "--no-crd-source-status-update")
+	}
+
+	if hasSourceType(edns, operatorv1.ConnectorType) {
+		container.Args = append(container.Args,
+			"--connector-source-server="+edns.Spec.ConnectorSourceServer)
+	}
+
+	if len(edns.Spec.Namespace) != 0 {
+		container.Args = append(container.Args,
+			"--namespace="+edns.Spec.Namespace)
+	}
+	if len(edns.Spec.LabelFilter) != 0 {
+		container.Args = append(container.Args,
+			"--label-filter="+edns.Spec.LabelFilter)
 	}
 
 	if *edns.Status.ProviderType == operatorv1.AWSProvider {
-		authEnvVars := []corev1.EnvVar{
-			{
-				Name: "AWS_ACCESS_KEY_ID",
-				Value: string(r.Credentials.Data["aws_access_key_id"]),
-			},
-			{
-				Name: "AWS_SECRET_ACCESS_KEY",
-				Value: string(r.Credentials.Data["aws_secret_access_key"]),
-			},
+		// Static credentials are omitted when the operand assumes a role
+		// through its service account.
+		if hasStaticAWSCredentials(r.Credentials) {
+			authEnvVars := []corev1.EnvVar{
+				{
+					Name:  "AWS_ACCESS_KEY_ID",
+					Value: string(r.Credentials.Data["aws_access_key_id"]),
+				},
+				{
+					Name:  "AWS_SECRET_ACCESS_KEY",
+					Value: string(r.Credentials.Data["aws_secret_access_key"]),
+				},
+			}
+			container.Env = append(container.Env, authEnvVars...)
+		}
+		retries := defaultAWSAPIRetries
+		if edns.Spec.Provider.AWSAPIRetries != nil {
+			retries = *edns.Spec.Provider.AWSAPIRetries
+		}
+		container.Args = append(container.Args,
+			"--no-aws-evaluate-target-health", "--aws-api-retries="+strconv.Itoa(int(retries)))
+		if size := edns.Spec.Provider.AWSBatchChangeSizeBytes; size != nil {
+			container.Args = append(container.Args,
+				"--aws-batch-change-size-bytes="+strconv.Itoa(int(*size)))
+		}
+		if size := edns.Spec.Provider.AWSBatchChangeSizeValues; size != nil {
+			container.Args = append(container.Args,
+				"--aws-batch-change-size-values="+strconv.Itoa(int(*size)))
+		}
+		if role := edns.Spec.Provider.AWSAssumeRole; len(role) != 0 {
+			container.Args = append(container.Args,
+				"--aws-assume-role="+role)
+			if id := edns.Spec.Provider.AWSAssumeRoleExternalID; len(id) != 0 {
+				container.Args = append(container.Args,
+					"--aws-assume-role-external-id="+id)
+			}
+		}
+		container.Args = append(container.Args, durationArgs("--aws-zones-cache-duration", edns.Spec.Provider.AWSZonesCacheDuration)...)
+		if edns.Spec.Provider.AWSZoneMatchParent {
+			container.Args = append(container.Args, "--aws-zone-match-parent")
+		}
+		container.Args = append(container.Args, awsTagsArgs("--aws-sd-create-tag", edns.Spec.Provider.AWSResourceTags)...)
+		if cloudMap := edns.Spec.Provider.AWSCloudMap; cloudMap != nil {
+			if len(cloudMap.ServiceType) != 0 {
+				container.Args = append(container.Args, "--aws-sd-service-type="+string(cloudMap.ServiceType))
+			}
+			if cloudMap.ServiceCleanup {
+				container.Args = append(container.Args, "--aws-sd-service-cleanup")
+			}
+		}
+		if id := edns.Spec.Provider.UserAgentAppID; len(id) != 0 {
+			container.Env = append(container.Env, corev1.EnvVar{Name: awsUserAgentAppIDEnvVar, Value: id})
 		}
-		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, authEnvVars...)
-		deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args,
-			"--no-aws-evaluate-target-health", "--aws-api-retries=3")
-		if *edns.Spec.ZoneType == operatorv1.PublicZoneType {
-			deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args,
-				"--aws-zone-type=public")
+		if awsPreferCNAME(edns.Spec.Provider.AWSTargetRecordTypes, r.effectiveZoneVisibility(edns, dnsConfig)) {
+			container.Args = append(container.Args,
+				"--aws-prefer-cname")
 		}
-		if *edns.Spec.ZoneType == operatorv1.PrivateZoneType {
-			deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args,
-				"--aws-zone-type=private")
+	}
+
+	switch *edns.Status.ProviderType {
+	case operatorv1.AzureProvider:
+		volume, mount := operandCredentialsVolume(r.OperandNamespace, edns)
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		container.Args = append(container.Args,
+			"--azure-config-file="+operandCredentialsMountPath+"/"+azureConfigFileName)
+	case operatorv1.GoogleProvider:
+		volume, mount := operandCredentialsVolume(r.OperandNamespace, edns)
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  googleCredentialsEnvVar,
+			Value: operandCredentialsMountPath + "/" + googleCredentialsFileName,
+		})
+		if project, err := googleProjectID(r.Credentials); err != nil {
+			logrus.Warningf("omitting the gcp project of externaldns %s: %v", edns.Name, err)
+		} else {
+			container.Args = append(container.Args, "--google-project="+project)
+		}
+		if size := edns.Spec.Provider.GoogleBatchChangeSize; size != nil {
+			container.Args = append(container.Args,
+				"--google-batch-change-size="+strconv.Itoa(int(*size)))
 		}
+		container.Args = append(container.Args, durationArgs("--google-batch-change-interval", edns.Spec.Provider.GoogleBatchChangeInterval)...)
+	}
+
+	if p, ok := providers[*edns.Status.ProviderType]; ok {
+		container.Args = append(container.Args,
+			p.zoneVisibilityArgs(r.effectiveZoneVisibility(edns, dnsConfig))...)
+	}
+
+	if events := edns.Spec.Events; events != nil {
+		container.Args = append(container.Args, "--events")
+		if events.FullResyncInterval != nil {
+			container.Args = append(container.Args, durationArgs("--interval", events.FullResyncInterval)...)
+		}
+	}
+	container.Args = append(container.Args, durationArgs("--interval", edns.Spec.Interval)...)
+	container.Args = append(container.Args, durationArgs("--min-event-sync-interval", edns.Spec.MinEventSyncInterval)...)
+	container.Args = append(container.Args, durationArgs("--provider-cache-time", edns.Spec.Provider.CacheTime)...)
+
+	container.Env = append(container.Env, edns.Spec.Provider.Env...)
+	if files := edns.Spec.Provider.CredentialsFiles; files != nil {
+		mode := credentialsFilesMode
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: credentialsFilesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: files.SecretName, DefaultMode: &mode},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      credentialsFilesVolumeName,
+			MountPath: credentialsFilesMountPath(files),
+			ReadOnly:  true,
+		})
+	}
+	if token := edns.Spec.Provider.ServiceAccountToken; token != nil {
+		volume, mount, tokenFile := serviceAccountTokenVolume(token)
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		container.Env = append(container.Env, serviceAccountTokenEnv(*edns.Status.ProviderType, tokenFile, r.RoleARN)...)
+	}
+	if cache := edns.Spec.CacheVolume; cache != nil {
+		volume, mount := cacheVolume(cache)
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: xdgCacheHomeEnvVar, Value: mount.MountPath},
+			corev1.EnvVar{Name: tmpDirEnvVar, Value: mount.MountPath})
+	}
+	if operandLeaseEnabled(edns) {
+		// More than one pod may run, so only the pod holding the lease of
+		// the txt owner id writes records.
+		volume, mount := operandLeaseVolume()
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		container.Command = append([]string{"/bin/sh", "-c", operandLeaseScript, "external-dns"},
+			append(operandLeaseStandbyArgs(deployment.Namespace, metricsAddress), "--")...)
+	}
+	if edns.Spec.FSGroup != nil {
+		if deployment.Spec.Template.Spec.SecurityContext == nil {
+			deployment.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+		fsGroup := *edns.Spec.FSGroup
+		deployment.Spec.Template.Spec.SecurityContext.FSGroup = &fsGroup
+	}
+	deployment.Spec.Template.Spec.InitContainers = append(deployment.Spec.Template.Spec.InitContainers, edns.Spec.InitContainers...)
+	deployment.Spec.Template.Spec.ReadinessGates = edns.Spec.ReadinessGates
+	if sidecar := webhookSidecar(edns); sidecar != nil {
+		// The kubelet starts containers in order, so the webhook is started
+		// first and its postStart hook can hold back the ExternalDNS
+		// controller until the webhook serves requests.
+		deployment.Spec.Template.Spec.Containers = append([]corev1.Container{*sidecar}, deployment.Spec.Template.Spec.Containers...)
+		container = operandContainer(&deployment.Spec.Template.Spec, r.OperandContainerName)
+	}
+	if sidecar, volume, mount := credentialsSidecar(edns); sidecar != nil {
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		// The credentials sidecar is started first, so its postStart hook
+		// holds back the other containers until the credentials are written.
+		deployment.Spec.Template.Spec.Containers = append([]corev1.Container{*sidecar}, deployment.Spec.Template.Spec.Containers...)
+		container = operandContainer(&deployment.Spec.Template.Spec, r.OperandContainerName)
 	}
 
 	if edns.Spec.Provider.Args != nil {
 		for _, a := range edns.Spec.Provider.Args {
-			deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args, a)
+			container.Args = append(container.Args, a)
 		}
 	}
+	if len(edns.Spec.ExperimentalArgs) != 0 {
+		logrus.Warningf("externaldns %s uses unsupported experimental args: %v", edns.Name, edns.Spec.ExperimentalArgs)
+		container.Args = append(container.Args, edns.Spec.ExperimentalArgs...)
+	}
 
-	if edns.Spec.Provider.ZoneFilter != nil {
+	metadataArgs, unknown := providerMetadataArgs(*edns.Status.ProviderType, edns.Spec.Provider.Metadata)
+	if len(unknown) != 0 {
+		logrus.Warningf("ignoring provider metadata keys of externaldns %s unknown to provider %s: %v",
+			edns.Name, *edns.Status.ProviderType, unknown)
+	}
+	container.Args = append(container.Args, metadataArgs...)
+
+	// In-memory zones are only identified by the base domain.
+	if edns.Spec.Provider.ZoneFilter != nil && *edns.Status.ProviderType != operatorv1.InMemoryProvider {
 		for _, z := range edns.Spec.Provider.ZoneFilter {
+			if z == nil {
+				continue
+			}
 			if len(z.ID) != 0 {
 				zf := "--zone-id-filter=" + z.ID
-				deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args, zf)
+				container.Args = append(container.Args, zf)
+				continue
+			}
+			if *edns.Status.ProviderType == operatorv1.AWSProvider {
+				container.Args = append(container.Args,
+					awsTagsArgs("--aws-zone-tags", z.Tags)...)
 			}
-			// Tag filters are broken upstream:
-			// https://github.com/kubernetes-incubator/external-dns/issues/1019
 		}
 	}
 
+	if r.ValidateOperandFlags {
+		// Validate the flags before any other init container runs, so the
+		// pod fails fast.
+		deployment.Spec.Template.Spec.InitContainers = append([]corev1.Container{flagValidationContainer(container)},
+			deployment.Spec.Template.Spec.InitContainers...)
+	}
+
+	deployment.Spec.Template.Annotations = map[string]string{
+		configHashAnnotation: operandConfigHash(container),
+	}
+	if hash := operandCredentialsHash(*edns.Status.ProviderType, r.Credentials); len(hash) != 0 {
+		deployment.Spec.Template.Annotations[credentialsHashAnnotation] = hash
+	}
+
 	return deployment
 }
 
+// flagValidationContainer returns an init container running the image of
+// the given operand container to check that it recognizes the flags of
+// the operand container.
+func flagValidationContainer(operand *corev1.Container) corev1.Container {
+	return corev1.Container{
+		Name:                     flagValidationContainerName,
+		Image:                    operand.Image,
+		ImagePullPolicy:          operand.ImagePullPolicy,
+		Command:                  []string{"/bin/sh", "-c", flagValidationScript, flagValidationContainerName},
+		Args:                     operandFlagNames(operand.Args),
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+}
+
+// operandFlagNames returns the sorted, unique names of the flags in the
+// given args, without the leading dashes and values.
+func operandFlagNames(args []string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, a := range args {
+		if !strings.HasPrefix(a, "--") {
+			continue
+		}
+		name := strings.SplitN(strings.TrimPrefix(a, "--"), "=", 2)[0]
+		if len(name) == 0 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// operandConfigHash returns a hash of the effective configuration, args and
+// env, of the operand container.
+func operandConfigHash(container *corev1.Container) string {
+	config, err := json.Marshal(struct {
+		Args []string        `json:"args"`
+		Env  []corev1.EnvVar `json:"env"`
+	}{container.Args, container.Env})
+	if err != nil {
+		// Args and env always marshal, but don't fail the
+		// reconcile over the hash if they ever don't.
+		logrus.Errorf("failed to marshal operand config: %v", err)
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(config))
+}
+
+// providerMetadataArgs translates metadata into ExternalDNS controller args
+// for provider, sorted by key so the resulting args are stable. Keys unknown
+// to provider are returned separately.
+func providerMetadataArgs(provider operatorv1.ProviderType, metadata map[string]string) ([]string, []string) {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args, unknown := []string{}, []string{}
+	for _, k := range keys {
+		known := false
+		for prefix, flag := range providerMetadataPrefixFlags[provider] {
+			if name := strings.TrimPrefix(k, prefix); name != k && len(name) != 0 {
+				args = append(args, flag+"="+name+"="+metadata[k])
+				known = true
+				break
+			}
+		}
+		if !known {
+			unknown = append(unknown, k)
+		}
+	}
+	return args, unknown
+}
+
+// hasRegexDomainFilter reports whether edns sets a regular expression domain
+// filter, either in the spec or in the provider args.
+func hasRegexDomainFilter(edns *operatorv1.ExternalDNS) bool {
+	if len(edns.Spec.RegexDomainFilter) != 0 {
+		return true
+	}
+	for _, arg := range edns.Spec.Provider.Args {
+		if strings.HasPrefix(arg, "--regex-domain-filter=") {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookProviderURL returns the effective URL of the webhook provider.
+func webhookProviderURL(webhook *operatorv1.WebhookProviderSpec) string {
+	if webhook == nil || len(webhook.URL) == 0 {
+		return defaultWebhookProviderURL
+	}
+	return webhook.URL
+}
+
+// webhookSidecar returns the webhook provider sidecar container of edns,
+// or nil if it has none.
+func webhookSidecar(edns *operatorv1.ExternalDNS) *corev1.Container {
+	if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.WebhookProvider ||
+		edns.Spec.Provider.Webhook == nil || edns.Spec.Provider.Webhook.Sidecar == nil {
+		return nil
+	}
+	sidecar := edns.Spec.Provider.Webhook.Sidecar.DeepCopy()
+	sidecar.Name = webhookSidecarName
+	return sidecar
+}
+
+// awsDynamoDBArgsAndEnv returns the args and env configuring the table of
+// the dynamodb registry. The endpoint has no flag, so it's passed through
+// the AWS SDK endpoint environment variable.
+func awsDynamoDBArgsAndEnv(dynamoDB *operatorv1.AWSDynamoDBRegistrySpec) ([]string, []corev1.EnvVar) {
+	if dynamoDB == nil {
+		return nil, nil
+	}
+	var args []string
+	var env []corev1.EnvVar
+	if len(dynamoDB.Table) != 0 {
+		args = append(args, "--dynamodb-table="+dynamoDB.Table)
+	}
+	if len(dynamoDB.Region) != 0 {
+		args = append(args, "--dynamodb-region="+dynamoDB.Region)
+	}
+	if len(dynamoDB.Endpoint) != 0 {
+		env = append(env, corev1.EnvVar{Name: awsDynamoDBEndpointEnvVar, Value: dynamoDB.Endpoint})
+	}
+	return args, env
+}
+
+// resourceRequirementsEqual returns true if a and b request and limit the
+// same quantities of the same resources. Quantities are compared by value,
+// since the same quantity may be formatted differently.
+func resourceRequirementsEqual(a, b corev1.ResourceRequirements) bool {
+	return resourceListsEqual(a.Requests, b.Requests) && resourceListsEqual(a.Limits, b.Limits)
+}
+
+// resourceListsEqual returns true if a and b hold equal quantities of the
+// same resources.
+func resourceListsEqual(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, quantity := range a {
+		other, ok := b[name]
+		if !ok || quantity.Cmp(other) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// quantityComparer compares quantities by value, e.g. the size limit of the
+// cache volume, since the same quantity may be formatted differently.
+var quantityComparer = cmp.Comparer(func(a, b resource.Quantity) bool {
+	return a.Cmp(b) == 0
+})
+
+// durationArgs returns the given duration flag arg for duration. An unset
+// duration keeps the ExternalDNS controller default, while zero is passed on,
+// e.g. to disable a cache.
+func durationArgs(flag string, duration *metav1.Duration) []string {
+	if duration == nil {
+		return nil
+	}
+	return []string{flag + "=" + duration.Duration.String()}
+}
+
+// awsTagsArgs returns a key=value arg of the given flag for each of tags,
+// sorted by key so the resulting args are stable.
+func awsTagsArgs(flag string, tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys))
+	for _, k := range keys {
+		args = append(args, flag+"="+k+"="+tags[k])
+	}
+	return args
+}
+
+// zoneFilterSpansZoneTypes returns true if the zoneFilter of edns includes
+// both the public and the private zone of the cluster's dnsConfig.
+func zoneFilterSpansZoneTypes(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS) bool {
+	if dnsConfig == nil {
+		return false
+	}
+	public, private := false, false
+	for _, z := range edns.Spec.Provider.ZoneFilter {
+		if dnsZonesEqual(z, dnsConfig.Spec.PublicZone) {
+			public = true
+		}
+		if dnsZonesEqual(z, dnsConfig.Spec.PrivateZone) {
+			private = true
+		}
+	}
+	return public && private
+}
+
+// dnsZonesEqual returns true if a and b identify the same zone, either by
+// ID or, when neither has an ID, by tags.
+func dnsZonesEqual(a, b *configv1.DNSZone) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if len(a.ID) != 0 || len(b.ID) != 0 {
+		return a.ID == b.ID
+	}
+	return len(a.Tags) != 0 && reflect.DeepEqual(a.Tags, b.Tags)
+}
+
 // currentExternalDNSDeployment returns the current ExternalDNS deployment.
 func (r *reconciler) currentExternalDNSDeployment(edns *operatorv1.ExternalDNS) (*appsv1.Deployment, error) {
 	deployment := &appsv1.Deployment{}
-	if err := r.kclient.Get(context.TODO(), ExternalDNSDeploymentNamespacedName(edns), deployment); err != nil {
+	if err := r.kclient.Get(context.TODO(), ExternalDNSDeploymentNamespacedName(r.OperandNamespace, edns), deployment); err != nil {
 		if errors.IsNotFound(err) {
+			if edns.Spec.AdoptDeployment {
+				return r.adoptableExternalDNSDeployment(edns)
+			}
 			return nil, nil
 		}
 		return nil, err
@@ -172,6 +1003,55 @@ func (r *reconciler) currentExternalDNSDeployment(edns *operatorv1.ExternalDNS)
 	return deployment, nil
 }
 
+// adoptableExternalDNSDeployment returns the deployment in the operand
+// namespace labeled as owned by edns, or nil if none exists. An error is
+// returned if more than one deployment is labeled or the labeled deployment
+// doesn't run an ExternalDNS controller container, to avoid taking over
+// unrelated deployments.
+func (r *reconciler) adoptableExternalDNSDeployment(edns *operatorv1.ExternalDNS) (*appsv1.Deployment, error) {
+	deployments := &appsv1.DeploymentList{}
+	namespace := ExternalDNSDeploymentNamespacedName(r.OperandNamespace, edns).Namespace
+	labels := map[string]string{manifests.OwningExternalDNSLabel: edns.Name}
+	if err := r.kclient.List(context.TODO(), deployments, kclient.InNamespace(namespace), kclient.MatchingLabels(labels)); err != nil {
+		return nil, fmt.Errorf("failed to list deployments of externaldns %s: %v", edns.Name, err)
+	}
+	switch len(deployments.Items) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("refusing to adopt deployment for externaldns %s: %d deployments in namespace %s are labeled %s=%s",
+			edns.Name, len(deployments.Items), namespace, manifests.OwningExternalDNSLabel, edns.Name)
+	}
+	deployment := &deployments.Items[0]
+	if err := validateAdoptableDeployment(deployment, r.OperandContainerName); err != nil {
+		return nil, fmt.Errorf("refusing to adopt deployment %s/%s for externaldns %s: %v", deployment.Namespace, deployment.Name, edns.Name, err)
+	}
+	logrus.Infof("adopting deployment %s/%s for externaldns %s", deployment.Namespace, deployment.Name, edns.Name)
+	return deployment, nil
+}
+
+// validateAdoptableDeployment ensures deployment has an ExternalDNS
+// controller container of the given name, since only that container is
+// managed once adopted.
+func validateAdoptableDeployment(deployment *appsv1.Deployment, containerName string) error {
+	if operandContainer(&deployment.Spec.Template.Spec, containerName) == nil {
+		return fmt.Errorf("no container is named %q", containerName)
+	}
+	return nil
+}
+
+// operandContainer returns the container of spec with the given name, or
+// nil if there is none.
+func operandContainer(spec *corev1.PodSpec, name string) *corev1.Container {
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == name {
+			return &spec.Containers[i]
+		}
+	}
+	return nil
+}
+
 // createExternalDNSDeployment creates a ExternalDNS deployment.
 func (r *reconciler) createExternalDNSDeployment(deployment *appsv1.Deployment) error {
 	if err := r.kclient.Create(context.TODO(), deployment); err != nil {
@@ -183,7 +1063,7 @@ func (r *reconciler) createExternalDNSDeployment(deployment *appsv1.Deployment)
 
 // updateExternalDNSDeployment updates a ExternalDNS deployment.
 func (r *reconciler) updateExternalDNSDeployment(current, desired *appsv1.Deployment) error {
-	changed, updated := deploymentConfigChanged(current, desired)
+	changed, updated := deploymentConfigChanged(current, desired, r.OperandContainerName)
 	if !changed {
 		return nil
 	}
@@ -197,14 +1077,226 @@ func (r *reconciler) updateExternalDNSDeployment(current, desired *appsv1.Deploy
 
 // deploymentConfigChanged checks if current config matches the expected config
 // for the externaldns deployment and if not returns the updated config.
-func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv1.Deployment) {
-	if cmp.Equal(current.Spec.Template.Spec.Containers[0].Args, expected.Spec.Template.Spec.Containers[0].Args, cmpopts.EquateEmpty()) &&
-		current.Spec.Template.Spec.Containers[0].Image == expected.Spec.Template.Spec.Containers[0].Image {
+func deploymentConfigChanged(current, expected *appsv1.Deployment, containerName string) (bool, *appsv1.Deployment) {
+	expectedContainer := operandContainer(&expected.Spec.Template.Spec, containerName)
+	currentContainer := operandContainer(&current.Spec.Template.Spec, containerName)
+	containerMissing := currentContainer == nil
+	if containerMissing {
+		// The container was removed or renamed, so restore it.
+		current = current.DeepCopy()
+		current.Spec.Template.Spec.Containers = append(current.Spec.Template.Spec.Containers, *expectedContainer)
+		currentContainer = operandContainer(&current.Spec.Template.Spec, containerName)
+	}
+	sidecarChanged := false
+	for _, name := range sidecarNames {
+		expectedSidecar := operandContainer(&expected.Spec.Template.Spec, name)
+		currentSidecar := operandContainer(&current.Spec.Template.Spec, name)
+		if (currentSidecar == nil) != (expectedSidecar == nil) ||
+			(currentSidecar != nil && !cmp.Equal(*currentSidecar, *expectedSidecar, cmpopts.EquateEmpty(),
+				cmpopts.IgnoreFields(corev1.Container{}, initContainerDefaultedFields...))) {
+			sidecarChanged = true
+		}
+	}
+	labels, labelsChanged := mergeLabels(current.Labels, expected.Labels)
+	templateLabels, templateLabelsChanged := mergeLabels(current.Spec.Template.Labels, expected.Spec.Template.Labels)
+	if !containerMissing && !sidecarChanged && !labelsChanged && !templateLabelsChanged &&
+		cmp.Equal(currentContainer.Command, expectedContainer.Command, cmpopts.EquateEmpty()) &&
+		cmp.Equal(currentContainer.Args, expectedContainer.Args, cmpopts.EquateEmpty()) &&
+		cmp.Equal(currentContainer.Ports, expectedContainer.Ports, cmpopts.EquateEmpty()) &&
+		cmp.Equal(currentContainer.Env, expectedContainer.Env, cmpopts.EquateEmpty()) &&
+		cmp.Equal(currentContainer.LivenessProbe, expectedContainer.LivenessProbe) &&
+		cmp.Equal(currentContainer.ReadinessProbe, expectedContainer.ReadinessProbe) &&
+		currentContainer.Image == expectedContainer.Image &&
+		currentContainer.ImagePullPolicy == expectedContainer.ImagePullPolicy &&
+		resourceRequirementsEqual(currentContainer.Resources, expectedContainer.Resources) &&
+		current.Spec.Template.Annotations[configHashAnnotation] == expected.Spec.Template.Annotations[configHashAnnotation] &&
+		current.Spec.Template.Annotations[credentialsHashAnnotation] == expected.Spec.Template.Annotations[credentialsHashAnnotation] &&
+		cmp.Equal(current.Spec.Template.Spec.InitContainers, expected.Spec.Template.Spec.InitContainers, cmpopts.EquateEmpty(),
+			cmpopts.IgnoreFields(corev1.Container{}, initContainerDefaultedFields...)) &&
+		cmp.Equal(current.Spec.Template.Spec.ReadinessGates, expected.Spec.Template.Spec.ReadinessGates, cmpopts.EquateEmpty()) &&
+		current.Spec.Template.Spec.PriorityClassName == expected.Spec.Template.Spec.PriorityClassName &&
+		cmp.Equal(current.Spec.ProgressDeadlineSeconds, expected.Spec.ProgressDeadlineSeconds) &&
+		cmp.Equal(current.Spec.RevisionHistoryLimit, expected.Spec.RevisionHistoryLimit) &&
+		current.Spec.Strategy.Type == expected.Spec.Strategy.Type &&
+		(expected.Spec.Replicas == nil || cmp.Equal(current.Spec.Replicas, expected.Spec.Replicas)) &&
+		cmp.Equal(currentContainer.VolumeMounts, expectedContainer.VolumeMounts, cmpopts.EquateEmpty()) &&
+		cmp.Equal(current.Spec.Template.Spec.Volumes, expected.Spec.Template.Spec.Volumes, cmpopts.EquateEmpty(), quantityComparer) &&
+		cmp.Equal(podFSGroup(&current.Spec.Template.Spec), podFSGroup(&expected.Spec.Template.Spec)) {
 		return false, nil
 	}
 
 	updated := current.DeepCopy()
-	updated.Spec.Template.Spec.Containers[0].Args = expected.Spec.Template.Spec.Containers[0].Args
-	updated.Spec.Template.Spec.Containers[0].Image = expected.Spec.Template.Spec.Containers[0].Image
+	updatedContainer := operandContainer(&updated.Spec.Template.Spec, containerName)
+	updated.Labels = labels
+	updated.Spec.Template.Labels = templateLabels
+	updatedContainer.Command = expectedContainer.Command
+	updatedContainer.Args = expectedContainer.Args
+	updatedContainer.Ports = expectedContainer.Ports
+	updatedContainer.Env = expectedContainer.Env
+	updatedContainer.LivenessProbe = expectedContainer.LivenessProbe
+	updatedContainer.ReadinessProbe = expectedContainer.ReadinessProbe
+	updatedContainer.Image = expectedContainer.Image
+	updatedContainer.ImagePullPolicy = expectedContainer.ImagePullPolicy
+	updatedContainer.Resources = expectedContainer.Resources
+	updated.Spec.Template.Spec.InitContainers = expected.Spec.Template.Spec.InitContainers
+	updated.Spec.Template.Spec.ReadinessGates = expected.Spec.Template.Spec.ReadinessGates
+	updated.Spec.Template.Spec.PriorityClassName = expected.Spec.Template.Spec.PriorityClassName
+	updated.Spec.ProgressDeadlineSeconds = expected.Spec.ProgressDeadlineSeconds
+	updated.Spec.RevisionHistoryLimit = expected.Spec.RevisionHistoryLimit
+	updated.Spec.Strategy = expected.Spec.Strategy
+	if expected.Spec.Replicas != nil {
+		updated.Spec.Replicas = expected.Spec.Replicas
+	}
+	updatedContainer.VolumeMounts = expectedContainer.VolumeMounts
+	updated.Spec.Template.Spec.Volumes = expected.Spec.Template.Spec.Volumes
+	// Only the fsGroup of the security context is managed, so any other
+	// settings are preserved.
+	if fsGroup := podFSGroup(&expected.Spec.Template.Spec); fsGroup != nil || podFSGroup(&updated.Spec.Template.Spec) != nil {
+		if updated.Spec.Template.Spec.SecurityContext == nil {
+			updated.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+		updated.Spec.Template.Spec.SecurityContext.FSGroup = fsGroup
+	}
+	if updated.Spec.Template.Annotations == nil {
+		updated.Spec.Template.Annotations = map[string]string{}
+	}
+	if sidecarChanged {
+		// Replace the sidecars, keeping them ahead of the other containers
+		// in their expected order.
+		containers := []corev1.Container{}
+		for _, c := range expected.Spec.Template.Spec.Containers {
+			if slice.ContainsString(sidecarNames, c.Name) {
+				containers = append(containers, c)
+			}
+		}
+		for _, c := range updated.Spec.Template.Spec.Containers {
+			if !slice.ContainsString(sidecarNames, c.Name) {
+				containers = append(containers, c)
+			}
+		}
+		updated.Spec.Template.Spec.Containers = containers
+	}
+	updated.Spec.Template.Annotations[configHashAnnotation] = expected.Spec.Template.Annotations[configHashAnnotation]
+	if hash, ok := expected.Spec.Template.Annotations[credentialsHashAnnotation]; ok {
+		updated.Spec.Template.Annotations[credentialsHashAnnotation] = hash
+	} else {
+		delete(updated.Spec.Template.Annotations, credentialsHashAnnotation)
+	}
 	return true, updated
 }
+
+// podFSGroup returns the fsGroup of the security context of spec, if any.
+func podFSGroup(spec *corev1.PodSpec) *int64 {
+	if spec.SecurityContext == nil {
+		return nil
+	}
+	return spec.SecurityContext.FSGroup
+}
+
+// credentialsFilesMountPath returns the effective mount path of the
+// provider credentials files.
+func credentialsFilesMountPath(files *operatorv1.ProviderCredentialsFiles) string {
+	if len(files.MountPath) == 0 {
+		return defaultCredentialsFilesMountPath
+	}
+	return files.MountPath
+}
+
+// cacheVolume returns the emptyDir volume and the mount of the given cache
+// volume.
+func cacheVolume(cache *operatorv1.OperandCacheVolume) (corev1.Volume, corev1.VolumeMount) {
+	volume := corev1.Volume{
+		Name: cacheVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: cache.Medium, SizeLimit: cache.SizeLimit},
+		},
+	}
+	mountPath := cache.MountPath
+	if len(mountPath) == 0 {
+		mountPath = defaultCacheVolumeMountPath
+	}
+	return volume, corev1.VolumeMount{Name: cacheVolumeName, MountPath: mountPath}
+}
+
+// operandLeaseEnabled reports whether more than one operand pod of edns may
+// run, so that its pods must hold the lease of the txt owner id to write
+// records.
+func operandLeaseEnabled(edns *operatorv1.ExternalDNS) bool {
+	if edns.Spec.RunMode == operatorv1.OnceRunMode {
+		return false
+	}
+	return edns.Spec.Autoscaling != nil || (edns.Spec.Replicas != nil && *edns.Spec.Replicas > 1)
+}
+
+// operandLeaseStandbyArgs returns the args of the ExternalDNS controller
+// standing by for the lease. It serves the probes on the metrics address of
+// the operand, doesn't call the provider, and syncs the services of the
+// operand namespace, which has none, rarely and in dry run mode.
+func operandLeaseStandbyArgs(namespace, metricsAddress string) []string {
+	return []string{
+		"--provider=inmemory",
+		"--registry=noop",
+		"--source=service",
+		"--namespace=" + namespace,
+		"--interval=" + operandLeaseStandbyInterval,
+		"--metrics-address=" + metricsAddress,
+		"--dry-run",
+	}
+}
+
+// operandLeaseVolume returns the downward API volume exposing the pod
+// labels to the lease wrapper, and its mount.
+func operandLeaseVolume() (corev1.Volume, corev1.VolumeMount) {
+	// The mode and API version are explicit so that the operator does not
+	// detect drift against API server defaulting.
+	mode := corev1.DownwardAPIVolumeSourceDefaultMode
+	volume := corev1.Volume{
+		Name: operandLeaseVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{{
+					Path:     operandLeaseLabelsFile,
+					FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.labels"},
+				}},
+				DefaultMode: &mode,
+			},
+		},
+	}
+	return volume, corev1.VolumeMount{Name: operandLeaseVolumeName, MountPath: operandLeaseMountPath, ReadOnly: true}
+}
+
+// mergeLabels returns current with the labels of expected added, and
+// whether any were added or changed. Labels not in expected are kept, so
+// labels set by others, e.g. on an adopted deployment, are preserved.
+func mergeLabels(current, expected map[string]string) (map[string]string, bool) {
+	merged := map[string]string{}
+	for k, v := range current {
+		merged[k] = v
+	}
+	changed := false
+	for k, v := range expected {
+		if cv, ok := current[k]; !ok || cv != v {
+			merged[k] = v
+			changed = true
+		}
+	}
+	return merged, changed
+}
+
+// hasStaticAWSCredentials returns true if creds contains an AWS access key.
+func hasStaticAWSCredentials(creds *corev1.Secret) bool {
+	return creds != nil && len(creds.Data["aws_access_key_id"]) != 0
+}
+
+// metricsPort returns the port of the given metrics listen address.
+func metricsPort(address string) (int32, error) {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return 0, err
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || p == 0 {
+		return 0, fmt.Errorf("invalid port %q", port)
+	}
+	return int32(p), nil
+}
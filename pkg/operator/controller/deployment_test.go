@@ -0,0 +1,1459 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	"github.com/danehans/external-dns-operator/pkg/util/slice"
+	configv1 "github.com/openshift/api/config/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestZoneFilterSpansZoneTypes(t *testing.T) {
+	dnsConfig := &configv1.DNS{
+		Spec: configv1.DNSSpec{
+			PublicZone:  &configv1.DNSZone{ID: "public"},
+			PrivateZone: &configv1.DNSZone{Tags: map[string]string{"Name": "private"}},
+		},
+	}
+	testCases := []struct {
+		description string
+		zoneFilter  []*configv1.DNSZone
+		expected    bool
+	}{
+		{
+			description: "public zone only",
+			zoneFilter:  []*configv1.DNSZone{{ID: "public"}},
+			expected:    false,
+		},
+		{
+			description: "private zone only",
+			zoneFilter:  []*configv1.DNSZone{{Tags: map[string]string{"Name": "private"}}},
+			expected:    false,
+		},
+		{
+			description: "public and private zones",
+			zoneFilter:  []*configv1.DNSZone{{ID: "public"}, {Tags: map[string]string{"Name": "private"}}},
+			expected:    true,
+		},
+		{
+			description: "unknown zones",
+			zoneFilter:  []*configv1.DNSZone{{ID: "other"}, {ID: "another"}},
+			expected:    false,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			Spec: operatorv1.ExternalDNSSpec{
+				Provider: operatorv1.ProviderSpec{ZoneFilter: tc.zoneFilter},
+			},
+		}
+		if actual := zoneFilterSpansZoneTypes(edns, dnsConfig); actual != tc.expected {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestAWSTagsArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		tags        map[string]string
+		expected    []string
+	}{
+		{
+			description: "no tags",
+			tags:        nil,
+			expected:    []string{},
+		},
+		{
+			description: "multiple tags sorted by key",
+			tags:        map[string]string{"Name": "private", "kubernetes.io/cluster/foo": "owned"},
+			expected:    []string{"--aws-zone-tags=Name=private", "--aws-zone-tags=kubernetes.io/cluster/foo=owned"},
+		},
+	}
+	for _, tc := range testCases {
+		if actual := awsTagsArgs("--aws-zone-tags", tc.tags); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestProviderMetadataArgs(t *testing.T) {
+	testCases := []struct {
+		description     string
+		provider        operatorv1.ProviderType
+		metadata        map[string]string
+		expectedArgs    []string
+		expectedUnknown []string
+	}{
+		{
+			description:     "no metadata",
+			provider:        operatorv1.AWSProvider,
+			expectedArgs:    []string{},
+			expectedUnknown: []string{},
+		},
+		{
+			description:     "aws service tags",
+			provider:        operatorv1.AWSProvider,
+			metadata:        map[string]string{"serviceTag/team": "dns", "serviceTag/owner": "ops"},
+			expectedArgs:    []string{"--aws-sd-create-tag=owner=ops", "--aws-sd-create-tag=team=dns"},
+			expectedUnknown: []string{},
+		},
+		{
+			description:     "unknown keys",
+			provider:        operatorv1.AWSProvider,
+			metadata:        map[string]string{"serviceTag/": "empty", "comment": "foo"},
+			expectedArgs:    []string{},
+			expectedUnknown: []string{"comment", "serviceTag/"},
+		},
+		{
+			description:     "provider without known keys",
+			provider:        operatorv1.GoogleProvider,
+			metadata:        map[string]string{"serviceTag/team": "dns"},
+			expectedArgs:    []string{},
+			expectedUnknown: []string{"serviceTag/team"},
+		},
+	}
+	for _, tc := range testCases {
+		args, unknown := providerMetadataArgs(tc.provider, tc.metadata)
+		if !reflect.DeepEqual(args, tc.expectedArgs) {
+			t.Errorf("%q: expected args %v, got %v", tc.description, tc.expectedArgs, args)
+		}
+		if !reflect.DeepEqual(unknown, tc.expectedUnknown) {
+			t.Errorf("%q: expected unknown keys %v, got %v", tc.description, tc.expectedUnknown, unknown)
+		}
+	}
+}
+
+func TestOperandConfigHash(t *testing.T) {
+	container := &corev1.Container{
+		Args: []string{"--provider=aws"},
+		Env:  []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+	}
+	hash := operandConfigHash(container)
+	if len(hash) == 0 {
+		t.Fatalf("expected a non-empty hash")
+	}
+	if actual := operandConfigHash(container.DeepCopy()); actual != hash {
+		t.Errorf("expected equal configs to hash to %q, got %q", hash, actual)
+	}
+	changed := container.DeepCopy()
+	changed.Env[0].Value = "baz"
+	if actual := operandConfigHash(changed); actual == hash {
+		t.Errorf("expected a changed env to change hash %q", hash)
+	}
+}
+
+func TestValidateAdoptableDeployment(t *testing.T) {
+	newDeployment := func(containers ...string) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{}
+		for _, name := range containers {
+			deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, corev1.Container{Name: name})
+		}
+		return deployment
+	}
+	testCases := []struct {
+		description string
+		deployment  *appsv1.Deployment
+		valid       bool
+	}{
+		{
+			description: "externaldns container",
+			deployment:  newDeployment("sidecar", "externaldns"),
+			valid:       true,
+		},
+		{
+			description: "unrelated container",
+			deployment:  newDeployment("nginx"),
+			valid:       false,
+		},
+		{
+			description: "no containers",
+			deployment:  newDeployment(),
+			valid:       false,
+		},
+	}
+	for _, tc := range testCases {
+		if err := validateAdoptableDeployment(tc.deployment, defaultOperandContainerName); (err == nil) != tc.valid {
+			t.Errorf("%q: expected valid %t, got error %v", tc.description, tc.valid, err)
+		}
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	testCases := []struct {
+		description     string
+		current         map[string]string
+		expected        map[string]string
+		merged          map[string]string
+		expectedChanged bool
+	}{
+		{
+			description: "unchanged",
+			current:     map[string]string{"a": "1", "other": "x"},
+			expected:    map[string]string{"a": "1"},
+			merged:      map[string]string{"a": "1", "other": "x"},
+		},
+		{
+			description:     "added and changed",
+			current:         map[string]string{"a": "1", "other": "x"},
+			expected:        map[string]string{"a": "2", "team": "dns"},
+			merged:          map[string]string{"a": "2", "team": "dns", "other": "x"},
+			expectedChanged: true,
+		},
+	}
+	for _, tc := range testCases {
+		merged, changed := mergeLabels(tc.current, tc.expected)
+		if changed != tc.expectedChanged || !reflect.DeepEqual(merged, tc.merged) {
+			t.Errorf("%q: expected %v (changed %t), got %v (changed %t)", tc.description, tc.merged, tc.expectedChanged, merged, changed)
+		}
+	}
+}
+
+func TestDeploymentConfigChangedRestoresOperandContainer(t *testing.T) {
+	expected := &appsv1.Deployment{}
+	expected.Spec.Template.Spec.Containers = []corev1.Container{{Name: defaultOperandContainerName, Image: "externaldns:v1"}}
+	current := &appsv1.Deployment{}
+	current.Spec.Template.Spec.Containers = []corev1.Container{{Name: "sidecar"}}
+
+	changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+	if !changed {
+		t.Fatal("expected a deployment without the operand container to change")
+	}
+	if c := operandContainer(&updated.Spec.Template.Spec, defaultOperandContainerName); c == nil || c.Image != "externaldns:v1" {
+		t.Errorf("expected the operand container to be restored, got %v", updated.Spec.Template.Spec.Containers)
+	}
+	if len(updated.Spec.Template.Spec.Containers) != 2 {
+		t.Errorf("expected the sidecar to be kept, got %v", updated.Spec.Template.Spec.Containers)
+	}
+	if changed, _ := deploymentConfigChanged(updated, expected, defaultOperandContainerName); changed {
+		t.Error("expected the restored deployment to be unchanged")
+	}
+}
+
+func TestDurationArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		duration    *metav1.Duration
+		expected    []string
+	}{
+		{
+			description: "unset keeps the default",
+			expected:    nil,
+		},
+		{
+			description: "zero disables the cache",
+			duration:    &metav1.Duration{},
+			expected:    []string{"--aws-zones-cache-duration=0s"},
+		},
+		{
+			description: "positive duration",
+			duration:    &metav1.Duration{Duration: 3 * time.Hour},
+			expected:    []string{"--aws-zones-cache-duration=3h0m0s"},
+		},
+	}
+	for _, tc := range testCases {
+		if actual := durationArgs("--aws-zones-cache-duration", tc.duration); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestDeploymentConfigChangedWebhookSidecar(t *testing.T) {
+	operand := corev1.Container{Name: defaultOperandContainerName}
+	sidecar := corev1.Container{Name: webhookSidecarName, Image: "webhook:v1"}
+	withSidecar := &appsv1.Deployment{}
+	withSidecar.Spec.Template.Spec.Containers = []corev1.Container{sidecar, operand}
+	withoutSidecar := &appsv1.Deployment{}
+	withoutSidecar.Spec.Template.Spec.Containers = []corev1.Container{operand}
+
+	changed, updated := deploymentConfigChanged(withoutSidecar, withSidecar, defaultOperandContainerName)
+	if !changed || !reflect.DeepEqual(updated.Spec.Template.Spec.Containers, withSidecar.Spec.Template.Spec.Containers) {
+		t.Errorf("expected the sidecar to be added before the operand, got %v", updated)
+	}
+	changed, updated = deploymentConfigChanged(withSidecar, withoutSidecar, defaultOperandContainerName)
+	if !changed || !reflect.DeepEqual(updated.Spec.Template.Spec.Containers, withoutSidecar.Spec.Template.Spec.Containers) {
+		t.Errorf("expected the sidecar to be removed, got %v", updated)
+	}
+	if changed, _ := deploymentConfigChanged(withSidecar, withSidecar, defaultOperandContainerName); changed {
+		t.Error("expected an unchanged sidecar not to update the deployment")
+	}
+}
+
+func TestDesiredExternalDNSDeploymentNodeSourceDefaultTargets(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	node := operatorv1.NodeType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "bare-metal"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:        []*operatorv1.SourceType{&node},
+			DefaultTargets: []string{"192.0.2.10"},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	args := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args
+	for _, expected := range []string{"--source=node", "--default-targets=192.0.2.10"} {
+		if !slice.ContainsString(args, expected) {
+			t.Errorf("expected arg %q in %v", expected, args)
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentFlagValidation(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName, ValidateOperandFlags: true}}
+	node := operatorv1.NodeType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "validated"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:        []*operatorv1.SourceType{&node},
+			InitContainers: []corev1.Container{{Name: "init", Image: "init:latest"}},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	initContainers := deployment.Spec.Template.Spec.InitContainers
+	if len(initContainers) != 2 || initContainers[0].Name != flagValidationContainerName {
+		t.Fatalf("expected the %s init container before the user init containers, got %v", flagValidationContainerName, initContainers)
+	}
+	validation := initContainers[0]
+	if validation.Image != "externaldns:latest" {
+		t.Errorf("expected the operand image, got %q", validation.Image)
+	}
+	if !reflect.DeepEqual(validation.Args, operandFlagNames(operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args)) {
+		t.Errorf("expected the operand flag names, got %v", validation.Args)
+	}
+	for _, expected := range []string{"source", "provider"} {
+		if !slice.ContainsString(validation.Args, expected) {
+			t.Errorf("expected flag %q in %v", expected, validation.Args)
+		}
+	}
+
+	r.ValidateOperandFlags = false
+	deployment = r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if len(deployment.Spec.Template.Spec.InitContainers) != 1 {
+		t.Errorf("expected no flag validation init container, got %v", deployment.Spec.Template.Spec.InitContainers)
+	}
+}
+
+func TestOperandFlagNames(t *testing.T) {
+	args := []string{"--source=service", "--source=ingress", "--aws-prefer-cname", "positional", "--=x", "--provider=aws"}
+	expected := []string{"aws-prefer-cname", "provider", "source"}
+	if names := operandFlagNames(args); !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentCredentialsFiles(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	fsGroup := int64(1000)
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "files"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources: []*operatorv1.SourceType{&service},
+			FSGroup: &fsGroup,
+			Provider: operatorv1.ProviderSpec{
+				CredentialsFiles: &operatorv1.ProviderCredentialsFiles{SecretName: "gcp-credentials"},
+			},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	expected := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	podSpec := expected.Spec.Template.Spec
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Secret == nil || podSpec.Volumes[0].Secret.SecretName != "gcp-credentials" ||
+		*podSpec.Volumes[0].Secret.DefaultMode != credentialsFilesMode {
+		t.Fatalf("expected a group-readable gcp-credentials secret volume, got %v", podSpec.Volumes)
+	}
+	mounts := operandContainer(&podSpec, defaultOperandContainerName).VolumeMounts
+	if len(mounts) != 1 || mounts[0].MountPath != defaultCredentialsFilesMountPath || !mounts[0].ReadOnly {
+		t.Errorf("expected a read-only mount at %s, got %v", defaultCredentialsFilesMountPath, mounts)
+	}
+	if fsGroup := podFSGroup(&podSpec); fsGroup == nil || *fsGroup != 1000 {
+		t.Errorf("expected fsGroup 1000, got %v", fsGroup)
+	}
+
+	// Settings of the security context other than the fsGroup are kept.
+	runAsNonRoot := true
+	current := expected.DeepCopy()
+	current.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot}
+	changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+	if !changed {
+		t.Fatal("expected the fsGroup drift to be detected")
+	}
+	if sc := updated.Spec.Template.Spec.SecurityContext; sc.RunAsNonRoot == nil || sc.FSGroup == nil || *sc.FSGroup != 1000 {
+		t.Errorf("expected fsGroup 1000 with runAsNonRoot kept, got %v", sc)
+	}
+	if changed, _ := deploymentConfigChanged(updated, expected, defaultOperandContainerName); changed {
+		t.Error("expected no change after the update")
+	}
+}
+
+func TestManagedFlagNames(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	private := operatorv1.PrivateZoneType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "managed"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:          []*operatorv1.SourceType{&service},
+			ZoneType:         &private,
+			ExperimentalArgs: []string{"--txt-cache-interval=1h"},
+			Interval:         &metav1.Duration{Duration: time.Minute},
+			Events:           &operatorv1.EventsSpec{},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider, BaseDomain: "example.com"},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	args := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args
+	if !slice.ContainsString(args, "--txt-cache-interval=1h") {
+		t.Errorf("expected experimental arg --txt-cache-interval=1h in %v", args)
+	}
+	for _, name := range operandFlagNames(args) {
+		if name != "txt-cache-interval" && !slice.ContainsString(managedFlagNames, strings.TrimPrefix(name, "no-")) {
+			t.Errorf("flag --%s set by the operator is missing from managedFlagNames", name)
+		}
+	}
+}
+
+func TestDeploymentConfigChangedRevisionHistoryLimit(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "history"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	current := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if l := current.Spec.RevisionHistoryLimit; l == nil || *l != defaultRevisionHistoryLimit {
+		t.Fatalf("expected the default revision history limit %d, got %v", defaultRevisionHistoryLimit, l)
+	}
+
+	limit := int32(0)
+	edns.Spec.RevisionHistoryLimit = &limit
+	expected := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+	if !changed || *updated.Spec.RevisionHistoryLimit != 0 {
+		t.Errorf("expected the revision history limit to be updated to 0, got %t %v", changed, updated)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentMultipleSources(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	ingress := operatorv1.IngressType
+	route := operatorv1.RouteType
+	provider := operatorv1.InMemoryProvider
+	testCases := []struct {
+		description string
+		sources     []*operatorv1.SourceType
+		expected    []string
+	}{
+		{
+			description: "single source",
+			sources:     []*operatorv1.SourceType{&service},
+			expected:    []string{"--source=service"},
+		},
+		{
+			description: "two sources",
+			sources:     []*operatorv1.SourceType{&service, &ingress},
+			expected:    []string{"--source=service", "--source=ingress"},
+		},
+		{
+			description: "three sources",
+			sources:     []*operatorv1.SourceType{&service, &ingress, &route},
+			expected:    []string{"--source=service", "--source=ingress", "--source=openshift-route"},
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "sources"},
+			Spec:       operatorv1.ExternalDNSSpec{Sources: tc.sources},
+			Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		var sources []string
+		for _, arg := range operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args {
+			if strings.HasPrefix(arg, "--source=") {
+				sources = append(sources, arg)
+			}
+		}
+		if !reflect.DeepEqual(sources, tc.expected) {
+			t.Errorf("%q: expected source args %v, got %v", tc.description, tc.expected, sources)
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentEvents(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	testCases := []struct {
+		description string
+		spec        operatorv1.ExternalDNSSpec
+		expected    []string
+	}{
+		{
+			description: "events disabled",
+			spec:        operatorv1.ExternalDNSSpec{Interval: &metav1.Duration{Duration: time.Minute}},
+			expected:    []string{"--interval=1m0s"},
+		},
+		{
+			description: "events with the default interval",
+			spec:        operatorv1.ExternalDNSSpec{Events: &operatorv1.EventsSpec{}},
+			expected:    []string{"--events"},
+		},
+		{
+			description: "events with a full resync interval",
+			spec:        operatorv1.ExternalDNSSpec{Events: &operatorv1.EventsSpec{FullResyncInterval: &metav1.Duration{Duration: 15 * time.Minute}}},
+			expected:    []string{"--events", "--interval=15m0s"},
+		},
+	}
+	for _, tc := range testCases {
+		tc.spec.Sources = []*operatorv1.SourceType{&service}
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "events"},
+			Spec:       tc.spec,
+			Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		var actual []string
+		for _, arg := range operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args {
+			if arg == "--events" || strings.HasPrefix(arg, "--interval=") {
+				actual = append(actual, arg)
+			}
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+// updateRecorder is a client recording the objects it updates. Calls to
+// other methods panic.
+type updateRecorder struct {
+	kclient.Client
+	updated []runtime.Object
+}
+
+func (c *updateRecorder) Update(ctx context.Context, obj runtime.Object, opts ...kclient.UpdateOptionFunc) error {
+	c.updated = append(c.updated, obj)
+	return nil
+}
+
+// createRecorder is a client for which no object exists, recording the
+// keys it gets and the objects it creates. Calls to other methods panic.
+type createRecorder struct {
+	kclient.Client
+	gets    []kclient.ObjectKey
+	created []runtime.Object
+}
+
+func (c *createRecorder) Get(ctx context.Context, key kclient.ObjectKey, obj runtime.Object) error {
+	c.gets = append(c.gets, key)
+	return errors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (c *createRecorder) Create(ctx context.Context, obj runtime.Object, opts ...kclient.CreateOptionFunc) error {
+	c.created = append(c.created, obj)
+	return nil
+}
+
+func TestEnsureExternalDNSDeploymentOperandNamespace(t *testing.T) {
+	recorder := &createRecorder{}
+	r := &reconciler{
+		Config:  Config{OperandNamespace: "custom-externaldns", OperandContainerName: defaultOperandContainerName},
+		kclient: recorder,
+	}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	if err := r.ensureExternalDNSDeployment(edns, &configv1.DNS{}, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := kclient.ObjectKey{Namespace: "custom-externaldns", Name: "externaldns-custom"}
+	if len(recorder.gets) != 1 || recorder.gets[0] != expected {
+		t.Errorf("expected the deployment to be looked up as %v, got %v", expected, recorder.gets)
+	}
+	if len(recorder.created) != 1 {
+		t.Fatalf("expected the deployment to be created once, got %d creates", len(recorder.created))
+	}
+	if created := recorder.created[0].(*appsv1.Deployment); created.Namespace != expected.Namespace || created.Name != expected.Name {
+		t.Errorf("expected the deployment to be created as %v, got %s/%s", expected, created.Namespace, created.Name)
+	}
+}
+
+func TestUpdateExternalDNSDeploymentReplicas(t *testing.T) {
+	recorder := &updateRecorder{}
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}, kclient: recorder}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "scaled"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	current := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if replicas := current.Spec.Replicas; replicas == nil || *replicas != 1 {
+		t.Fatalf("expected 1 replica by default, got %v", replicas)
+	}
+
+	replicas := int32(2)
+	edns.Spec.Replicas = &replicas
+	desired := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if err := r.updateExternalDNSDeployment(current, desired); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.updated) != 1 {
+		t.Fatalf("expected the deployment to be updated once, got %d updates", len(recorder.updated))
+	}
+	current = recorder.updated[0].(*appsv1.Deployment)
+	if *current.Spec.Replicas != 2 {
+		t.Errorf("expected the deployment to be scaled to 2 replicas, got %d", *current.Spec.Replicas)
+	}
+
+	// An autoscaled deployment's replicas are left to its HPA.
+	recorder.updated = nil
+	edns.Spec.Replicas = nil
+	edns.Spec.Autoscaling = &operatorv1.AutoscalingSpec{MaxReplicas: 3}
+	desired = r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if err := r.updateExternalDNSDeployment(current, desired); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.updated) != 0 {
+		t.Errorf("expected no update of an autoscaled deployment, got %v", recorder.updated)
+	}
+}
+
+func TestDeploymentConfigChanged(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "drift"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	expected := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	replicas := int32(3)
+	testCases := []struct {
+		description string
+		mutate      func(*appsv1.Deployment)
+		field       func(*appsv1.Deployment) interface{}
+	}{
+		{
+			description: "image pull policy",
+			mutate: func(d *appsv1.Deployment) {
+				operandContainer(&d.Spec.Template.Spec, defaultOperandContainerName).ImagePullPolicy = corev1.PullAlways
+			},
+			field: func(d *appsv1.Deployment) interface{} {
+				return operandContainer(&d.Spec.Template.Spec, defaultOperandContainerName).ImagePullPolicy
+			},
+		},
+		{
+			description: "env",
+			mutate: func(d *appsv1.Deployment) {
+				c := operandContainer(&d.Spec.Template.Spec, defaultOperandContainerName)
+				c.Env = append(c.Env, corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", Value: "stale"})
+			},
+			field: func(d *appsv1.Deployment) interface{} {
+				return operandContainer(&d.Spec.Template.Spec, defaultOperandContainerName).Env
+			},
+		},
+		{
+			description: "volume mounts",
+			mutate: func(d *appsv1.Deployment) {
+				c := operandContainer(&d.Spec.Template.Spec, defaultOperandContainerName)
+				c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: "stale", MountPath: "/stale"})
+			},
+			field: func(d *appsv1.Deployment) interface{} {
+				return operandContainer(&d.Spec.Template.Spec, defaultOperandContainerName).VolumeMounts
+			},
+		},
+		{
+			description: "volumes",
+			mutate: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, corev1.Volume{Name: "stale"})
+			},
+			field: func(d *appsv1.Deployment) interface{} {
+				return d.Spec.Template.Spec.Volumes
+			},
+		},
+		{
+			description: "replicas",
+			mutate: func(d *appsv1.Deployment) {
+				d.Spec.Replicas = &replicas
+			},
+			field: func(d *appsv1.Deployment) interface{} {
+				return *d.Spec.Replicas
+			},
+		},
+	}
+	for _, tc := range testCases {
+		current := expected.DeepCopy()
+		tc.mutate(current)
+		changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+		if !changed {
+			t.Errorf("%q: expected the deployment to change", tc.description)
+			continue
+		}
+		if actual, want := tc.field(updated), tc.field(expected); !reflect.DeepEqual(actual, want) {
+			t.Errorf("%q: expected %v to be copied, got %v", tc.description, want, actual)
+		}
+	}
+}
+
+func TestDeploymentConfigChangedRecreateStrategy(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "single-writer"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	expected := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if expected.Spec.Strategy.Type != appsv1.RecreateDeploymentStrategyType {
+		t.Fatalf("expected the %s strategy, got %q", appsv1.RecreateDeploymentStrategyType, expected.Spec.Strategy.Type)
+	}
+
+	// A deployment created before the strategy was managed has the
+	// defaulted rolling update strategy.
+	current := expected.DeepCopy()
+	maxSurge := intstr.FromString("25%")
+	current.Spec.Strategy = appsv1.DeploymentStrategy{
+		Type:          appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge},
+	}
+	changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+	if !changed {
+		t.Fatal("expected a rolling update deployment to change")
+	}
+	if !reflect.DeepEqual(updated.Spec.Strategy, expected.Spec.Strategy) {
+		t.Errorf("expected the strategy %v, got %v", expected.Spec.Strategy, updated.Spec.Strategy)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentResources(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "sized"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	current := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if requests := operandContainer(&current.Spec.Template.Spec, defaultOperandContainerName).Resources.Requests; len(requests) == 0 {
+		t.Fatal("expected the manifest resource requests by default")
+	}
+
+	edns.Spec.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+	}
+	expected := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	resources := operandContainer(&expected.Spec.Template.Spec, defaultOperandContainerName).Resources
+	if !resourceRequirementsEqual(resources, edns.Spec.Resources) {
+		t.Errorf("expected resources %v, got %v", edns.Spec.Resources, resources)
+	}
+	changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+	if !changed {
+		t.Fatal("expected changed resources to update the deployment")
+	}
+	if resources := operandContainer(&updated.Spec.Template.Spec, defaultOperandContainerName).Resources; !resourceRequirementsEqual(resources, edns.Spec.Resources) {
+		t.Errorf("expected the updated deployment to have resources %v, got %v", edns.Spec.Resources, resources)
+	}
+
+	// Equal quantities formatted differently aren't a change.
+	edns.Spec.Resources.Limits[corev1.ResourceCPU] = resource.MustParse("1000m")
+	reformatted := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if changed, _ := deploymentConfigChanged(updated, reformatted, defaultOperandContainerName); changed {
+		t.Error("expected equal resource quantities not to update the deployment")
+	}
+}
+
+func TestDesiredExternalDNSDeploymentServiceAccountToken(t *testing.T) {
+	testCases := []struct {
+		description string
+		provider    operatorv1.ProviderType
+		token       *operatorv1.ProviderServiceAccountToken
+		expectedEnv []corev1.EnvVar
+		expectMount string
+	}{
+		{
+			description: "aws web identity",
+			provider:    operatorv1.AWSProvider,
+			token:       &operatorv1.ProviderServiceAccountToken{Audience: "sts.amazonaws.com"},
+			expectedEnv: []corev1.EnvVar{
+				{Name: awsWebIdentityTokenFileEnvVar, Value: "/var/run/secrets/externaldns/serviceaccount/token"},
+				{Name: awsRoleARNEnvVar, Value: "arn:aws:iam::123456789012:role/externaldns"},
+			},
+			expectMount: defaultServiceAccountTokenMountPath,
+		},
+		{
+			description: "azure workload identity at a custom path",
+			provider:    operatorv1.AzureProvider,
+			token:       &operatorv1.ProviderServiceAccountToken{Audience: "api://AzureADTokenExchange", MountPath: "/var/run/secrets/azure/tokens"},
+			expectedEnv: []corev1.EnvVar{{Name: azureFederatedTokenFileEnvVar, Value: "/var/run/secrets/azure/tokens/token"}},
+			expectMount: "/var/run/secrets/azure/tokens",
+		},
+		{
+			description: "gcp workload identity federation",
+			provider:    operatorv1.GoogleProvider,
+			token:       &operatorv1.ProviderServiceAccountToken{Audience: "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/p/providers/k8s"},
+			expectMount: defaultServiceAccountTokenMountPath,
+		},
+	}
+	for _, tc := range testCases {
+		r := &reconciler{Config: Config{
+			OperandContainerName: defaultOperandContainerName,
+			Credentials:          &corev1.Secret{},
+			RoleARN:              "arn:aws:iam::123456789012:role/externaldns",
+		}}
+		service := operatorv1.ServiceType
+		provider := tc.provider
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "keyless"},
+			Spec: operatorv1.ExternalDNSSpec{
+				Sources:  []*operatorv1.SourceType{&service},
+				Provider: operatorv1.ProviderSpec{ServiceAccountToken: tc.token},
+			},
+			Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		podSpec := deployment.Spec.Template.Spec
+		container := operandContainer(&podSpec, defaultOperandContainerName)
+
+		var volume *corev1.Volume
+		for i := range podSpec.Volumes {
+			if podSpec.Volumes[i].Name == serviceAccountTokenVolumeName {
+				volume = &podSpec.Volumes[i]
+			}
+		}
+		if volume == nil || volume.Projected == nil || len(volume.Projected.Sources) != 1 ||
+			volume.Projected.Sources[0].ServiceAccountToken == nil ||
+			volume.Projected.Sources[0].ServiceAccountToken.Audience != tc.token.Audience {
+			t.Errorf("%q: expected a projected token volume for audience %q, got %v", tc.description, tc.token.Audience, volume)
+		}
+		mounted := false
+		for _, mount := range container.VolumeMounts {
+			mounted = mounted || (mount.Name == serviceAccountTokenVolumeName && mount.MountPath == tc.expectMount)
+		}
+		if !mounted {
+			t.Errorf("%q: expected the token to be mounted at %s, got %v", tc.description, tc.expectMount, container.VolumeMounts)
+		}
+		for _, expected := range tc.expectedEnv {
+			found := false
+			for _, env := range container.Env {
+				found = found || env == expected
+			}
+			if !found {
+				t.Errorf("%q: expected env %v in %v", tc.description, expected, container.Env)
+			}
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentPolicy(t *testing.T) {
+	sync, upsertOnly := operatorv1.SyncPolicyType, operatorv1.UpsertOnlyPolicyType
+	testCases := []struct {
+		description string
+		policy      *operatorv1.PolicyType
+		expected    string
+	}{
+		{"unset", nil, ""},
+		{"sync", &sync, "--policy=sync"},
+		{"upsert-only", &upsertOnly, "--policy=upsert-only"},
+	}
+	for _, tc := range testCases {
+		r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+		service := operatorv1.ServiceType
+		provider := operatorv1.InMemoryProvider
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}, Policy: tc.policy},
+			Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		policyArgs := []string{}
+		for _, arg := range operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args {
+			if strings.HasPrefix(arg, "--policy") {
+				policyArgs = append(policyArgs, arg)
+			}
+		}
+		expected := []string{}
+		if len(tc.expected) != 0 {
+			expected = append(expected, tc.expected)
+		}
+		if !reflect.DeepEqual(policyArgs, expected) {
+			t.Errorf("%q: expected policy args %v, got %v", tc.description, expected, policyArgs)
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentIngressClassFilter(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	ingress, service := operatorv1.IngressType, operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "public"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:            []*operatorv1.SourceType{&ingress},
+			IngressClassFilter: []string{"public", "internal"},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	ingressClassArgs := func() []string {
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		args := []string{}
+		for _, arg := range operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args {
+			if strings.HasPrefix(arg, "--ingress-class=") {
+				args = append(args, arg)
+			}
+		}
+		return args
+	}
+	if args, expected := ingressClassArgs(), []string{"--ingress-class=public", "--ingress-class=internal"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected ingress class args %v, got %v", expected, args)
+	}
+
+	edns.Spec.Sources = []*operatorv1.SourceType{&service}
+	if args := ingressClassArgs(); len(args) != 0 {
+		t.Errorf("expected no ingress class args without the ingress source, got %v", args)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentDomainFilter(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "public"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider, BaseDomain: " example.com "},
+	}
+	domainFilterArgs := func() []string {
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		args := []string{}
+		for _, arg := range operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args {
+			if strings.HasPrefix(arg, "--domain-filter") {
+				args = append(args, arg)
+			}
+		}
+		return args
+	}
+	if args, expected := domainFilterArgs(), []string{"--domain-filter=example.com"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected domain filter args %v, got %v", expected, args)
+	}
+
+	edns.Spec.RegexDomainFilter = `.*\.example\.com$`
+	if args := domainFilterArgs(); len(args) != 0 {
+		t.Errorf("expected no domain filter args with a regex domain filter, got %v", args)
+	}
+
+	edns.Spec.RegexDomainFilter = ""
+	edns.Status.BaseDomain = ""
+	if args := domainFilterArgs(); len(args) != 0 {
+		t.Errorf("expected no domain filter args without a base domain, got %v", args)
+	}
+}
+
+func TestDeploymentConfigChangedDomainFilter(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "public"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider, BaseDomain: "example.com"},
+	}
+	current := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	edns.Status.BaseDomain = "example.org"
+	expected := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+	if !changed {
+		t.Fatal("expected a base domain change to change the deployment")
+	}
+	args := operandContainer(&updated.Spec.Template.Spec, defaultOperandContainerName).Args
+	if !slice.ContainsString(args, "--domain-filter=example.org") {
+		t.Errorf("expected updated args to contain --domain-filter=example.org, got %v", args)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentTXTRegistryOptions(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "public"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	infraConfig := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{InfrastructureName: "cluster-abc"}}
+	txtArgs := func() []string {
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, infraConfig)
+		args := []string{}
+		for _, arg := range operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args {
+			if strings.HasPrefix(arg, "--txt-") {
+				args = append(args, arg)
+			}
+		}
+		return args
+	}
+	if args, expected := txtArgs(), []string{"--txt-owner-id=cluster-abc/aws/ns/public"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected txt args %v, got %v", expected, args)
+	}
+
+	edns.Spec.TXTPrefix = "dns-"
+	edns.Spec.TXTOwnerID = "legacy-owner"
+	if args, expected := txtArgs(), []string{"--txt-owner-id=legacy-owner", "--txt-prefix=dns-"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected txt args %v, got %v", expected, args)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentAWSZoneMatchParent(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "delegated"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources: []*operatorv1.SourceType{&service},
+			Provider: operatorv1.ProviderSpec{
+				ZoneFilter:         []*configv1.DNSZone{{ID: "Z2CHILD"}},
+				AWSZoneMatchParent: true,
+			},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider, BaseDomain: "apps.example.com"},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	args := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args
+	for _, expected := range []string{"--aws-zone-match-parent", "--domain-filter=apps.example.com", "--zone-id-filter=Z2CHILD"} {
+		if !slice.ContainsString(args, expected) {
+			t.Errorf("expected arg %s in %v", expected, args)
+		}
+	}
+
+	edns.Spec.Provider.AWSZoneMatchParent = false
+	deployment = r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	if args := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args; slice.ContainsString(args, "--aws-zone-match-parent") {
+		t.Errorf("expected no --aws-zone-match-parent arg in %v", args)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentTextOwnerMigration(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	testCases := []struct {
+		description string
+		spec        string
+		status      operatorv1.ExternalDNSStatus
+		expected    []string
+	}{
+		{
+			description: "no migration",
+			spec:        "new",
+			status:      operatorv1.ExternalDNSStatus{TextOwnerID: "new"},
+			expected:    []string{"--txt-owner-id=new"},
+		},
+		{
+			description: "migration ongoing",
+			spec:        "new",
+			status:      operatorv1.ExternalDNSStatus{TextOwnerID: "new", PreviousTextOwnerID: "old"},
+			expected:    []string{"--txt-owner-id=new", "--migrate-from-txt-owner=old"},
+		},
+		{
+			description: "owner id change deferred by an ongoing migration",
+			spec:        "newer",
+			status:      operatorv1.ExternalDNSStatus{TextOwnerID: "new", PreviousTextOwnerID: "old"},
+			expected:    []string{"--txt-owner-id=new", "--migrate-from-txt-owner=old"},
+		},
+		{
+			description: "owner id not yet published",
+			spec:        "new",
+			expected:    []string{"--txt-owner-id=new"},
+		},
+	}
+	for _, tc := range testCases {
+		status := tc.status
+		status.ProviderType = &provider
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrating"},
+			Spec: operatorv1.ExternalDNSSpec{
+				Sources:    []*operatorv1.SourceType{&service},
+				TXTOwnerID: tc.spec,
+			},
+			Status: status,
+		}
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		var actual []string
+		for _, arg := range operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args {
+			if strings.HasPrefix(arg, "--txt-owner-id=") || strings.HasPrefix(arg, "--migrate-from-txt-owner=") {
+				actual = append(actual, arg)
+			}
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentStartupFailureThreshold(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "slow-start"},
+		Spec:       operatorv1.ExternalDNSSpec{Sources: []*operatorv1.SourceType{&service}},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	current := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	defaultLiveness := operandContainer(&current.Spec.Template.Spec, defaultOperandContainerName).LivenessProbe
+	defaultReadiness := operandContainer(&current.Spec.Template.Spec, defaultOperandContainerName).ReadinessProbe
+
+	threshold := int32(30)
+	edns.Spec.StartupFailureThreshold = &threshold
+	expected := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	container := operandContainer(&expected.Spec.Template.Spec, defaultOperandContainerName)
+	if delay := threshold * defaultLiveness.PeriodSeconds; container.LivenessProbe.InitialDelaySeconds != delay {
+		t.Errorf("expected a liveness delay of %d periods of %ds, got %ds", threshold, defaultLiveness.PeriodSeconds, container.LivenessProbe.InitialDelaySeconds)
+	}
+	if container.LivenessProbe.PeriodSeconds != defaultLiveness.PeriodSeconds || container.LivenessProbe.FailureThreshold != defaultLiveness.FailureThreshold {
+		t.Errorf("expected only the liveness delay to change, got %v", container.LivenessProbe)
+	}
+	if !reflect.DeepEqual(container.ReadinessProbe, defaultReadiness) {
+		t.Errorf("expected the readiness probe to be unchanged, got %v", container.ReadinessProbe)
+	}
+
+	changed, updated := deploymentConfigChanged(current, expected, defaultOperandContainerName)
+	if !changed {
+		t.Fatal("expected a changed startupFailureThreshold to be detected")
+	}
+	if delay := operandContainer(&updated.Spec.Template.Spec, defaultOperandContainerName).LivenessProbe.InitialDelaySeconds; delay != threshold*defaultLiveness.PeriodSeconds {
+		t.Errorf("expected the updated liveness delay to be %ds, got %ds", threshold*defaultLiveness.PeriodSeconds, delay)
+	}
+	if changed, _ := deploymentConfigChanged(updated, expected, defaultOperandContainerName); changed {
+		t.Error("expected no change once the liveness delay is updated")
+	}
+}
+
+func TestDesiredExternalDNSDeploymentMetricsAddress(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	testCases := []struct {
+		address      string
+		expectedArg  string
+		expectedPort int32
+	}{
+		{address: "", expectedArg: "--metrics-address=:7979", expectedPort: 7979},
+		{address: ":8080", expectedArg: "--metrics-address=:8080", expectedPort: 8080},
+		{address: "0.0.0.0:9090", expectedArg: "--metrics-address=0.0.0.0:9090", expectedPort: 9090},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics"},
+			Spec: operatorv1.ExternalDNSSpec{
+				Sources:        []*operatorv1.SourceType{&service},
+				MetricsAddress: tc.address,
+			},
+			Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		container := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName)
+		if !slice.ContainsString(container.Args, tc.expectedArg) {
+			t.Errorf("%q: expected arg %s in %v", tc.address, tc.expectedArg, container.Args)
+		}
+		var port *corev1.ContainerPort
+		for i := range container.Ports {
+			if container.Ports[i].Name == metricsPortName {
+				port = &container.Ports[i]
+			}
+		}
+		if port == nil || port.ContainerPort != tc.expectedPort {
+			t.Errorf("%q: expected metrics container port %d, got %v", tc.address, tc.expectedPort, port)
+		}
+		// The probes follow the container port through its name.
+		for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe} {
+			if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Port.StrVal != metricsPortName {
+				t.Errorf("%q: expected the probes to use the %s port, got %v", tc.address, metricsPortName, probe)
+			}
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentAWSZonesCacheDuration(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	aws := operatorv1.AWSProvider
+	inMemory := operatorv1.InMemoryProvider
+	testCases := []struct {
+		description string
+		provider    operatorv1.ProviderType
+		duration    *metav1.Duration
+		expected    []string
+	}{
+		{"aws unset keeps the default", aws, nil, nil},
+		{"aws cache disabled", aws, &metav1.Duration{}, []string{"--aws-zones-cache-duration=0s"}},
+		{"aws custom", aws, &metav1.Duration{Duration: time.Hour}, []string{"--aws-zones-cache-duration=1h0m0s"}},
+		{"other provider", inMemory, nil, nil},
+	}
+	for _, tc := range testCases {
+		provider := tc.provider
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "zones-cache"},
+			Spec: operatorv1.ExternalDNSSpec{
+				Sources:  []*operatorv1.SourceType{&service},
+				Provider: operatorv1.ProviderSpec{AWSZonesCacheDuration: tc.duration},
+			},
+			Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		var actual []string
+		for _, arg := range operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args {
+			if strings.HasPrefix(arg, "--aws-zones-cache-duration=") {
+				actual = append(actual, arg)
+			}
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentTenantScope(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:     []*operatorv1.SourceType{&service},
+			Namespace:   "team-a",
+			LabelFilter: "team=a",
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	args := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args
+	for _, expected := range []string{"--namespace=team-a", "--label-filter=team=a"} {
+		if !slice.ContainsString(args, expected) {
+			t.Errorf("expected arg %s in %v", expected, args)
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentCacheVolume(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	sizeLimit := resource.MustParse("64Mi")
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "cached"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:     []*operatorv1.SourceType{&service},
+			CacheVolume: &operatorv1.OperandCacheVolume{Medium: corev1.StorageMediumMemory, SizeLimit: &sizeLimit},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	container := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName)
+	expectedMount := corev1.VolumeMount{Name: cacheVolumeName, MountPath: defaultCacheVolumeMountPath}
+	if !reflect.DeepEqual(container.VolumeMounts, []corev1.VolumeMount{expectedMount}) {
+		t.Errorf("expected volume mounts %v, got %v", []corev1.VolumeMount{expectedMount}, container.VolumeMounts)
+	}
+	volumes := deployment.Spec.Template.Spec.Volumes
+	if len(volumes) != 1 || volumes[0].EmptyDir == nil || volumes[0].EmptyDir.Medium != corev1.StorageMediumMemory ||
+		volumes[0].EmptyDir.SizeLimit.Cmp(sizeLimit) != 0 {
+		t.Errorf("expected a 64Mi memory emptyDir volume, got %v", volumes)
+	}
+	expectedEnv := []corev1.EnvVar{
+		{Name: xdgCacheHomeEnvVar, Value: defaultCacheVolumeMountPath},
+		{Name: tmpDirEnvVar, Value: defaultCacheVolumeMountPath},
+	}
+	if !reflect.DeepEqual(container.Env, expectedEnv) {
+		t.Errorf("expected env %v, got %v", expectedEnv, container.Env)
+	}
+
+	// The same size limit formatted differently isn't drift.
+	current := deployment.DeepCopy()
+	equivalent := resource.MustParse("67108864")
+	current.Spec.Template.Spec.Volumes[0].EmptyDir.SizeLimit = &equivalent
+	if changed, _ := deploymentConfigChanged(current, deployment, defaultOperandContainerName); changed {
+		t.Error("expected an equivalent size limit to leave the deployment unchanged")
+	}
+
+	// A removed cache volume is drift.
+	current.Spec.Template.Spec.Volumes = nil
+	changed, updated := deploymentConfigChanged(current, deployment, defaultOperandContainerName)
+	if !changed || len(updated.Spec.Template.Spec.Volumes) != 1 {
+		t.Errorf("expected the cache volume to be restored, got %v", updated)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentCredentialsSidecar(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "vault"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources: []*operatorv1.SourceType{&service},
+			Provider: operatorv1.ProviderSpec{
+				CredentialsSidecar: &operatorv1.ProviderCredentialsSidecar{
+					Container:       corev1.Container{Image: "vault:1.4", Args: []string{"agent"}},
+					CredentialsFile: "aws-credentials",
+				},
+				Env: []corev1.EnvVar{{Name: "AWS_SHARED_CREDENTIALS_FILE", Value: defaultCredentialsSidecarMountPath + "/aws-credentials"}},
+			},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) != 2 || containers[0].Name != credentialsSidecarName || containers[1].Name != defaultOperandContainerName {
+		t.Fatalf("expected the credentials sidecar to start before the operand, got %v", containers)
+	}
+	sidecar := containers[0]
+	expectedSidecarMount := corev1.VolumeMount{Name: credentialsSidecarVolumeName, MountPath: defaultCredentialsSidecarMountPath}
+	if !reflect.DeepEqual(sidecar.VolumeMounts, []corev1.VolumeMount{expectedSidecarMount}) {
+		t.Errorf("expected sidecar volume mounts %v, got %v", []corev1.VolumeMount{expectedSidecarMount}, sidecar.VolumeMounts)
+	}
+	if sidecar.Lifecycle == nil || sidecar.Lifecycle.PostStart == nil || sidecar.Lifecycle.PostStart.Exec == nil ||
+		!slice.ContainsString(sidecar.Lifecycle.PostStart.Exec.Command, defaultCredentialsSidecarMountPath+"/aws-credentials") {
+		t.Errorf("expected a postStart hook waiting for the credentials file, got %v", sidecar.Lifecycle)
+	}
+	expectedOperandMount := corev1.VolumeMount{Name: credentialsSidecarVolumeName, MountPath: defaultCredentialsSidecarMountPath, ReadOnly: true}
+	if mounts := containers[1].VolumeMounts; !reflect.DeepEqual(mounts, []corev1.VolumeMount{expectedOperandMount}) {
+		t.Errorf("expected operand volume mounts %v, got %v", []corev1.VolumeMount{expectedOperandMount}, mounts)
+	}
+	if volumes := deployment.Spec.Template.Spec.Volumes; len(volumes) != 1 || volumes[0].Name != credentialsSidecarVolumeName || volumes[0].EmptyDir == nil {
+		t.Errorf("expected a shared emptyDir volume, got %v", volumes)
+	}
+
+	// A removed sidecar is restored ahead of the operand.
+	current := deployment.DeepCopy()
+	current.Spec.Template.Spec.Containers = current.Spec.Template.Spec.Containers[1:]
+	changed, updated := deploymentConfigChanged(current, deployment, defaultOperandContainerName)
+	if !changed {
+		t.Fatal("expected a removed credentials sidecar to change the deployment")
+	}
+	if containers := updated.Spec.Template.Spec.Containers; len(containers) != 2 || containers[0].Name != credentialsSidecarName {
+		t.Errorf("expected the credentials sidecar to be restored first, got %v", containers)
+	}
+}
+
+func TestDesiredExternalDNSDeploymentOperandLease(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.InMemoryProvider
+	one, two := int32(1), int32(2)
+	testCases := []struct {
+		description string
+		spec        operatorv1.ExternalDNSSpec
+		expectLease bool
+	}{
+		{"defaults", operatorv1.ExternalDNSSpec{}, false},
+		{"single replica", operatorv1.ExternalDNSSpec{Replicas: &one}, false},
+		{"multiple replicas", operatorv1.ExternalDNSSpec{Replicas: &two}, true},
+		{"autoscaled", operatorv1.ExternalDNSSpec{Autoscaling: &operatorv1.AutoscalingSpec{MaxReplicas: 1}}, true},
+	}
+	for _, tc := range testCases {
+		tc.spec.Sources = []*operatorv1.SourceType{&service}
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: "lease"},
+			Spec:       tc.spec,
+			Status:     operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+		container := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName)
+		hasVolume := false
+		for _, v := range deployment.Spec.Template.Spec.Volumes {
+			if v.Name == operandLeaseVolumeName && v.DownwardAPI != nil {
+				hasVolume = true
+			}
+		}
+		hasMount := false
+		for _, m := range container.VolumeMounts {
+			if m.Name == operandLeaseVolumeName && m.MountPath == operandLeaseMountPath {
+				hasMount = true
+			}
+		}
+		hasWrapper := len(container.Command) > 4 && container.Command[2] == operandLeaseScript
+		if hasVolume != tc.expectLease || hasMount != tc.expectLease || hasWrapper != tc.expectLease {
+			t.Errorf("%q: expected lease %t, got volume %t, mount %t, command %v", tc.description, tc.expectLease, hasVolume, hasMount, container.Command)
+		}
+		if !slice.ContainsString(container.Args, "--source=service") {
+			t.Errorf("%q: expected the args to be passed through, got %v", tc.description, container.Args)
+		}
+		if tc.expectLease {
+			// The standby doesn't call the provider and serves the probes
+			// on the metrics address.
+			standby := container.Command[4:]
+			if standby[len(standby)-1] != "--" ||
+				!slice.ContainsString(standby, "--provider=inmemory") ||
+				!slice.ContainsString(standby, "--metrics-address="+defaultMetricsAddress) ||
+				slice.ContainsString(container.Args, "--dry-run") {
+				t.Errorf("%q: expected an idle standby ahead of the args, got command %v and args %v", tc.description, standby, container.Args)
+			}
+		}
+
+		// Enabling or disabling the lease rolls out the wrapper.
+		current := deployment.DeepCopy()
+		operandContainer(&current.Spec.Template.Spec, defaultOperandContainerName).Command = nil
+		changed, updated := deploymentConfigChanged(current, deployment, defaultOperandContainerName)
+		if changed != tc.expectLease {
+			t.Errorf("%q: expected a missing wrapper to change %t, got %t", tc.description, tc.expectLease, changed)
+		}
+		if changed && !reflect.DeepEqual(operandContainer(&updated.Spec.Template.Spec, defaultOperandContainerName).Command, container.Command) {
+			t.Errorf("%q: expected the wrapper command to be restored, got %v", tc.description, operandContainer(&updated.Spec.Template.Spec, defaultOperandContainerName).Command)
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentNilZoneFilterEntry(t *testing.T) {
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName}}
+	service := operatorv1.ServiceType
+	provider := operatorv1.AWSProvider
+	// A default externaldns created by an earlier operator from a dns
+	// config without a public zone has a nil zone filter entry.
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-public"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:  []*operatorv1.SourceType{&service},
+			Provider: operatorv1.ProviderSpec{ZoneFilter: []*configv1.DNSZone{nil, {ID: "Z1"}}},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider, BaseDomain: "example.com"},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	args := operandContainer(&deployment.Spec.Template.Spec, defaultOperandContainerName).Args
+	if !slice.ContainsString(args, "--zone-id-filter=Z1") {
+		t.Errorf("expected arg --zone-id-filter=Z1 in %v", args)
+	}
+}
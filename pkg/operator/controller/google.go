@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// googleCredentialsFileName is the name of the GCP service account key
+	// file, both in the operator credentials and in the operand.
+	googleCredentialsFileName = "service_account.json"
+
+	// googleCredentialsEnvVar is the environment variable pointing the
+	// Google client libraries of the operand to the service account key.
+	googleCredentialsEnvVar = "GOOGLE_APPLICATION_CREDENTIALS"
+)
+
+// googleCredentialsFile returns the GCP service account key of creds, the
+// secret provisioned for a GCP CredentialsRequest.
+func googleCredentialsFile(creds *corev1.Secret) ([]byte, error) {
+	if creds == nil {
+		return nil, fmt.Errorf("no gcp credentials")
+	}
+	key := creds.Data[googleCredentialsFileName]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("gcp credentials are missing key %q", googleCredentialsFileName)
+	}
+	return key, nil
+}
+
+// googleProjectID returns the GCP project of the service account key of creds.
+func googleProjectID(creds *corev1.Secret) (string, error) {
+	key, err := googleCredentialsFile(creds)
+	if err != nil {
+		return "", err
+	}
+	account := struct {
+		ProjectID string `json:"project_id"`
+	}{}
+	if err := json.Unmarshal(key, &account); err != nil {
+		return "", fmt.Errorf("failed to parse gcp service account key: %v", err)
+	}
+	if len(account.ProjectID) == 0 {
+		return "", fmt.Errorf("gcp service account key has no project_id")
+	}
+	return account.ProjectID, nil
+}
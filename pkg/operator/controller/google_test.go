@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/util/slice"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGoogleProjectID(t *testing.T) {
+	testCases := []struct {
+		description string
+		key         string
+		expected    string
+		expectErr   bool
+	}{
+		{"service account key", `{"type":"service_account","project_id":"my-project"}`, "my-project", false},
+		{"no key", "", "", true},
+		{"invalid key", "{", "", true},
+		{"no project", `{"type":"service_account"}`, "", true},
+	}
+	for _, tc := range testCases {
+		creds := &corev1.Secret{Data: map[string][]byte{}}
+		if len(tc.key) != 0 {
+			creds.Data[googleCredentialsFileName] = []byte(tc.key)
+		}
+		project, err := googleProjectID(creds)
+		if (err != nil) != tc.expectErr || project != tc.expected {
+			t.Errorf("%q: expected %q with error %t, got %q, %v", tc.description, tc.expected, tc.expectErr, project, err)
+		}
+	}
+}
+
+func TestDesiredExternalDNSDeploymentGoogle(t *testing.T) {
+	creds := &corev1.Secret{
+		Data: map[string][]byte{googleCredentialsFileName: []byte(`{"type":"service_account","project_id":"my-project"}`)},
+	}
+	r := &reconciler{Config: Config{OperandContainerName: defaultOperandContainerName, Credentials: creds}}
+	service := operatorv1.ServiceType
+	public := operatorv1.PublicZoneType
+	provider := operatorv1.GoogleProvider
+	batchSize := int32(500)
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "gcp"},
+		Spec: operatorv1.ExternalDNSSpec{
+			Sources:  []*operatorv1.SourceType{&service},
+			ZoneType: &public,
+			Provider: operatorv1.ProviderSpec{
+				GoogleBatchChangeSize:     &batchSize,
+				GoogleBatchChangeInterval: &metav1.Duration{Duration: 5 * time.Second},
+			},
+		},
+		Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+	}
+	deployment := r.desiredExternalDNSDeployment(edns, "externaldns:latest", &configv1.DNS{}, &configv1.Infrastructure{})
+	podSpec := deployment.Spec.Template.Spec
+	container := operandContainer(&podSpec, defaultOperandContainerName)
+	for _, expected := range []string{
+		"--provider=google",
+		"--google-project=my-project",
+		"--google-zone-visibility=public",
+		"--google-batch-change-size=500",
+		"--google-batch-change-interval=5s",
+	} {
+		if !slice.ContainsString(container.Args, expected) {
+			t.Errorf("expected arg %q in %v", expected, container.Args)
+		}
+	}
+	expectedEnv := corev1.EnvVar{Name: googleCredentialsEnvVar, Value: "/etc/externaldns/cloud-credentials/service_account.json"}
+	found := false
+	for _, env := range container.Env {
+		found = found || env == expectedEnv
+	}
+	if !found {
+		t.Errorf("expected env %v in %v", expectedEnv, container.Env)
+	}
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Secret == nil ||
+		podSpec.Volumes[0].Secret.SecretName != ExternalDNSCredentialsSecretNamespacedName(DefaultOperandNamespace, edns).Name {
+		t.Errorf("expected the credentials secret volume, got %v", podSpec.Volumes)
+	}
+
+	files, err := operandCredentialsFiles(provider, creds)
+	if err != nil || string(files[googleCredentialsFileName]) != string(creds.Data[googleCredentialsFileName]) {
+		t.Errorf("expected the service account key as credentials file, got %v, %v", files, err)
+	}
+	if files, err := operandCredentialsFiles(operatorv1.AWSProvider, creds); files != nil || err != nil {
+		t.Errorf("expected no credentials files for aws, got %v, %v", files, err)
+	}
+}
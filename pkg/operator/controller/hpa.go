@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	"github.com/sirupsen/logrus"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ensureExternalDNSHPA ensures the HorizontalPodAutoscaler of the operand
+// deployment of edns matches spec.autoscaling, deleting it when autoscaling
+// isn't configured or the operand doesn't run as a deployment.
+func (r *reconciler) ensureExternalDNSHPA(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.Autoscaling == nil || edns.Spec.RunMode == operatorv1.OnceRunMode {
+		return r.ensureExternalDNSHPADeleted(edns)
+	}
+
+	desired := desiredExternalDNSHPA(r.OperandNamespace, edns)
+	current := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err := r.kclient.Get(context.TODO(), ExternalDNSHPANamespacedName(r.OperandNamespace, edns), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get externaldns horizontal pod autoscaler %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.kclient.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create externaldns horizontal pod autoscaler %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		logrus.Infof("created externaldns horizontal pod autoscaler %s/%s", desired.Namespace, desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Spec, desired.Spec) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Spec = desired.Spec
+	if err := r.kclient.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update externaldns horizontal pod autoscaler %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	logrus.Infof("updated externaldns horizontal pod autoscaler %s/%s", updated.Namespace, updated.Name)
+	return nil
+}
+
+// ensureExternalDNSHPADeleted ensures the HorizontalPodAutoscaler of the
+// operand deployment of edns is deleted.
+func (r *reconciler) ensureExternalDNSHPADeleted(edns *operatorv1.ExternalDNS) error {
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	name := ExternalDNSHPANamespacedName(r.OperandNamespace, edns)
+	hpa.Name = name.Name
+	hpa.Namespace = name.Namespace
+	if err := r.kclient.Delete(context.TODO(), hpa); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete externaldns horizontal pod autoscaler %s/%s: %v", hpa.Namespace, hpa.Name, err)
+	}
+	return nil
+}
+
+// desiredExternalDNSHPA returns the HorizontalPodAutoscaler scaling the
+// operand deployment of edns according to spec.autoscaling.
+func desiredExternalDNSHPA(operandNamespace string, edns *operatorv1.ExternalDNS) *autoscalingv1.HorizontalPodAutoscaler {
+	hpa := manifests.ExternalDNSHorizontalPodAutoscaler()
+	name := ExternalDNSHPANamespacedName(operandNamespace, edns)
+	hpa.Name = name.Name
+	hpa.Namespace = name.Namespace
+	setExternalDNSOwnerReference(hpa, edns)
+	hpa.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	hpa.Spec.ScaleTargetRef.Name = ExternalDNSDeploymentNamespacedName(operandNamespace, edns).Name
+
+	minReplicas := int32(1)
+	if edns.Spec.Autoscaling.MinReplicas != nil {
+		minReplicas = *edns.Spec.Autoscaling.MinReplicas
+	}
+	hpa.Spec.MinReplicas = &minReplicas
+	hpa.Spec.MaxReplicas = edns.Spec.Autoscaling.MaxReplicas
+	if edns.Spec.Autoscaling.TargetCPUUtilizationPercentage != nil {
+		target := *edns.Spec.Autoscaling.TargetCPUUtilizationPercentage
+		hpa.Spec.TargetCPUUtilizationPercentage = &target
+	}
+	return hpa
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/sirupsen/logrus"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ensureExternalDNSJob ensures the Job running the operand of edns once
+// exists and matches the desired configuration. Since the pod template of
+// a Job is immutable, a changed Job is deleted and recreated once the
+// deletion requeues edns.
+func (r *reconciler) ensureExternalDNSJob(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS,
+	infraConfig *configv1.Infrastructure) error {
+	desired := r.desiredExternalDNSJob(edns, dnsConfig, infraConfig)
+	current, err := r.currentExternalDNSJob(edns)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		if err := r.kclient.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create externaldns job %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		logrus.Infof("created externaldns job %s/%s", desired.Namespace, desired.Name)
+		return nil
+	}
+	if current.DeletionTimestamp != nil || !jobConfigChanged(current, desired, r.OperandContainerName) {
+		return nil
+	}
+	if err := r.kclient.Delete(context.TODO(), current, kclient.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete externaldns job %s/%s: %v", current.Namespace, current.Name, err)
+	}
+	logrus.Infof("deleted externaldns job %s/%s to apply configuration changes", current.Namespace, current.Name)
+	return nil
+}
+
+// currentExternalDNSJob returns the current Job running the operand of edns
+// once, or nil if it doesn't exist.
+func (r *reconciler) currentExternalDNSJob(edns *operatorv1.ExternalDNS) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	name := ExternalDNSJobNamespacedName(r.OperandNamespace, edns)
+	if err := r.kclient.Get(context.TODO(), name, job); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get externaldns job %s/%s: %v", name.Namespace, name.Name, err)
+	}
+	return job, nil
+}
+
+// ensureExternalDNSJobDeleted ensures that the Job running the operand of
+// edns once, and its pods, are deleted.
+func (r *reconciler) ensureExternalDNSJobDeleted(edns *operatorv1.ExternalDNS) error {
+	job := &batchv1.Job{}
+	name := ExternalDNSJobNamespacedName(r.OperandNamespace, edns)
+	job.Name = name.Name
+	job.Namespace = name.Namespace
+	if err := r.kclient.Delete(context.TODO(), job, kclient.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// desiredExternalDNSJob returns the Job running the operand of edns once,
+// using the pod template of the operand deployment.
+func (r *reconciler) desiredExternalDNSJob(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS,
+	infraConfig *configv1.Infrastructure) *batchv1.Job {
+	deployment := r.desiredExternalDNSDeployment(edns, r.Config.ExternalDNSImage, dnsConfig, infraConfig)
+
+	job := manifests.ExternalDNSJob()
+	name := ExternalDNSJobNamespacedName(r.OperandNamespace, edns)
+	job.Name = name.Name
+	job.Namespace = name.Namespace
+	setExternalDNSOwnerReference(job, edns)
+	job.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	job.Spec.Template = *deployment.Spec.Template.DeepCopy()
+	job.Spec.Template.Spec.RestartPolicy = operandRestartPolicy(edns)
+	// Don't select the pods of the deployment while switching run modes.
+	job.Spec.Template.Labels = nil
+	job.Spec.Template.Spec.Affinity = nil
+	// The operand exits after a single sync, so it has nothing to probe.
+	container := operandContainer(&job.Spec.Template.Spec, r.OperandContainerName)
+	container.LivenessProbe = nil
+	container.ReadinessProbe = nil
+	container.Args = append(container.Args, "--once")
+
+	return job
+}
+
+// jobConfigChanged returns true if the pod template of current differs from
+// that of expected in a way the operator manages.
+func jobConfigChanged(current, expected *batchv1.Job, containerName string) bool {
+	currentSpec, expectedSpec := current.Spec.Template.Spec, expected.Spec.Template.Spec
+	currentContainer := operandContainer(&currentSpec, containerName)
+	expectedContainer := operandContainer(&expectedSpec, containerName)
+	return currentSpec.RestartPolicy != expectedSpec.RestartPolicy ||
+		currentContainer == nil ||
+		currentContainer.Image != expectedContainer.Image ||
+		!cmp.Equal(currentContainer.Args, expectedContainer.Args, cmpopts.EquateEmpty()) ||
+		!cmp.Equal(currentContainer.Env, expectedContainer.Env, cmpopts.EquateEmpty()) ||
+		!cmp.Equal(currentContainer.VolumeMounts, expectedContainer.VolumeMounts, cmpopts.EquateEmpty()) ||
+		!cmp.Equal(currentSpec.Volumes, expectedSpec.Volumes, cmpopts.EquateEmpty()) ||
+		!cmp.Equal(podFSGroup(&currentSpec), podFSGroup(&expectedSpec)) ||
+		!cmp.Equal(currentSpec.InitContainers, expectedSpec.InitContainers, cmpopts.EquateEmpty(),
+			cmpopts.IgnoreFields(corev1.Container{}, initContainerDefaultedFields...))
+}
+
+// operandRestartPolicy returns the effective restart policy of the operand
+// pods of edns.
+func operandRestartPolicy(edns *operatorv1.ExternalDNS) corev1.RestartPolicy {
+	if len(edns.Spec.RestartPolicy) != 0 {
+		return edns.Spec.RestartPolicy
+	}
+	if edns.Spec.RunMode == operatorv1.OnceRunMode {
+		return corev1.RestartPolicyOnFailure
+	}
+	return corev1.RestartPolicyAlways
+}
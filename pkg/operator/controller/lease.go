@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ensureOperandLease grants the lease of the txt owner id of edns to a
+// single operand pod, so that the replicas of an ExternalDNS controller
+// sharing the owner id never write its records at the same time. The
+// lease is held by labeling the pod with operandLeaseHolderLabel, which
+// the lease wrapper of the pod reads through the downward API.
+//
+// The lease is held until the pod is gone or has terminated; a deleted pod
+// keeps the lease while it shuts down. Pods of externaldnses that don't use
+// the lease always write, so no lease is granted while one runs.
+func (r *reconciler) ensureOperandLease(edns *operatorv1.ExternalDNS) error {
+	if !operandLeaseEnabled(edns) {
+		return nil
+	}
+	sharing := []operatorv1.ExternalDNS{*edns}
+	if owner := edns.Status.TextOwnerID; len(owner) != 0 {
+		dnses := &operatorv1.ExternalDNSList{}
+		if err := r.kclient.List(context.TODO(), dnses, kclient.InNamespace(r.Namespace)); err != nil {
+			return fmt.Errorf("failed to list externaldnses: %v", err)
+		}
+		for _, dns := range dnses.Items {
+			if dns.Name != edns.Name && dns.Status.TextOwnerID == owner {
+				sharing = append(sharing, dns)
+			}
+		}
+	}
+
+	candidates := []corev1.Pod{}
+	for i := range sharing {
+		dns := &sharing[i]
+		pods := &corev1.PodList{}
+		namespace := ExternalDNSDeploymentNamespacedName(r.OperandNamespace, dns).Namespace
+		selector := ExternalDNSDeploymentPodSelector(dns).MatchLabels
+		if err := r.kclient.List(context.TODO(), pods, kclient.InNamespace(namespace), kclient.MatchingLabels(selector)); err != nil {
+			return fmt.Errorf("failed to list pods of externaldns %s: %v", dns.Name, err)
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			if pod.Labels[operandLeaseHolderLabel] == "true" || !operandLeaseEnabled(dns) {
+				// The owner id already has a writer.
+				return nil
+			}
+			if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+				candidates = append(candidates, pod)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Grant the lease to the oldest pod, which is the least likely to be
+	// scaled down.
+	sort.Slice(candidates, func(i, j int) bool {
+		ti, tj := candidates[i].CreationTimestamp, candidates[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	holder := candidates[0].DeepCopy()
+	if holder.Labels == nil {
+		holder.Labels = map[string]string{}
+	}
+	holder.Labels[operandLeaseHolderLabel] = "true"
+	if err := r.kclient.Update(context.TODO(), holder); err != nil {
+		return fmt.Errorf("failed to grant the txt owner id lease of externaldns %s to pod %s/%s: %v", edns.Name, holder.Namespace, holder.Name, err)
+	}
+	logrus.Infof("granted the txt owner id lease of externaldns %s to pod %s/%s", edns.Name, holder.Namespace, holder.Name)
+	return nil
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podLeaseClient is a client listing the given externaldnses and the pods
+// matching the label selector, and recording pod updates. Calls to other
+// methods panic.
+type podLeaseClient struct {
+	kclient.Client
+	dnses   []operatorv1.ExternalDNS
+	pods    []corev1.Pod
+	updated []*corev1.Pod
+}
+
+func (c *podLeaseClient) List(ctx context.Context, list runtime.Object, opts ...kclient.ListOptionFunc) error {
+	switch l := list.(type) {
+	case *operatorv1.ExternalDNSList:
+		l.Items = c.dnses
+	case *corev1.PodList:
+		options := (&kclient.ListOptions{}).ApplyOptions(opts)
+		for _, pod := range c.pods {
+			if options.LabelSelector == nil || options.LabelSelector.Matches(labels.Set(pod.Labels)) {
+				l.Items = append(l.Items, pod)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *podLeaseClient) Update(ctx context.Context, obj runtime.Object, opts ...kclient.UpdateOptionFunc) error {
+	c.updated = append(c.updated, obj.(*corev1.Pod).DeepCopy())
+	return nil
+}
+
+func TestEnsureOperandLease(t *testing.T) {
+	two := int32(2)
+	newExternalDNS := func(name, owner string, replicas *int32) operatorv1.ExternalDNS {
+		return operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       operatorv1.ExternalDNSSpec{Replicas: replicas},
+			Status:     operatorv1.ExternalDNSStatus{TextOwnerID: owner},
+		}
+	}
+	scaled := newExternalDNS("scaled", "owner", &two)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newPod := func(edns operatorv1.ExternalDNS, name string, age time.Duration, phase corev1.PodPhase, holder bool) corev1.Pod {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         DefaultOperandNamespace,
+				Name:              name,
+				Labels:            map[string]string{},
+				CreationTimestamp: metav1.NewTime(start.Add(-age)),
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+		for k, v := range ExternalDNSDeploymentPodSelector(&edns).MatchLabels {
+			pod.Labels[k] = v
+		}
+		if holder {
+			pod.Labels[operandLeaseHolderLabel] = "true"
+		}
+		return pod
+	}
+	deleting := func(pod corev1.Pod) corev1.Pod {
+		now := metav1.NewTime(start)
+		pod.DeletionTimestamp = &now
+		return pod
+	}
+
+	testCases := []struct {
+		description    string
+		edns           operatorv1.ExternalDNS
+		others         []operatorv1.ExternalDNS
+		pods           []corev1.Pod
+		expectedHolder string
+	}{
+		{
+			description: "single replica doesn't use the lease",
+			edns:        newExternalDNS("single", "owner", nil),
+			pods:        []corev1.Pod{newPod(newExternalDNS("single", "owner", nil), "single-a", time.Hour, corev1.PodRunning, false)},
+		},
+		{
+			description:    "oldest running pod is granted the lease",
+			edns:           scaled,
+			pods:           []corev1.Pod{newPod(scaled, "scaled-b", time.Minute, corev1.PodRunning, false), newPod(scaled, "scaled-c", time.Hour, corev1.PodRunning, false), newPod(scaled, "scaled-a", 2*time.Hour, corev1.PodPending, false)},
+			expectedHolder: "scaled-c",
+		},
+		{
+			description:    "pods of the same age are ordered by name",
+			edns:           scaled,
+			pods:           []corev1.Pod{newPod(scaled, "scaled-b", time.Hour, corev1.PodRunning, false), newPod(scaled, "scaled-a", time.Hour, corev1.PodRunning, false)},
+			expectedHolder: "scaled-a",
+		},
+		{
+			description: "running holder keeps the lease",
+			edns:        scaled,
+			pods:        []corev1.Pod{newPod(scaled, "scaled-a", time.Hour, corev1.PodRunning, false), newPod(scaled, "scaled-b", time.Minute, corev1.PodRunning, true)},
+		},
+		{
+			description: "terminating holder keeps the lease",
+			edns:        scaled,
+			pods:        []corev1.Pod{newPod(scaled, "scaled-a", time.Hour, corev1.PodRunning, false), deleting(newPod(scaled, "scaled-b", time.Minute, corev1.PodRunning, true))},
+		},
+		{
+			description:    "failed holder releases the lease",
+			edns:           scaled,
+			pods:           []corev1.Pod{newPod(scaled, "scaled-a", time.Hour, corev1.PodFailed, true), newPod(scaled, "scaled-b", time.Minute, corev1.PodRunning, false)},
+			expectedHolder: "scaled-b",
+		},
+		{
+			description: "terminating pod isn't granted the lease",
+			edns:        scaled,
+			pods:        []corev1.Pod{deleting(newPod(scaled, "scaled-a", time.Hour, corev1.PodRunning, false))},
+		},
+		{
+			description: "holder of another externaldns with the owner id keeps the lease",
+			edns:        scaled,
+			others:      []operatorv1.ExternalDNS{newExternalDNS("other", "owner", &two)},
+			pods:        []corev1.Pod{newPod(scaled, "scaled-a", time.Hour, corev1.PodRunning, false), newPod(newExternalDNS("other", "owner", &two), "other-a", time.Minute, corev1.PodRunning, true)},
+		},
+		{
+			description: "pod of another externaldns without the lease writes the owner id",
+			edns:        scaled,
+			others:      []operatorv1.ExternalDNS{newExternalDNS("other", "owner", nil)},
+			pods:        []corev1.Pod{newPod(scaled, "scaled-a", time.Hour, corev1.PodRunning, false), newPod(newExternalDNS("other", "owner", nil), "other-a", time.Minute, corev1.PodRunning, false)},
+		},
+		{
+			description:    "holder of another owner id doesn't hold the lease",
+			edns:           scaled,
+			others:         []operatorv1.ExternalDNS{newExternalDNS("other", "other-owner", &two)},
+			pods:           []corev1.Pod{newPod(scaled, "scaled-a", time.Hour, corev1.PodRunning, false), newPod(newExternalDNS("other", "other-owner", &two), "other-a", time.Minute, corev1.PodRunning, true)},
+			expectedHolder: "scaled-a",
+		},
+	}
+	for _, tc := range testCases {
+		client := &podLeaseClient{dnses: append([]operatorv1.ExternalDNS{tc.edns}, tc.others...), pods: tc.pods}
+		r := &reconciler{Config: Config{OperandNamespace: DefaultOperandNamespace}, kclient: client}
+		edns := tc.edns.DeepCopy()
+		if err := r.ensureOperandLease(edns); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		switch {
+		case len(tc.expectedHolder) == 0 && len(client.updated) != 0:
+			t.Errorf("%q: expected no lease to be granted, got %s", tc.description, client.updated[0].Name)
+		case len(tc.expectedHolder) != 0 && len(client.updated) != 1:
+			t.Errorf("%q: expected the lease to be granted to %s, got %d updates", tc.description, tc.expectedHolder, len(client.updated))
+		case len(tc.expectedHolder) != 0 && (client.updated[0].Name != tc.expectedHolder || client.updated[0].Labels[operandLeaseHolderLabel] != "true"):
+			t.Errorf("%q: expected the lease to be granted to %s, got %s with labels %v", tc.description, tc.expectedHolder, client.updated[0].Name, client.updated[0].Labels)
+		}
+	}
+}
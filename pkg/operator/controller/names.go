@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+
 	operatorv1 "github.com/danehans/api/operator/v1"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -10,21 +13,88 @@ import (
 )
 
 const (
+	// DefaultOperandNamespace is the namespace of the operand resources
+	// when the operator isn't configured with another one.
+	DefaultOperandNamespace = "openshift-externaldns"
+
 	// controllerDeploymentLabel identifies a deployment as an
 	// externaldns deployment, and the value is the name of the
 	// owning externaldns.
 	controllerDeploymentLabel = "externaldns.operator.openshift.io/deployment-externaldns"
+
+	// operandLeaseHolderLabel marks the operand pod granted the lease of
+	// its txt owner id, and so the only pod writing the records of the
+	// owner id.
+	operandLeaseHolderLabel = "externaldns.operator.openshift.io/lease-holder"
 )
 
 // ExternalDNSDeploymentNamespacedName returns the namespaced name
-// for the externaldns Deployment.
-func ExternalDNSDeploymentNamespacedName(edns *operatorv1.ExternalDNS) types.NamespacedName {
+// for the externaldns Deployment in operandNamespace.
+func ExternalDNSDeploymentNamespacedName(operandNamespace string, edns *operatorv1.ExternalDNS) types.NamespacedName {
 	return types.NamespacedName{
-		Namespace: "openshift-externaldns",
+		Namespace: operandNamespace,
 		Name:      "externaldns-" + edns.Name,
 	}
 }
 
+// ExternalDNSJobNamespacedName returns the namespaced name for the Job
+// running the operand of edns once. Only one of the Job and the Deployment
+// exists at a time, so they share a name.
+func ExternalDNSJobNamespacedName(operandNamespace string, edns *operatorv1.ExternalDNS) types.NamespacedName {
+	return ExternalDNSDeploymentNamespacedName(operandNamespace, edns)
+}
+
+// ExternalDNSHPANamespacedName returns the namespaced name for the
+// HorizontalPodAutoscaler of the externaldns Deployment.
+func ExternalDNSHPANamespacedName(operandNamespace string, edns *operatorv1.ExternalDNS) types.NamespacedName {
+	return ExternalDNSDeploymentNamespacedName(operandNamespace, edns)
+}
+
+// ExternalDNSNetworkPolicyNamespacedName returns the namespaced name for the
+// NetworkPolicy of the pods of the externaldns Deployment.
+func ExternalDNSNetworkPolicyNamespacedName(operandNamespace string, edns *operatorv1.ExternalDNS) types.NamespacedName {
+	return ExternalDNSDeploymentNamespacedName(operandNamespace, edns)
+}
+
+// ExternalDNSCleanupJobNamespacedName returns the namespaced name for the
+// Job that removes the records owned by edns.
+func ExternalDNSCleanupJobNamespacedName(operandNamespace string, edns *operatorv1.ExternalDNS) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: operandNamespace,
+		Name:      "externaldns-cleanup-" + edns.Name,
+	}
+}
+
+// ExternalDNSConfigMapNamespacedName returns the namespaced name for the
+// ConfigMap documenting the effective configuration of edns.
+func ExternalDNSConfigMapNamespacedName(operandNamespace string, edns *operatorv1.ExternalDNS) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: operandNamespace,
+		Name:      "externaldns-config-" + edns.Name,
+	}
+}
+
+// ExternalDNSCredentialsSecretNamespacedName returns the namespaced name for
+// the secret holding the provider credentials files of the operand of edns.
+func ExternalDNSCredentialsSecretNamespacedName(operandNamespace string, edns *operatorv1.ExternalDNS) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: operandNamespace,
+		Name:      "externaldns-credentials-" + edns.Name,
+	}
+}
+
+// ExternalDNSCRDSourceBindingName returns the name of the RoleBinding or
+// ClusterRoleBinding granting the operand of edns access to DNSEndpoints.
+func ExternalDNSCRDSourceBindingName(edns *operatorv1.ExternalDNS) string {
+	return "externaldns-crd-source-" + edns.Name
+}
+
+// ExternalDNSContourHTTPProxySourceBindingName returns the name of the
+// ClusterRoleBinding granting the operand of edns access to HTTPProxies.
+func ExternalDNSContourHTTPProxySourceBindingName(edns *operatorv1.ExternalDNS) string {
+	return "externaldns-contour-httpproxy-source-" + edns.Name
+}
+
 // ExternalDNSNamespacedName returns the namespaced name of edns.
 func ExternalDNSNamespacedName(edns *operatorv1.ExternalDNS) types.NamespacedName {
 	return types.NamespacedName{
@@ -48,11 +118,56 @@ func ExternalDNSName(edns *operatorv1.ExternalDNS) string {
 	return edns.Name
 }
 
-// TextOwnerID returns the ExternalDNS controller txt owner id.
+// TextOwnerID returns the ExternalDNS controller txt owner id. The provider
+// type is included so that externaldnses of different providers never share
+// an owner id, and the zone type so that the public and private instances
+// of a split-horizon domain never claim each other's records. Records of a
+// previous owner id format are re-adopted through status.previousTextOwnerID.
+// spec.txtOwnerID, if set, overrides the derived owner id, and
+// spec.txtOwnerIDFormat may shorten the derived owner id to a hash of it.
 func TextOwnerID(infraConfig *configv1.Infrastructure, edns *operatorv1.ExternalDNS) string {
+	if len(edns.Spec.TXTOwnerID) != 0 {
+		return edns.Spec.TXTOwnerID
+	}
+	if edns.Spec.TXTOwnerIDFormat == operatorv1.HashedTXTOwnerIDFormat {
+		return hashedTextOwnerID(fullTextOwnerID(infraConfig, edns))
+	}
+	return fullTextOwnerID(infraConfig, edns)
+}
+
+// legacyTextOwnerID returns the txt owner id used by operators that didn't
+// publish the owner id in status.
+func legacyTextOwnerID(infraConfig *configv1.Infrastructure, edns *operatorv1.ExternalDNS) string {
 	return infraConfig.Status.InfrastructureName + "/" + ExternalDNSNamespaceName(edns)
 }
 
+// hashedTextOwnerIDPrefix and hashedTextOwnerIDHashLength make up a hashed
+// txt owner id. 20 hex characters keep 80 bits of the hash, so distinct full
+// owner ids practically never collide.
+const (
+	hashedTextOwnerIDPrefix     = "externaldns-"
+	hashedTextOwnerIDHashLength = 20
+)
+
+// hashedTextOwnerID returns a txt owner id of fixed length derived from the
+// given full owner id.
+func hashedTextOwnerID(full string) string {
+	sum := sha256.Sum256([]byte(full))
+	return hashedTextOwnerIDPrefix + hex.EncodeToString(sum[:])[:hashedTextOwnerIDHashLength]
+}
+
+// fullTextOwnerID returns the full txt owner id of edns.
+func fullTextOwnerID(infraConfig *configv1.Infrastructure, edns *operatorv1.ExternalDNS) string {
+	if edns.Status.ProviderType == nil || len(*edns.Status.ProviderType) == 0 {
+		return infraConfig.Status.InfrastructureName + "/" + ExternalDNSNamespaceName(edns)
+	}
+	owner := infraConfig.Status.InfrastructureName + "/" + string(*edns.Status.ProviderType)
+	if edns.Spec.ZoneType != nil && len(*edns.Spec.ZoneType) != 0 {
+		owner += "/" + string(*edns.Spec.ZoneType)
+	}
+	return owner + "/" + ExternalDNSNamespaceName(edns)
+}
+
 // ExternalDNSDeploymentPodSelector returns a LabelSelector based
 // on the name of edns.
 func ExternalDNSDeploymentPodSelector(edns *operatorv1.ExternalDNS) *metav1.LabelSelector {
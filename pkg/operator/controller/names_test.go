@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTextOwnerIDDistinctAcrossProviders(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{InfrastructureName: "cluster-abc"},
+	}
+	ids := map[string]operatorv1.ProviderType{}
+	for _, provider := range []operatorv1.ProviderType{
+		operatorv1.AWSProvider,
+		operatorv1.AzureProvider,
+		operatorv1.GoogleProvider,
+		operatorv1.InMemoryProvider,
+	} {
+		p := provider
+		edns := &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "mine"},
+			Status:     operatorv1.ExternalDNSStatus{ProviderType: &p},
+		}
+		id := TextOwnerID(infraConfig, edns)
+		if other, ok := ids[id]; ok {
+			t.Errorf("providers %s and %s share txt owner id %q", other, provider, id)
+		}
+		ids[id] = provider
+	}
+}
+
+func TestTextOwnerIDSplitHorizon(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{InfrastructureName: "cluster-abc"},
+	}
+	aws := operatorv1.AWSProvider
+	newExternalDNS := func(name string, zoneType operatorv1.ZoneType) *operatorv1.ExternalDNS {
+		return &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: name},
+			Spec:       operatorv1.ExternalDNSSpec{ZoneType: &zoneType},
+			Status:     operatorv1.ExternalDNSStatus{ProviderType: &aws},
+		}
+	}
+	public := TextOwnerID(infraConfig, newExternalDNS("default-public-zone", operatorv1.PublicZoneType))
+	private := TextOwnerID(infraConfig, newExternalDNS("default-private-zone", operatorv1.PrivateZoneType))
+	if public == private {
+		t.Errorf("public and private zone externaldnses share txt owner id %q", public)
+	}
+	if expected := "cluster-abc/aws/public/openshift-externaldns-operator/default-public-zone"; public != expected {
+		t.Errorf("expected txt owner id %q, got %q", expected, public)
+	}
+	// The same externaldns switching zone types must not keep claiming the
+	// records it owns in the other zone type.
+	if TextOwnerID(infraConfig, newExternalDNS("mine", operatorv1.PublicZoneType)) ==
+		TextOwnerID(infraConfig, newExternalDNS("mine", operatorv1.PrivateZoneType)) {
+		t.Error("expected the txt owner id to depend on the zone type")
+	}
+}
+
+func TestTextOwnerIDOverride(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{InfrastructureName: "cluster-abc"},
+	}
+	aws := operatorv1.AWSProvider
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: "mine"},
+		Status:     operatorv1.ExternalDNSStatus{ProviderType: &aws},
+	}
+	if id, expected := TextOwnerID(infraConfig, edns), "cluster-abc/aws/openshift-externaldns-operator/mine"; id != expected {
+		t.Errorf("expected derived txt owner id %q, got %q", expected, id)
+	}
+	edns.Spec.TXTOwnerID = "legacy-owner"
+	if id := TextOwnerID(infraConfig, edns); id != "legacy-owner" {
+		t.Errorf("expected overridden txt owner id %q, got %q", "legacy-owner", id)
+	}
+}
+
+func TestHashedTextOwnerID(t *testing.T) {
+	aws := operatorv1.AWSProvider
+	public, private := operatorv1.PublicZoneType, operatorv1.PrivateZoneType
+	newExternalDNS := func(name string, zoneType *operatorv1.ZoneType) *operatorv1.ExternalDNS {
+		return &operatorv1.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-externaldns-operator", Name: name},
+			Spec:       operatorv1.ExternalDNSSpec{ZoneType: zoneType, TXTOwnerIDFormat: operatorv1.HashedTXTOwnerIDFormat},
+			Status:     operatorv1.ExternalDNSStatus{ProviderType: &aws},
+		}
+	}
+	expectedLength := len(hashedTextOwnerIDPrefix) + hashedTextOwnerIDHashLength
+	ids := map[string]string{}
+	for _, infraName := range []string{"abc", strings.Repeat("a-very-long-infrastructure-name", 8)} {
+		infraConfig := &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{InfrastructureName: infraName},
+		}
+		for _, edns := range []*operatorv1.ExternalDNS{
+			newExternalDNS("mine", &public),
+			newExternalDNS("mine", &private),
+			newExternalDNS(strings.Repeat("long-name", 20), &public),
+		} {
+			id := TextOwnerID(infraConfig, edns)
+			if len(id) != expectedLength {
+				t.Errorf("expected hashed txt owner id of length %d, got %q", expectedLength, id)
+			}
+			if id != TextOwnerID(infraConfig, edns.DeepCopy()) {
+				t.Errorf("expected hashed txt owner id %q to be stable", id)
+			}
+			full := fullTextOwnerID(infraConfig, edns)
+			if other, ok := ids[id]; ok {
+				t.Errorf("full txt owner ids %q and %q share hashed txt owner id %q", other, full, id)
+			}
+			ids[id] = full
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// defaultNetworkPolicyEgressPorts are the ports the pods of an externaldns
+// may reach on any address when spec.networkPolicy.egress is empty: HTTPS
+// for the provider APIs and the kube API, and the kube API server port.
+var defaultNetworkPolicyEgressPorts = []int{443, 6443}
+
+// ensureExternalDNSNetworkPolicy ensures the NetworkPolicy of the operand
+// pods of edns matches spec.networkPolicy, deleting it when no policy is
+// configured.
+func (r *reconciler) ensureExternalDNSNetworkPolicy(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.NetworkPolicy == nil {
+		return r.ensureExternalDNSNetworkPolicyDeleted(edns)
+	}
+
+	desired := desiredExternalDNSNetworkPolicy(r.OperandNamespace, edns)
+	current := &networkingv1.NetworkPolicy{}
+	if err := r.kclient.Get(context.TODO(), ExternalDNSNetworkPolicyNamespacedName(r.OperandNamespace, edns), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get externaldns network policy %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		if err := r.kclient.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create externaldns network policy %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		logrus.Infof("created externaldns network policy %s/%s", desired.Namespace, desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Spec, desired.Spec) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Spec = desired.Spec
+	if err := r.kclient.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update externaldns network policy %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	logrus.Infof("updated externaldns network policy %s/%s", updated.Namespace, updated.Name)
+	return nil
+}
+
+// ensureExternalDNSNetworkPolicyDeleted ensures the NetworkPolicy of the
+// operand pods of edns is deleted.
+func (r *reconciler) ensureExternalDNSNetworkPolicyDeleted(edns *operatorv1.ExternalDNS) error {
+	np := &networkingv1.NetworkPolicy{}
+	name := ExternalDNSNetworkPolicyNamespacedName(r.OperandNamespace, edns)
+	np.Name = name.Name
+	np.Namespace = name.Namespace
+	if err := r.kclient.Delete(context.TODO(), np); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete externaldns network policy %s/%s: %v", np.Namespace, np.Name, err)
+	}
+	return nil
+}
+
+// desiredExternalDNSNetworkPolicy returns the NetworkPolicy selecting the
+// operand deployment pods of edns and allowing the egress configured by
+// spec.networkPolicy.
+func desiredExternalDNSNetworkPolicy(operandNamespace string, edns *operatorv1.ExternalDNS) *networkingv1.NetworkPolicy {
+	np := manifests.ExternalDNSNetworkPolicy()
+	name := ExternalDNSNetworkPolicyNamespacedName(operandNamespace, edns)
+	np.Name = name.Name
+	np.Namespace = name.Namespace
+	setExternalDNSOwnerReference(np, edns)
+	np.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	np.Spec.PodSelector = *ExternalDNSDeploymentPodSelector(edns)
+
+	egress := edns.Spec.NetworkPolicy.Egress
+	if len(egress) == 0 {
+		rule := networkingv1.NetworkPolicyEgressRule{}
+		for _, port := range defaultNetworkPolicyEgressPorts {
+			rule.Ports = append(rule.Ports, networkPolicyPort(corev1.ProtocolTCP, intstr.FromInt(port)))
+		}
+		egress = []networkingv1.NetworkPolicyEgressRule{rule}
+	}
+	for _, rule := range egress {
+		rule = *rule.DeepCopy()
+		// Default the protocol as the API server does, so the policy
+		// doesn't appear to drift.
+		for i := range rule.Ports {
+			if rule.Ports[i].Protocol == nil {
+				tcp := corev1.ProtocolTCP
+				rule.Ports[i].Protocol = &tcp
+			}
+		}
+		np.Spec.Egress = append(np.Spec.Egress, rule)
+	}
+	return np
+}
+
+// networkPolicyPort returns the NetworkPolicyPort of the given protocol and
+// port.
+func networkPolicyPort(protocol corev1.Protocol, port intstr.IntOrString) networkingv1.NetworkPolicyPort {
+	return networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDesiredExternalDNSNetworkPolicy(t *testing.T) {
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "locked-down"},
+		Spec:       operatorv1.ExternalDNSSpec{NetworkPolicy: &operatorv1.NetworkPolicySpec{}},
+	}
+	np := desiredExternalDNSNetworkPolicy(DefaultOperandNamespace, edns)
+	if np.Spec.PodSelector.MatchLabels[controllerDeploymentLabel] != ExternalDNSName(edns) {
+		t.Errorf("expected the policy to select the operand pods, got %v", np.Spec.PodSelector)
+	}
+	if len(np.Spec.PolicyTypes) != 2 {
+		t.Errorf("expected the policy to restrict ingress and egress, got %v", np.Spec.PolicyTypes)
+	}
+	// The manifest allows name resolution, followed by the default egress.
+	if len(np.Spec.Egress) != 2 {
+		t.Fatalf("expected the name resolution and default egress rules, got %v", np.Spec.Egress)
+	}
+	defaultEgress := np.Spec.Egress[1]
+	if len(defaultEgress.To) != 0 || len(defaultEgress.Ports) != len(defaultNetworkPolicyEgressPorts) {
+		t.Errorf("expected egress to the default ports on any address, got %v", defaultEgress)
+	}
+
+	port := intstr.FromInt(443)
+	edns.Spec.NetworkPolicy.Egress = []networkingv1.NetworkPolicyEgressRule{{
+		To:    []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "192.0.2.0/24"}}},
+		Ports: []networkingv1.NetworkPolicyPort{{Port: &port}},
+	}}
+	np = desiredExternalDNSNetworkPolicy(DefaultOperandNamespace, edns)
+	if len(np.Spec.Egress) != 2 {
+		t.Fatalf("expected the name resolution and configured egress rules, got %v", np.Spec.Egress)
+	}
+	egress := np.Spec.Egress[1]
+	if egress.To[0].IPBlock.CIDR != "192.0.2.0/24" {
+		t.Errorf("expected the configured egress peer, got %v", egress.To)
+	}
+	if p := egress.Ports[0].Protocol; p == nil || *p != corev1.ProtocolTCP {
+		t.Errorf("expected the port protocol to default to TCP, got %v", p)
+	}
+	if edns.Spec.NetworkPolicy.Egress[0].Ports[0].Protocol != nil {
+		t.Errorf("expected the spec of the externaldns not to be modified")
+	}
+}
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PlatformType returns the platform type of the cluster from
+// infrastructure.config/cluster. The infrastructure is read unstructured
+// since status.platformStatus is newer than the vendored config API.
+func PlatformType(client kclient.Client) (configv1.PlatformType, error) {
+	infra := &unstructured.Unstructured{}
+	infra.SetGroupVersionKind(configv1.GroupVersion.WithKind("Infrastructure"))
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infra); err != nil {
+		return "", fmt.Errorf("failed to get infrastructure 'cluster': %v", err)
+	}
+	return platformTypeForInfra(infra), nil
+}
+
+// platformTypeForInfra returns status.platformStatus.type of infra, falling
+// back to the deprecated status.platform when platformStatus is unset.
+func platformTypeForInfra(infra *unstructured.Unstructured) configv1.PlatformType {
+	if platform, _, _ := unstructured.NestedString(infra.Object, "status", "platformStatus", "type"); len(platform) != 0 {
+		return configv1.PlatformType(platform)
+	}
+	platform, _, _ := unstructured.NestedString(infra.Object, "status", "platform")
+	return configv1.PlatformType(platform)
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPlatformTypeForInfra(t *testing.T) {
+	testCases := []struct {
+		description string
+		status      map[string]interface{}
+		expected    configv1.PlatformType
+	}{
+		{
+			description: "platform only",
+			status:      map[string]interface{}{"platform": "AWS"},
+			expected:    configv1.AWSPlatformType,
+		},
+		{
+			description: "platformStatus only",
+			status:      map[string]interface{}{"platformStatus": map[string]interface{}{"type": "GCP"}},
+			expected:    configv1.GCPPlatformType,
+		},
+		{
+			description: "platformStatus preferred over platform",
+			status: map[string]interface{}{
+				"platform":       "AWS",
+				"platformStatus": map[string]interface{}{"type": "Azure"},
+			},
+			expected: configv1.AzurePlatformType,
+		},
+		{
+			description: "empty platformStatus",
+			status:      map[string]interface{}{"platform": "AWS", "platformStatus": nil},
+			expected:    configv1.AWSPlatformType,
+		},
+		{
+			description: "no status",
+			status:      nil,
+			expected:    "",
+		},
+	}
+	for _, tc := range testCases {
+		infra := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if tc.status != nil {
+			infra.Object["status"] = tc.status
+		}
+		if actual := platformTypeForInfra(infra); actual != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/sirupsen/logrus"
+)
+
+// zoneVisibility is the provider-agnostic visibility of the zones managed by
+// an ExternalDNS controller, derived from the zoneType of an ExternalDNS.
+type zoneVisibility string
+
+const (
+	publicZoneVisibility  zoneVisibility = "public"
+	privateZoneVisibility zoneVisibility = "private"
+	// anyZoneVisibility doesn't limit the visibility of managed zones.
+	anyZoneVisibility zoneVisibility = "any"
+)
+
+// provider translates provider-agnostic ExternalDNS configuration into
+// ExternalDNS controller args for a provider type.
+type provider interface {
+	// zoneVisibilityArgs returns the args limiting the ExternalDNS
+	// controller to zones of the given visibility.
+	zoneVisibilityArgs(visibility zoneVisibility) []string
+}
+
+// providers are the provider implementations keyed by provider type.
+var providers = map[operatorv1.ProviderType]provider{
+	operatorv1.AWSProvider:      awsProvider{},
+	operatorv1.AzureProvider:    azureProvider{},
+	operatorv1.GoogleProvider:   googleProvider{},
+	operatorv1.InMemoryProvider: inMemoryProvider{},
+	operatorv1.WebhookProvider:  webhookProvider{},
+}
+
+// awsProvider is the Route 53 provider.
+type awsProvider struct{}
+
+func (awsProvider) zoneVisibilityArgs(visibility zoneVisibility) []string {
+	switch visibility {
+	case publicZoneVisibility, privateZoneVisibility:
+		return []string{"--aws-zone-type=" + string(visibility)}
+	}
+	return nil
+}
+
+// azureProvider is the Azure DNS provider.
+type azureProvider struct{}
+
+// zoneVisibilityArgs returns no args since Azure DNS only hosts public
+// zones; Azure private zones are served by a separate provider.
+func (azureProvider) zoneVisibilityArgs(visibility zoneVisibility) []string {
+	return nil
+}
+
+// googleProvider is the Cloud DNS provider.
+type googleProvider struct{}
+
+func (googleProvider) zoneVisibilityArgs(visibility zoneVisibility) []string {
+	switch visibility {
+	case publicZoneVisibility, privateZoneVisibility:
+		return []string{"--google-zone-visibility=" + string(visibility)}
+	}
+	return nil
+}
+
+// inMemoryProvider is the in-memory provider used for testing.
+type inMemoryProvider struct{}
+
+// zoneVisibilityArgs returns no args since in-memory zones have no
+// visibility.
+func (inMemoryProvider) zoneVisibilityArgs(visibility zoneVisibility) []string {
+	return nil
+}
+
+// webhookProvider delegates to a webhook implementing the provider API.
+type webhookProvider struct{}
+
+// zoneVisibilityArgs returns no args since zone visibility is up to the
+// webhook.
+func (webhookProvider) zoneVisibilityArgs(visibility zoneVisibility) []string {
+	return nil
+}
+
+// zoneVisibilityForExternalDNS returns the visibility of the zones managed
+// by edns. A zoneFilter spanning the public and private zones of dnsConfig
+// needs both visibilities, since forcing one would exclude filtered zones.
+func zoneVisibilityForExternalDNS(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS) zoneVisibility {
+	if edns.Spec.ZoneType == nil || zoneFilterSpansZoneTypes(edns, dnsConfig) {
+		return anyZoneVisibility
+	}
+	switch *edns.Spec.ZoneType {
+	case operatorv1.PublicZoneType:
+		return publicZoneVisibility
+	case operatorv1.PrivateZoneType:
+		return privateZoneVisibility
+	}
+	return anyZoneVisibility
+}
+
+// awsPreferCNAME returns whether CNAME records should be created instead of
+// alias records for targets in zones of the given visibility. Zones of any
+// visibility prefer CNAME if either zone type does, since CNAME records
+// resolve in both while alias records may not.
+func awsPreferCNAME(types *operatorv1.AWSTargetRecordTypes, visibility zoneVisibility) bool {
+	if types == nil {
+		return false
+	}
+	switch visibility {
+	case publicZoneVisibility:
+		return types.Public == operatorv1.CNAMEAWSTargetRecordType
+	case privateZoneVisibility:
+		return types.Private == operatorv1.CNAMEAWSTargetRecordType
+	}
+	return types.Public == operatorv1.CNAMEAWSTargetRecordType || types.Private == operatorv1.CNAMEAWSTargetRecordType
+}
+
+// ZoneTypeDetector detects the visibility of a DNS zone in the provider.
+type ZoneTypeDetector interface {
+	// HostedZoneIsPrivate returns whether the zone with the given ID is a
+	// private zone, or an error if the zone can't be read.
+	HostedZoneIsPrivate(id string) (bool, error)
+}
+
+// effectiveZoneVisibility returns the visibility of the zones managed by
+// edns. When a ZoneTypeDetector is configured, the visibility of the zones
+// in the zoneFilter of an AWS edns takes precedence over its zoneType.
+func (r *reconciler) effectiveZoneVisibility(edns *operatorv1.ExternalDNS, dnsConfig *configv1.DNS) zoneVisibility {
+	declared := zoneVisibilityForExternalDNS(edns, dnsConfig)
+	if r.ZoneTypeDetector == nil || edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.AWSProvider {
+		return declared
+	}
+	detected, err := detectZoneVisibility(r.ZoneTypeDetector, edns.Spec.Provider.ZoneFilter)
+	if err != nil {
+		logrus.Warningf("failed to detect the zone type of externaldns %s, using the declared zone type: %v", edns.Name, err)
+		return declared
+	}
+	if len(detected) == 0 {
+		return declared
+	}
+	if detected != declared {
+		logrus.Warningf("zones of externaldns %s have %s visibility but the declared zone type implies %s; using %s",
+			edns.Name, detected, declared, detected)
+	}
+	return detected
+}
+
+// detectZoneVisibility returns the visibility of zones as reported by
+// detector: public or private if all zones agree, or any if they don't. An
+// empty visibility is returned when there are no zones or a zone is
+// selected by tags, since its visibility can't be known.
+func detectZoneVisibility(detector ZoneTypeDetector, zones []*configv1.DNSZone) (zoneVisibility, error) {
+	var visibility zoneVisibility
+	for _, z := range zones {
+		if z == nil || len(z.ID) == 0 {
+			return "", nil
+		}
+		private, err := detector.HostedZoneIsPrivate(z.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get zone %s: %v", z.ID, err)
+		}
+		v := publicZoneVisibility
+		if private {
+			v = privateZoneVisibility
+		}
+		switch {
+		case len(visibility) == 0:
+			visibility = v
+		case visibility != v:
+			return anyZoneVisibility, nil
+		}
+	}
+	return visibility, nil
+}
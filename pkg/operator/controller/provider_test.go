@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestZoneVisibilityArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		provider    operatorv1.ProviderType
+		visibility  zoneVisibility
+		expected    []string
+	}{
+		{
+			description: "aws private zones",
+			provider:    operatorv1.AWSProvider,
+			visibility:  privateZoneVisibility,
+			expected:    []string{"--aws-zone-type=private"},
+		},
+		{
+			description: "aws any zones",
+			provider:    operatorv1.AWSProvider,
+			visibility:  anyZoneVisibility,
+			expected:    nil,
+		},
+		{
+			description: "google public zones",
+			provider:    operatorv1.GoogleProvider,
+			visibility:  publicZoneVisibility,
+			expected:    []string{"--google-zone-visibility=public"},
+		},
+		{
+			description: "azure public zones",
+			provider:    operatorv1.AzureProvider,
+			visibility:  publicZoneVisibility,
+			expected:    nil,
+		},
+	}
+	for _, tc := range testCases {
+		if actual := providers[tc.provider].zoneVisibilityArgs(tc.visibility); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestAWSPreferCNAME(t *testing.T) {
+	publicCNAME := &operatorv1.AWSTargetRecordTypes{Public: operatorv1.CNAMEAWSTargetRecordType}
+	testCases := []struct {
+		description string
+		types       *operatorv1.AWSTargetRecordTypes
+		visibility  zoneVisibility
+		expected    bool
+	}{
+		{
+			description: "unset defaults to alias",
+			visibility:  publicZoneVisibility,
+			expected:    false,
+		},
+		{
+			description: "public zones prefer cname",
+			types:       publicCNAME,
+			visibility:  publicZoneVisibility,
+			expected:    true,
+		},
+		{
+			description: "private zones keep alias",
+			types:       publicCNAME,
+			visibility:  privateZoneVisibility,
+			expected:    false,
+		},
+		{
+			description: "any zones prefer cname if either zone type does",
+			types:       publicCNAME,
+			visibility:  anyZoneVisibility,
+			expected:    true,
+		},
+	}
+	for _, tc := range testCases {
+		if actual := awsPreferCNAME(tc.types, tc.visibility); actual != tc.expected {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+type fakeZoneTypeDetector map[string]bool
+
+func (f fakeZoneTypeDetector) HostedZoneIsPrivate(id string) (bool, error) {
+	private, ok := f[id]
+	if !ok {
+		return false, fmt.Errorf("NoSuchHostedZone")
+	}
+	return private, nil
+}
+
+func TestDetectZoneVisibility(t *testing.T) {
+	detector := fakeZoneTypeDetector{"PUB1": false, "PUB2": false, "PRIV": true}
+	testCases := []struct {
+		description string
+		zones       []*configv1.DNSZone
+		expected    zoneVisibility
+		expectErr   bool
+	}{
+		{
+			description: "no zones",
+		},
+		{
+			description: "public zones",
+			zones:       []*configv1.DNSZone{{ID: "PUB1"}, {ID: "PUB2"}},
+			expected:    publicZoneVisibility,
+		},
+		{
+			description: "private zone",
+			zones:       []*configv1.DNSZone{{ID: "PRIV"}},
+			expected:    privateZoneVisibility,
+		},
+		{
+			description: "mixed zones",
+			zones:       []*configv1.DNSZone{{ID: "PUB1"}, {ID: "PRIV"}},
+			expected:    anyZoneVisibility,
+		},
+		{
+			description: "zone selected by tags",
+			zones:       []*configv1.DNSZone{{ID: "PRIV"}, {Tags: map[string]string{"Name": "private"}}},
+		},
+		{
+			description: "unreadable zone",
+			zones:       []*configv1.DNSZone{{ID: "MISSING"}},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		actual, err := detectZoneVisibility(detector, tc.zones)
+		switch {
+		case tc.expectErr && err == nil:
+			t.Errorf("%q: expected an error", tc.description)
+		case !tc.expectErr && err != nil:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case actual != tc.expected:
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	"github.com/sirupsen/logrus"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ensureExternalDNSCRDSourceRBAC ensures the operand of edns can read
+// DNSEndpoints when the crd source is used. Access is granted within
+// spec.namespace through a RoleBinding, or cluster-wide through a
+// ClusterRoleBinding when spec.namespace is empty. Bindings that are no
+// longer desired are removed.
+func (r *reconciler) ensureExternalDNSCRDSourceRBAC(edns *operatorv1.ExternalDNS) error {
+	var desiredNamespace string
+	wantClusterBinding := false
+	if hasSourceType(edns, operatorv1.CRDType) {
+		for _, cr := range []*rbacv1.ClusterRole{
+			manifests.ExternalDNSCRDSourceClusterRole(),
+			manifests.ExternalDNSCRDSourceReadOnlyClusterRole(),
+		} {
+			if err := r.ensureCRDSourceClusterRole(cr); err != nil {
+				return err
+			}
+		}
+		if len(edns.Spec.Namespace) != 0 {
+			desiredNamespace = edns.Spec.Namespace
+			if err := r.ensureCRDSourceRoleBinding(edns); err != nil {
+				return err
+			}
+		} else {
+			wantClusterBinding = true
+			if err := r.ensureCRDSourceClusterRoleBinding(edns); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !wantClusterBinding {
+		crb := &rbacv1.ClusterRoleBinding{}
+		crb.Name = ExternalDNSCRDSourceBindingName(edns)
+		if err := r.kclient.Delete(context.TODO(), crb); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete crd source cluster role binding %s: %v", crb.Name, err)
+		}
+	}
+	return r.deleteSourceRoleBindings(edns, ExternalDNSCRDSourceBindingName(edns), desiredNamespace)
+}
+
+// ensureCRDSourceClusterRole creates the desired crd source ClusterRole if
+// it does not already exist, and updates its rules if they have changed.
+func (r *reconciler) ensureCRDSourceClusterRole(desired *rbacv1.ClusterRole) error {
+	current := &rbacv1.ClusterRole{}
+	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get crd source cluster role %s: %v", desired.Name, err)
+		}
+		if err := r.kclient.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create crd source cluster role %s: %v", desired.Name, err)
+		}
+		logrus.Infof("created crd source cluster role: %s", desired.Name)
+		return nil
+	}
+	if reflect.DeepEqual(current.Rules, desired.Rules) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Rules = desired.Rules
+	if err := r.kclient.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update crd source cluster role %s: %v", updated.Name, err)
+	}
+	logrus.Infof("updated crd source cluster role: %s", updated.Name)
+	return nil
+}
+
+// ensureExternalDNSCRDSourceRBACDeleted removes all crd source bindings
+// of edns.
+func (r *reconciler) ensureExternalDNSCRDSourceRBACDeleted(edns *operatorv1.ExternalDNS) error {
+	crb := &rbacv1.ClusterRoleBinding{}
+	crb.Name = ExternalDNSCRDSourceBindingName(edns)
+	if err := r.kclient.Delete(context.TODO(), crb); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete crd source cluster role binding %s: %v", crb.Name, err)
+	}
+	return r.deleteSourceRoleBindings(edns, ExternalDNSCRDSourceBindingName(edns), "")
+}
+
+// ensureCRDSourceRoleBinding creates the crd source RoleBinding of edns in
+// spec.namespace if it does not already exist, and replaces it if it binds
+// the wrong crd source ClusterRole.
+func (r *reconciler) ensureCRDSourceRoleBinding(edns *operatorv1.ExternalDNS) error {
+	rb := manifests.ExternalDNSCRDSourceRoleBinding()
+	rb.Name = ExternalDNSCRDSourceBindingName(edns)
+	rb.Namespace = edns.Spec.Namespace
+	// Only a binding in the namespace of edns can be owned by it.
+	setExternalDNSOwnerReference(rb, edns)
+	rb.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	rb.Subjects[0].Namespace = ExternalDNSDeploymentNamespacedName(r.OperandNamespace, edns).Namespace
+	rb.RoleRef.Name = crdSourceClusterRoleName(edns)
+	current := &rbacv1.RoleBinding{}
+	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Namespace: rb.Namespace, Name: rb.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get crd source role binding %s/%s: %v", rb.Namespace, rb.Name, err)
+		}
+	} else if current.RoleRef == rb.RoleRef {
+		return nil
+	} else {
+		// The role of a binding can't be changed, so replace the binding.
+		if err := r.kclient.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete crd source role binding %s/%s: %v", current.Namespace, current.Name, err)
+		}
+		logrus.Infof("deleted crd source role binding %s/%s to change its role", current.Namespace, current.Name)
+	}
+	if err := r.kclient.Create(context.TODO(), rb); err != nil {
+		return fmt.Errorf("failed to create crd source role binding %s/%s: %v", rb.Namespace, rb.Name, err)
+	}
+	logrus.Infof("created crd source role binding: %s/%s", rb.Namespace, rb.Name)
+	return nil
+}
+
+// ensureCRDSourceClusterRoleBinding creates the crd source ClusterRoleBinding
+// of edns if it does not already exist, and replaces it if it binds the
+// wrong crd source ClusterRole.
+func (r *reconciler) ensureCRDSourceClusterRoleBinding(edns *operatorv1.ExternalDNS) error {
+	crb := manifests.ExternalDNSCRDSourceClusterRoleBinding()
+	crb.Name = ExternalDNSCRDSourceBindingName(edns)
+	crb.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	crb.Subjects[0].Namespace = ExternalDNSDeploymentNamespacedName(r.OperandNamespace, edns).Namespace
+	crb.RoleRef.Name = crdSourceClusterRoleName(edns)
+	current := &rbacv1.ClusterRoleBinding{}
+	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Name: crb.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get crd source cluster role binding %s: %v", crb.Name, err)
+		}
+	} else if current.RoleRef == crb.RoleRef {
+		return nil
+	} else {
+		// The role of a binding can't be changed, so replace the binding.
+		if err := r.kclient.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete crd source cluster role binding %s: %v", current.Name, err)
+		}
+		logrus.Infof("deleted crd source cluster role binding %s to change its role", current.Name)
+	}
+	if err := r.kclient.Create(context.TODO(), crb); err != nil {
+		return fmt.Errorf("failed to create crd source cluster role binding %s: %v", crb.Name, err)
+	}
+	logrus.Infof("created crd source cluster role binding: %s", crb.Name)
+	return nil
+}
+
+// crdSourceClusterRoleName returns the name of the crd source ClusterRole
+// bound for edns, which only grants access to DNSEndpoint status when the
+// operand of edns updates it.
+func crdSourceClusterRoleName(edns *operatorv1.ExternalDNS) string {
+	if edns.Spec.DisableCRDSourceStatusUpdates {
+		return manifests.ExternalDNSCRDSourceReadOnlyClusterRole().Name
+	}
+	return manifests.ExternalDNSCRDSourceClusterRole().Name
+}
+
+// deleteSourceRoleBindings deletes the source RoleBindings of edns with the
+// given name in any namespace other than keepNamespace.
+func (r *reconciler) deleteSourceRoleBindings(edns *operatorv1.ExternalDNS, name, keepNamespace string) error {
+	rbs := &rbacv1.RoleBindingList{}
+	if err := r.kclient.List(context.TODO(), rbs, kclient.MatchingLabels(map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	})); err != nil {
+		return fmt.Errorf("failed to list source role bindings for externaldns %s: %v", edns.Name, err)
+	}
+	for i := range rbs.Items {
+		rb := &rbs.Items[i]
+		if rb.Name != name || rb.Namespace == keepNamespace {
+			continue
+		}
+		if err := r.kclient.Delete(context.TODO(), rb); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete source role binding %s/%s: %v", rb.Namespace, rb.Name, err)
+		}
+		logrus.Infof("deleted source role binding: %s/%s", rb.Namespace, rb.Name)
+	}
+	return nil
+}
+
+// contourHTTPProxyCRDName is the name of the Contour HTTPProxy CRD.
+const contourHTTPProxyCRDName = "httpproxies.projectcontour.io"
+
+// ensureExternalDNSContourHTTPProxySourceRBAC ensures the operand of edns can
+// read HTTPProxies when the contour-httpproxy source is used and the
+// HTTPProxy CRD is installed, and removes the access otherwise. Access is
+// granted within spec.namespace through a RoleBinding, or cluster-wide
+// through a ClusterRoleBinding when spec.namespace is empty.
+func (r *reconciler) ensureExternalDNSContourHTTPProxySourceRBAC(edns *operatorv1.ExternalDNS) error {
+	if !hasSourceType(edns, operatorv1.ContourHTTPProxyType) {
+		return r.ensureExternalDNSContourHTTPProxySourceRBACDeleted(edns)
+	}
+	installed, err := r.isCRDInstalled(contourHTTPProxyCRDName)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		logrus.Infof("crd %s is not installed; not granting externaldns %s access to httpproxies", contourHTTPProxyCRDName, edns.Name)
+		return r.ensureExternalDNSContourHTTPProxySourceRBACDeleted(edns)
+	}
+
+	cr := manifests.ExternalDNSContourHTTPProxySourceClusterRole()
+	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Name: cr.Name}, cr); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get contour httpproxy source cluster role %s: %v", cr.Name, err)
+		}
+		if err := r.kclient.Create(context.TODO(), cr); err != nil {
+			return fmt.Errorf("failed to create contour httpproxy source cluster role %s: %v", cr.Name, err)
+		}
+		logrus.Infof("created contour httpproxy source cluster role: %s", cr.Name)
+	}
+
+	crb := desiredContourHTTPProxySourceClusterRoleBinding(r.OperandNamespace, edns)
+	if len(edns.Spec.Namespace) != 0 {
+		rb := desiredContourHTTPProxySourceRoleBinding(r.OperandNamespace, edns)
+		if err := r.kclient.Get(context.TODO(), types.NamespacedName{Namespace: rb.Namespace, Name: rb.Name}, &rbacv1.RoleBinding{}); err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to get contour httpproxy source role binding %s/%s: %v", rb.Namespace, rb.Name, err)
+			}
+			if err := r.kclient.Create(context.TODO(), rb); err != nil {
+				return fmt.Errorf("failed to create contour httpproxy source role binding %s/%s: %v", rb.Namespace, rb.Name, err)
+			}
+			logrus.Infof("created contour httpproxy source role binding: %s/%s", rb.Namespace, rb.Name)
+		}
+		if err := r.kclient.Delete(context.TODO(), crb); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete contour httpproxy source cluster role binding %s: %v", crb.Name, err)
+		}
+	} else if err := r.kclient.Get(context.TODO(), types.NamespacedName{Name: crb.Name}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get contour httpproxy source cluster role binding %s: %v", crb.Name, err)
+		}
+		if err := r.kclient.Create(context.TODO(), crb); err != nil {
+			return fmt.Errorf("failed to create contour httpproxy source cluster role binding %s: %v", crb.Name, err)
+		}
+		logrus.Infof("created contour httpproxy source cluster role binding: %s", crb.Name)
+	}
+	return r.deleteSourceRoleBindings(edns, ExternalDNSContourHTTPProxySourceBindingName(edns), edns.Spec.Namespace)
+}
+
+// ensureExternalDNSContourHTTPProxySourceRBACDeleted removes the contour
+// httpproxy source bindings of edns.
+func (r *reconciler) ensureExternalDNSContourHTTPProxySourceRBACDeleted(edns *operatorv1.ExternalDNS) error {
+	crb := &rbacv1.ClusterRoleBinding{}
+	crb.Name = ExternalDNSContourHTTPProxySourceBindingName(edns)
+	if err := r.kclient.Delete(context.TODO(), crb); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete contour httpproxy source cluster role binding %s: %v", crb.Name, err)
+	}
+	return r.deleteSourceRoleBindings(edns, crb.Name, "")
+}
+
+// desiredContourHTTPProxySourceClusterRoleBinding returns the
+// ClusterRoleBinding granting the operand of edns access to HTTPProxies.
+func desiredContourHTTPProxySourceClusterRoleBinding(operandNamespace string, edns *operatorv1.ExternalDNS) *rbacv1.ClusterRoleBinding {
+	crb := manifests.ExternalDNSContourHTTPProxySourceClusterRoleBinding()
+	crb.Name = ExternalDNSContourHTTPProxySourceBindingName(edns)
+	crb.Labels = map[string]string{
+		manifests.OwningExternalDNSLabel: edns.Name,
+	}
+	crb.Subjects[0].Namespace = ExternalDNSDeploymentNamespacedName(operandNamespace, edns).Namespace
+	return crb
+}
+
+// desiredContourHTTPProxySourceRoleBinding returns the RoleBinding granting
+// the operand of edns access to the HTTPProxies of spec.namespace.
+func desiredContourHTTPProxySourceRoleBinding(operandNamespace string, edns *operatorv1.ExternalDNS) *rbacv1.RoleBinding {
+	crb := desiredContourHTTPProxySourceClusterRoleBinding(operandNamespace, edns)
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      crb.Name,
+			Namespace: edns.Spec.Namespace,
+			Labels:    crb.Labels,
+		},
+		Subjects: crb.Subjects,
+		RoleRef:  crb.RoleRef,
+	}
+	// Only a binding in the namespace of edns can be owned by it.
+	setExternalDNSOwnerReference(rb, edns)
+	return rb
+}
+
+// isCRDInstalled returns true if the CustomResourceDefinition of the given
+// name exists.
+func (r *reconciler) isCRDInstalled(name string) (bool, error) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "apiextensions.k8s.io",
+		Version: "v1beta1",
+		Kind:    "CustomResourceDefinition",
+	})
+	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Name: name}, crd); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get crd %s: %v", name, err)
+	}
+	return true, nil
+}
+
+// hasSourceType returns true if edns is configured with the given source type.
+func hasSourceType(edns *operatorv1.ExternalDNS, sourceType operatorv1.SourceType) bool {
+	for _, s := range edns.Spec.Sources {
+		if s != nil && *s == sourceType {
+			return true
+		}
+	}
+	return false
+}
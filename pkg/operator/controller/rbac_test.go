@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDesiredContourHTTPProxySourceClusterRoleBinding(t *testing.T) {
+	edns := &operatorv1.ExternalDNS{ObjectMeta: metav1.ObjectMeta{Name: "mine"}}
+	crb := desiredContourHTTPProxySourceClusterRoleBinding(DefaultOperandNamespace, edns)
+	if expected := "externaldns-contour-httpproxy-source-mine"; crb.Name != expected {
+		t.Errorf("expected name %q, got %q", expected, crb.Name)
+	}
+	if owner := crb.Labels[manifests.OwningExternalDNSLabel]; owner != edns.Name {
+		t.Errorf("expected owning externaldns label %q, got %q", edns.Name, owner)
+	}
+	if expected := manifests.ExternalDNSContourHTTPProxySourceClusterRole().Name; crb.RoleRef.Name != expected {
+		t.Errorf("expected role ref %q, got %q", expected, crb.RoleRef.Name)
+	}
+	if expected := ExternalDNSDeploymentNamespacedName(DefaultOperandNamespace, edns).Namespace; crb.Subjects[0].Namespace != expected {
+		t.Errorf("expected subject namespace %q, got %q", expected, crb.Subjects[0].Namespace)
+	}
+}
+
+func TestCRDSourceClusterRoleName(t *testing.T) {
+	edns := &operatorv1.ExternalDNS{}
+	if expected := manifests.ExternalDNSCRDSourceClusterRole().Name; crdSourceClusterRoleName(edns) != expected {
+		t.Errorf("expected %q, got %q", expected, crdSourceClusterRoleName(edns))
+	}
+	edns.Spec.DisableCRDSourceStatusUpdates = true
+	if expected := manifests.ExternalDNSCRDSourceReadOnlyClusterRole().Name; crdSourceClusterRoleName(edns) != expected {
+		t.Errorf("expected %q, got %q", expected, crdSourceClusterRoleName(edns))
+	}
+}
+
+func TestDesiredContourHTTPProxySourceRoleBinding(t *testing.T) {
+	edns := &operatorv1.ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "team-a"},
+		Spec:       operatorv1.ExternalDNSSpec{Namespace: "team-a"},
+	}
+	rb := desiredContourHTTPProxySourceRoleBinding(DefaultOperandNamespace, edns)
+	if rb.Namespace != "team-a" || rb.Name != ExternalDNSContourHTTPProxySourceBindingName(edns) {
+		t.Errorf("expected role binding team-a/%s, got %s/%s", ExternalDNSContourHTTPProxySourceBindingName(edns), rb.Namespace, rb.Name)
+	}
+	if owner := rb.Labels[manifests.OwningExternalDNSLabel]; owner != edns.Name {
+		t.Errorf("expected owning externaldns label %q, got %q", edns.Name, owner)
+	}
+	if expected := manifests.ExternalDNSContourHTTPProxySourceClusterRole().Name; rb.RoleRef.Name != expected {
+		t.Errorf("expected role ref %q, got %q", expected, rb.RoleRef.Name)
+	}
+	if expected := ExternalDNSDeploymentNamespacedName(DefaultOperandNamespace, edns).Namespace; rb.Subjects[0].Namespace != expected {
+		t.Errorf("expected subject namespace %q, got %q", expected, rb.Subjects[0].Namespace)
+	}
+	if len(rb.OwnerReferences) != 1 {
+		t.Errorf("expected the role binding to be owned by the externaldns in its namespace, got %v", rb.OwnerReferences)
+	}
+}
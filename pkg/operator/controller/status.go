@@ -0,0 +1,554 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	"github.com/danehans/external-dns-operator/pkg/util/slice"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// syncExternalDNSStatus merges conditions into the status of edns and
+// updates the status if anything changed.
+func (r *reconciler) syncExternalDNSStatus(edns *operatorv1.ExternalDNS, conditions []operatorv1.OperatorCondition) error {
+	updated := edns.DeepCopy()
+	for _, c := range conditions {
+		updated.Status.Conditions = setExternalDNSCondition(updated.Status.Conditions, c)
+	}
+	if reflect.DeepEqual(edns.Status, updated.Status) {
+		return nil
+	}
+	if err := r.kclient.Status().Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update status of externaldns %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	return nil
+}
+
+// setExternalDNSCondition adds condition to conditions, replacing any
+// existing condition of the same type. The lastTransitionTime is only
+// updated when the status of the condition changes.
+func setExternalDNSCondition(conditions []operatorv1.OperatorCondition, condition operatorv1.OperatorCondition) []operatorv1.OperatorCondition {
+	now := metav1.Now()
+	for i := range conditions {
+		if conditions[i].Type != condition.Type {
+			continue
+		}
+		if conditions[i].Status == condition.Status {
+			condition.LastTransitionTime = conditions[i].LastTransitionTime
+		} else {
+			condition.LastTransitionTime = now
+		}
+		conditions[i] = condition
+		return conditions
+	}
+	condition.LastTransitionTime = now
+	return append(conditions, condition)
+}
+
+// findExternalDNSCondition returns the condition of the given type from
+// conditions, or nil if it doesn't exist.
+func findExternalDNSCondition(conditions []operatorv1.OperatorCondition, conditionType string) *operatorv1.OperatorCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// computeZoneOverlapCondition reports whether other externaldnses manage a
+// zone in the zoneFilter of edns, listing the overlapping externaldnses.
+func (r *reconciler) computeZoneOverlapCondition(edns *operatorv1.ExternalDNS) (*operatorv1.OperatorCondition, error) {
+	dnses := &operatorv1.ExternalDNSList{}
+	if err := r.kclient.List(context.TODO(), dnses, kclient.InNamespace(r.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list externaldnses: %v", err)
+	}
+
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.ZoneOverlapConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "NoOverlap",
+	}
+	if overlapping := overlappingExternalDNSes(edns, dnses.Items); len(overlapping) != 0 {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "OverlappingZoneFilter"
+		condition.Message = fmt.Sprintf("ExternalDNSes %s also manage zones in the zoneFilter; records may be managed by more than one owner.",
+			strings.Join(overlapping, ", "))
+	}
+	return condition, nil
+}
+
+// overlappingExternalDNSes returns the names of the externaldnses in dnses,
+// other than edns, of the same provider whose zoneFilter shares a zone with
+// the zoneFilter of edns.
+func overlappingExternalDNSes(edns *operatorv1.ExternalDNS, dnses []operatorv1.ExternalDNS) []string {
+	overlapping := []string{}
+	for i := range dnses {
+		other := &dnses[i]
+		if other.Name == edns.Name || other.DeletionTimestamp != nil {
+			continue
+		}
+		if other.Status.ProviderType == nil || edns.Status.ProviderType == nil ||
+			*other.Status.ProviderType != *edns.Status.ProviderType {
+			continue
+		}
+	zones:
+		for _, a := range edns.Spec.Provider.ZoneFilter {
+			for _, b := range other.Spec.Provider.ZoneFilter {
+				if dnsZonesEqual(a, b) {
+					overlapping = append(overlapping, other.Name)
+					break zones
+				}
+			}
+		}
+	}
+	return overlapping
+}
+
+// ZoneChecker checks whether a DNS zone exists in the provider.
+type ZoneChecker interface {
+	// HostedZoneExists returns whether the zone with the given ID exists,
+	// or an error if the zone can't be read.
+	HostedZoneExists(id string) (bool, error)
+}
+
+// computeZonesAvailableCondition reports whether each zone ID in the
+// zoneFilter of an AWS edns exists and is accessible. A nil condition is
+// returned when no ZoneChecker is configured or the check doesn't apply.
+func (r *reconciler) computeZonesAvailableCondition(edns *operatorv1.ExternalDNS) *operatorv1.OperatorCondition {
+	if r.ZoneChecker == nil || edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.AWSProvider {
+		return nil
+	}
+	return zonesAvailableCondition(r.ZoneChecker, edns.Spec.Provider.ZoneFilter)
+}
+
+// zonesAvailableCondition checks the zone IDs of zones with checker. Zones
+// selected by tags are skipped, since the operand resolves them itself.
+func zonesAvailableCondition(checker ZoneChecker, zones []*configv1.DNSZone) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.ZonesAvailableConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "ZonesFound",
+	}
+	missing := []string{}
+	inaccessible := []string{}
+	for _, z := range zones {
+		if z == nil || len(z.ID) == 0 {
+			continue
+		}
+		exists, err := checker.HostedZoneExists(z.ID)
+		switch {
+		case err != nil:
+			inaccessible = append(inaccessible, fmt.Sprintf("%s (%v)", z.ID, err))
+		case !exists:
+			missing = append(missing, z.ID)
+		}
+	}
+	messages := []string{}
+	if len(missing) != 0 {
+		condition.Reason = "ZoneNotFound"
+		messages = append(messages, fmt.Sprintf("Zones %s don't exist.", strings.Join(missing, ", ")))
+	}
+	if len(inaccessible) != 0 {
+		condition.Reason = "ZoneInaccessible"
+		messages = append(messages, fmt.Sprintf("Zones %s couldn't be read.", strings.Join(inaccessible, ", ")))
+	}
+	if len(messages) != 0 {
+		condition.Status = operatorv1.ConditionFalse
+		condition.Message = strings.Join(messages, " ")
+	}
+	return condition
+}
+
+// imagePullFailureReasons are the waiting reasons of a container whose image
+// can't be pulled.
+var imagePullFailureReasons = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName"}
+
+// computeOperandImageUnavailableCondition reports whether a pod of the operand
+// deployment of edns is failing to pull an image, with the pull error.
+func (r *reconciler) computeOperandImageUnavailableCondition(edns *operatorv1.ExternalDNS) (*operatorv1.OperatorCondition, error) {
+	pods := &corev1.PodList{}
+	namespace := ExternalDNSDeploymentNamespacedName(r.OperandNamespace, edns).Namespace
+	selector := ExternalDNSDeploymentPodSelector(edns).MatchLabels
+	if err := r.kclient.List(context.TODO(), pods, kclient.InNamespace(namespace), kclient.MatchingLabels(selector)); err != nil {
+		return nil, fmt.Errorf("failed to list pods of externaldns %s: %v", edns.Name, err)
+	}
+
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.OperandImageUnavailableConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "ImagesAvailable",
+	}
+	for _, pod := range pods.Items {
+		statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+		for _, s := range statuses {
+			if s.State.Waiting == nil || !slice.ContainsString(imagePullFailureReasons, s.State.Waiting.Reason) {
+				continue
+			}
+			condition.Status = operatorv1.ConditionTrue
+			condition.Reason = s.State.Waiting.Reason
+			condition.Message = fmt.Sprintf("Pod %s/%s failed to pull image %s for container %s: %s",
+				pod.Namespace, pod.Name, s.Image, s.Name, s.State.Waiting.Message)
+			return condition, nil
+		}
+	}
+	return condition, nil
+}
+
+// operandPausedCondition returns the OperandPaused condition for the given
+// operand deployment, which may be nil.
+func operandPausedCondition(deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.OperandPausedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "NotPaused",
+	}
+	if deployment != nil && deployment.Spec.Paused {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "DeploymentPaused"
+		condition.Message = fmt.Sprintf("Deployment %s/%s is paused; configuration changes are applied once it is resumed.",
+			deployment.Namespace, deployment.Name)
+	}
+	return condition
+}
+
+// operandRolloutFailedCondition returns the OperandRolloutFailed condition
+// for the given operand deployment, which may be nil.
+func operandRolloutFailedCondition(deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.OperandRolloutFailedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "RolloutProgressing",
+	}
+	if deployment == nil {
+		return condition
+	}
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse && c.Reason == "ProgressDeadlineExceeded" {
+			condition.Status = operatorv1.ConditionTrue
+			condition.Reason = "ProgressDeadlineExceeded"
+			condition.Message = fmt.Sprintf("Deployment %s/%s: %s", deployment.Namespace, deployment.Name, c.Message)
+		}
+	}
+	return condition
+}
+
+// progressingCondition returns the Progressing condition for the given
+// operand deployment, which may be nil. The rollout is underway until the
+// deployment controller observed the latest generation and all the replicas
+// are updated and available, unless it exceeded its progress deadline.
+func progressingCondition(deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.ProgressingConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if deployment == nil {
+		condition.Reason = "NoDeployment"
+		return condition
+	}
+	if failed := operandRolloutFailedCondition(deployment); failed.Status == operatorv1.ConditionTrue {
+		condition.Reason = failed.Reason
+		condition.Message = failed.Message
+		return condition
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	var message string
+	switch {
+	case status.ObservedGeneration < deployment.Generation:
+		message = "waiting for the deployment spec update to be observed"
+	case status.UpdatedReplicas < replicas:
+		message = fmt.Sprintf("%d of %d replicas updated", status.UpdatedReplicas, replicas)
+	case status.Replicas > status.UpdatedReplicas:
+		message = fmt.Sprintf("%d old replicas pending termination", status.Replicas-status.UpdatedReplicas)
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		message = fmt.Sprintf("%d of %d updated replicas available", status.AvailableReplicas, status.UpdatedReplicas)
+	default:
+		return condition
+	}
+	condition.Status = operatorv1.ConditionTrue
+	condition.Reason = "RolloutInProgress"
+	condition.Message = fmt.Sprintf("Deployment %s/%s: %s.", deployment.Namespace, deployment.Name, message)
+	return condition
+}
+
+// availableCondition returns the Available condition for the given operand
+// deployment, which may be nil.
+func availableCondition(deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.AvailableConditionType,
+		Status: operatorv1.ConditionFalse,
+	}
+	switch {
+	case deployment == nil:
+		condition.Reason = "NoDeployment"
+	case deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0:
+		condition.Reason = "ScaledDown"
+		condition.Message = fmt.Sprintf("Deployment %s/%s is scaled to 0 replicas.", deployment.Namespace, deployment.Name)
+	case deployment.Status.AvailableReplicas == 0:
+		condition.Reason = "DeploymentUnavailable"
+		condition.Message = fmt.Sprintf("Deployment %s/%s has no available replicas.", deployment.Namespace, deployment.Name)
+	default:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "DeploymentAvailable"
+	}
+	return condition
+}
+
+// externalDNSDegradedCondition returns the Degraded condition of edns,
+// reporting whether spec.baseDomain was changed after it was published to
+// status, or whether the pods of the given operand deployment, which may be
+// nil, can't be created or rolled out.
+func externalDNSDegradedCondition(edns *operatorv1.ExternalDNS, deployment *appsv1.Deployment) *operatorv1.OperatorCondition {
+	if condition := baseDomainChangedCondition(edns); condition != nil {
+		return condition
+	}
+	return degradedCondition(deployment, nil)
+}
+
+// jobFailedCondition returns the Failed condition of the given Job, or nil
+// if the Job hasn't failed.
+func jobFailedCondition(job *batchv1.Job) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		if c := &job.Status.Conditions[i]; c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return c
+		}
+	}
+	return nil
+}
+
+// jobComplete returns whether the given Job ran to completion.
+func jobComplete(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// jobProgressingCondition returns the Progressing condition for the given
+// Job running the operand once, which may be nil. The run is underway until
+// the Job completes or fails.
+func jobProgressingCondition(job *batchv1.Job) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.ProgressingConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	switch {
+	case job == nil:
+		condition.Reason = "NoJob"
+	case jobFailedCondition(job) != nil:
+		condition.Reason = "JobFailed"
+		condition.Message = fmt.Sprintf("Job %s/%s: %s", job.Namespace, job.Name, jobFailedCondition(job).Message)
+	case !jobComplete(job):
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "JobRunning"
+		condition.Message = fmt.Sprintf("Job %s/%s: %d active pods.", job.Namespace, job.Name, job.Status.Active)
+	}
+	return condition
+}
+
+// jobAvailableCondition returns the Available condition for the given Job
+// running the operand once, which may be nil. The operand is available
+// while a pod of the Job runs and once the Job completed.
+func jobAvailableCondition(job *batchv1.Job) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.AvailableConditionType,
+		Status: operatorv1.ConditionFalse,
+	}
+	switch {
+	case job == nil:
+		condition.Reason = "NoJob"
+	case jobFailedCondition(job) != nil:
+		condition.Reason = "JobFailed"
+		condition.Message = fmt.Sprintf("Job %s/%s: %s", job.Namespace, job.Name, jobFailedCondition(job).Message)
+	case jobComplete(job):
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "JobComplete"
+	case job.Status.Active == 0:
+		condition.Reason = "JobUnavailable"
+		condition.Message = fmt.Sprintf("Job %s/%s has no active pods.", job.Namespace, job.Name)
+	default:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "JobRunning"
+	}
+	return condition
+}
+
+// baseDomainChangedCondition returns a Degraded condition if spec.baseDomain
+// of edns differs from the immutable base domain published to status, which
+// the ExternalDNS controller keeps using. A nil condition is returned if the
+// base domain is unchanged.
+func baseDomainChangedCondition(edns *operatorv1.ExternalDNS) *operatorv1.OperatorCondition {
+	if !IsStatusBaseDomainSet(edns) || len(edns.Spec.BaseDomain) == 0 || edns.Spec.BaseDomain == edns.Status.BaseDomain {
+		return nil
+	}
+	return &operatorv1.OperatorCondition{
+		Type:   operatorv1.DegradedConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "BaseDomainChanged",
+		Message: fmt.Sprintf("spec.baseDomain %q differs from the immutable base domain %q, which is still in use. "+
+			"Revert spec.baseDomain, or create a new ExternalDNS for the new base domain.", edns.Spec.BaseDomain, edns.Status.BaseDomain),
+	}
+}
+
+// duplicateBaseDomainCondition returns a Degraded condition naming the
+// externaldns whose published base domain of the same zone type conflicts
+// with domain, so status.baseDomain isn't set.
+func duplicateBaseDomainCondition(domain string, conflict *operatorv1.ExternalDNS) *operatorv1.OperatorCondition {
+	return &operatorv1.OperatorCondition{
+		Type:   operatorv1.DegradedConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "DuplicateBaseDomain",
+		Message: fmt.Sprintf("Base domain %q is already in use by ExternalDNS %s/%s of the same zone type. "+
+			"Set a different spec.baseDomain or zoneType.", domain, conflict.Namespace, conflict.Name),
+	}
+}
+
+// degradedCondition returns the Degraded condition for the given operand
+// deployment, which may be nil, and the error ensuring it, if any.
+func degradedCondition(deployment *appsv1.Deployment, ensureErr error) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.DegradedConditionType,
+		Status: operatorv1.ConditionTrue,
+	}
+	if ensureErr != nil {
+		condition.Reason = "DeploymentReconcileFailed"
+		condition.Message = ensureErr.Error()
+		return condition
+	}
+	if deployment != nil {
+		for _, c := range deployment.Status.Conditions {
+			if c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue {
+				condition.Reason = "ReplicaFailure"
+				condition.Message = fmt.Sprintf("Deployment %s/%s: %s", deployment.Namespace, deployment.Name, c.Message)
+				return condition
+			}
+		}
+		if failed := operandRolloutFailedCondition(deployment); failed.Status == operatorv1.ConditionTrue {
+			condition.Reason = failed.Reason
+			condition.Message = failed.Message
+			return condition
+		}
+	}
+	condition.Status = operatorv1.ConditionFalse
+	condition.Reason = "AsExpected"
+	return condition
+}
+
+// invalidConfigurationCondition returns a Degraded condition reporting the
+// given validation error of the spec of an externaldns.
+func invalidConfigurationCondition(validationErr error) *operatorv1.OperatorCondition {
+	return &operatorv1.OperatorCondition{
+		Type:    operatorv1.DegradedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "InvalidConfiguration",
+		Message: validationErr.Error(),
+	}
+}
+
+// experimentalArgsInUseCondition reports whether the operand of edns runs
+// with spec.experimentalArgs.
+func experimentalArgsInUseCondition(edns *operatorv1.ExternalDNS) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.ExperimentalArgsInUseConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "NoExperimentalArgs",
+	}
+	if len(edns.Spec.ExperimentalArgs) != 0 {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "ExperimentalArgs"
+		condition.Message = fmt.Sprintf("The ExternalDNS controller runs with unsupported experimental args: %s.",
+			strings.Join(edns.Spec.ExperimentalArgs, " "))
+	}
+	return condition
+}
+
+// defaultExcessReplicasThreshold is the number of ExternalDNS controller
+// replicas above which the ExcessReplicas condition is reported when
+// spec.excessReplicasThreshold is unset.
+const defaultExcessReplicasThreshold int32 = 1
+
+// excessReplicasCondition reports whether spec.replicas or spec.autoscaling
+// of edns request more ExternalDNS controllers than its excess replicas
+// threshold, and guides towards sharding the records across several
+// ExternalDNSes. The replicas are still run, as they are valid.
+func excessReplicasCondition(edns *operatorv1.ExternalDNS) *operatorv1.OperatorCondition {
+	condition := &operatorv1.OperatorCondition{
+		Type:   operatorv1.ExcessReplicasConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	var requested int32
+	if edns.Spec.Replicas != nil {
+		requested = *edns.Spec.Replicas
+	}
+	if as := edns.Spec.Autoscaling; as != nil && as.MaxReplicas > requested {
+		requested = as.MaxReplicas
+	}
+	threshold := defaultExcessReplicasThreshold
+	if edns.Spec.ExcessReplicasThreshold != nil {
+		threshold = *edns.Spec.ExcessReplicasThreshold
+	}
+	if requested > threshold {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "ExcessReplicas"
+		condition.Message = fmt.Sprintf("%d replicas were requested, more than the threshold of %d. "+
+			"Only the replica holding the lease of txt owner id %q writes records, so the other replicas add availability but not throughput. "+
+			"To spread the load, shard the records across several ExternalDNSes with disjoint zone or domain filters instead.",
+			requested, threshold, edns.Status.TextOwnerID)
+	}
+	return condition
+}
+
+// computeCredentialsAvailableCondition reports whether the operand of an AWS
+// edns has either static credentials or a role bound to its service account.
+// A nil condition is returned for other providers.
+func (r *reconciler) computeCredentialsAvailableCondition(edns *operatorv1.ExternalDNS) (*operatorv1.OperatorCondition, error) {
+	if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.AWSProvider {
+		return nil, nil
+	}
+
+	condition := &operatorv1.OperatorCondition{Type: operatorv1.CredentialsAvailableConditionType}
+	if hasStaticAWSCredentials(r.Credentials) {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "StaticCredentials"
+		return condition, nil
+	}
+
+	sa := &corev1.ServiceAccount{}
+	name := desiredExternalDNSServiceAccount(r.OperandNamespace)
+	if err := r.kclient.Get(context.TODO(), types.NamespacedName{Namespace: name.Namespace, Name: name.Name}, sa); err != nil {
+		return nil, fmt.Errorf("failed to get externaldns service account %s/%s: %v", name.Namespace, name.Name, err)
+	}
+	if arn := sa.Annotations[ServiceAccountRoleARNAnnotation]; len(arn) != 0 {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "RoleAnnotation"
+		condition.Message = fmt.Sprintf("Service account %s/%s assumes role %s.", sa.Namespace, sa.Name, arn)
+		return condition, nil
+	}
+	condition.Status = operatorv1.ConditionFalse
+	condition.Reason = "NoCredentials"
+	condition.Message = fmt.Sprintf("No static credentials exist and service account %s/%s is missing the %s annotation.",
+		sa.Namespace, sa.Name, ServiceAccountRoleARNAnnotation)
+	return condition, nil
+}
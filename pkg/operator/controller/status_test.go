@@ -0,0 +1,416 @@
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOverlappingExternalDNSes(t *testing.T) {
+	aws := operatorv1.AWSProvider
+	google := operatorv1.GoogleProvider
+	newExternalDNS := func(name string, provider *operatorv1.ProviderType, zones ...string) operatorv1.ExternalDNS {
+		edns := operatorv1.ExternalDNS{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		edns.Status.ProviderType = provider
+		for _, id := range zones {
+			edns.Spec.Provider.ZoneFilter = append(edns.Spec.Provider.ZoneFilter, &configv1.DNSZone{ID: id})
+		}
+		return edns
+	}
+	edns := newExternalDNS("mine", &aws, "a", "b")
+	testCases := []struct {
+		description string
+		dnses       []operatorv1.ExternalDNS
+		expected    []string
+	}{
+		{
+			description: "only itself",
+			dnses:       []operatorv1.ExternalDNS{edns},
+			expected:    []string{},
+		},
+		{
+			description: "shared zone",
+			dnses:       []operatorv1.ExternalDNS{edns, newExternalDNS("default-public-zone", &aws, "b"), newExternalDNS("other", &aws, "c")},
+			expected:    []string{"default-public-zone"},
+		},
+		{
+			description: "shared zone id of another provider",
+			dnses:       []operatorv1.ExternalDNS{edns, newExternalDNS("gcp", &google, "a")},
+			expected:    []string{},
+		},
+	}
+	for _, tc := range testCases {
+		if actual := overlappingExternalDNSes(&edns, tc.dnses); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+type fakeZoneChecker map[string]bool
+
+func (f fakeZoneChecker) HostedZoneExists(id string) (bool, error) {
+	exists, ok := f[id]
+	if !ok {
+		return false, errors.New("AccessDenied")
+	}
+	return exists, nil
+}
+
+func TestZonesAvailableCondition(t *testing.T) {
+	checker := fakeZoneChecker{"Z1": true, "Z2": false}
+	testCases := []struct {
+		description string
+		zones       []*configv1.DNSZone
+		status      operatorv1.ConditionStatus
+		reason      string
+	}{
+		{
+			description: "existing zone",
+			zones:       []*configv1.DNSZone{{ID: "Z1"}},
+			status:      operatorv1.ConditionTrue,
+			reason:      "ZonesFound",
+		},
+		{
+			description: "tagged zone is skipped",
+			zones:       []*configv1.DNSZone{{Tags: map[string]string{"Name": "x"}}},
+			status:      operatorv1.ConditionTrue,
+			reason:      "ZonesFound",
+		},
+		{
+			description: "missing zone",
+			zones:       []*configv1.DNSZone{{ID: "Z1"}, {ID: "Z2"}},
+			status:      operatorv1.ConditionFalse,
+			reason:      "ZoneNotFound",
+		},
+		{
+			description: "inaccessible zone",
+			zones:       []*configv1.DNSZone{{ID: "Z3"}},
+			status:      operatorv1.ConditionFalse,
+			reason:      "ZoneInaccessible",
+		},
+	}
+	for _, tc := range testCases {
+		c := zonesAvailableCondition(checker, tc.zones)
+		if c.Status != tc.status || c.Reason != tc.reason {
+			t.Errorf("%q: expected %s/%s, got %s/%s: %s", tc.description, tc.status, tc.reason, c.Status, c.Reason, c.Message)
+		}
+	}
+}
+
+func TestOperandRolloutFailedCondition(t *testing.T) {
+	progressing := func(status corev1.ConditionStatus, reason string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: status, Reason: reason}},
+			},
+		}
+	}
+	testCases := []struct {
+		description string
+		deployment  *appsv1.Deployment
+		expected    operatorv1.ConditionStatus
+	}{
+		{
+			description: "no deployment",
+			expected:    operatorv1.ConditionFalse,
+		},
+		{
+			description: "rollout progressing",
+			deployment:  progressing(corev1.ConditionTrue, "ReplicaSetUpdated"),
+			expected:    operatorv1.ConditionFalse,
+		},
+		{
+			description: "progress deadline exceeded",
+			deployment:  progressing(corev1.ConditionFalse, "ProgressDeadlineExceeded"),
+			expected:    operatorv1.ConditionTrue,
+		},
+	}
+	for _, tc := range testCases {
+		condition := operandRolloutFailedCondition(tc.deployment)
+		if condition.Type != operatorv1.OperandRolloutFailedConditionType || condition.Status != tc.expected {
+			t.Errorf("%q: expected %s condition %s, got %s %s", tc.description,
+				operatorv1.OperandRolloutFailedConditionType, tc.expected, condition.Type, condition.Status)
+		}
+	}
+}
+
+func TestProgressingCondition(t *testing.T) {
+	two := int32(2)
+	deployment := func(generation, observed int64, replicas, updated, available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: &two},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: observed,
+				Replicas:           replicas,
+				UpdatedReplicas:    updated,
+				AvailableReplicas:  available,
+			},
+		}
+	}
+	failed := deployment(2, 2, 3, 1, 2)
+	failed.Status.Conditions = []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+	}
+	testCases := []struct {
+		description string
+		deployment  *appsv1.Deployment
+		expected    operatorv1.ConditionStatus
+	}{
+		{"no deployment", nil, operatorv1.ConditionFalse},
+		{"settled", deployment(2, 2, 2, 2, 2), operatorv1.ConditionFalse},
+		{"generation not observed", deployment(3, 2, 2, 2, 2), operatorv1.ConditionTrue},
+		{"replicas updating", deployment(2, 2, 3, 1, 2), operatorv1.ConditionTrue},
+		{"old replicas terminating", deployment(2, 2, 3, 2, 2), operatorv1.ConditionTrue},
+		{"updated replicas unavailable", deployment(2, 2, 2, 2, 1), operatorv1.ConditionTrue},
+		{"progress deadline exceeded", failed, operatorv1.ConditionFalse},
+	}
+	for _, tc := range testCases {
+		condition := progressingCondition(tc.deployment)
+		if condition.Type != operatorv1.ProgressingConditionType || condition.Status != tc.expected {
+			t.Errorf("%q: expected %s condition %s, got %s %s", tc.description,
+				operatorv1.ProgressingConditionType, tc.expected, condition.Type, condition.Status)
+		}
+	}
+}
+
+func TestAvailableCondition(t *testing.T) {
+	zero := int32(0)
+	deployment := func(available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{Status: appsv1.DeploymentStatus{AvailableReplicas: available}}
+	}
+	scaledDown := deployment(0)
+	scaledDown.Spec.Replicas = &zero
+
+	// The condition transitions as the deployment becomes ready and
+	// loses its replicas again.
+	conditions := []operatorv1.OperatorCondition{}
+	steps := []struct {
+		description string
+		deployment  *appsv1.Deployment
+		expected    operatorv1.ConditionStatus
+		reason      string
+	}{
+		{"no deployment", nil, operatorv1.ConditionFalse, "NoDeployment"},
+		{"no ready replicas", deployment(0), operatorv1.ConditionFalse, "DeploymentUnavailable"},
+		{"ready replica", deployment(1), operatorv1.ConditionTrue, "DeploymentAvailable"},
+		{"replica lost", deployment(0), operatorv1.ConditionFalse, "DeploymentUnavailable"},
+		{"scaled down", scaledDown, operatorv1.ConditionFalse, "ScaledDown"},
+	}
+	var lastStatus operatorv1.ConditionStatus
+	var lastTransition metav1.Time
+	for _, step := range steps {
+		conditions = setExternalDNSCondition(conditions, *availableCondition(step.deployment))
+		condition := findExternalDNSCondition(conditions, operatorv1.AvailableConditionType)
+		if condition == nil || condition.Status != step.expected || condition.Reason != step.reason {
+			t.Fatalf("%q: expected %s condition %s with reason %s, got %v", step.description,
+				operatorv1.AvailableConditionType, step.expected, step.reason, condition)
+		}
+		if condition.Status == lastStatus && !condition.LastTransitionTime.Equal(&lastTransition) {
+			t.Errorf("%q: expected the transition time to be kept without a status change", step.description)
+		}
+		lastStatus, lastTransition = condition.Status, condition.LastTransitionTime
+	}
+}
+
+func TestJobConditions(t *testing.T) {
+	job := func(active int32, conditionType batchv1.JobConditionType) *batchv1.Job {
+		job := &batchv1.Job{Status: batchv1.JobStatus{Active: active}}
+		if len(conditionType) != 0 {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+		}
+		return job
+	}
+	testCases := []struct {
+		description         string
+		job                 *batchv1.Job
+		expectedAvailable   operatorv1.ConditionStatus
+		expectedProgressing operatorv1.ConditionStatus
+		reason              string
+	}{
+		{"no job", nil, operatorv1.ConditionFalse, operatorv1.ConditionFalse, "NoJob"},
+		{"pending", job(0, ""), operatorv1.ConditionFalse, operatorv1.ConditionTrue, ""},
+		{"running", job(1, ""), operatorv1.ConditionTrue, operatorv1.ConditionTrue, "JobRunning"},
+		{"complete", job(0, batchv1.JobComplete), operatorv1.ConditionTrue, operatorv1.ConditionFalse, ""},
+		{"failed", job(0, batchv1.JobFailed), operatorv1.ConditionFalse, operatorv1.ConditionFalse, "JobFailed"},
+	}
+	for _, tc := range testCases {
+		available := jobAvailableCondition(tc.job)
+		if available.Type != operatorv1.AvailableConditionType || available.Status != tc.expectedAvailable {
+			t.Errorf("%q: expected %s condition %s, got %s %s", tc.description,
+				operatorv1.AvailableConditionType, tc.expectedAvailable, available.Type, available.Status)
+		}
+		progressing := jobProgressingCondition(tc.job)
+		if progressing.Type != operatorv1.ProgressingConditionType || progressing.Status != tc.expectedProgressing {
+			t.Errorf("%q: expected %s condition %s, got %s %s", tc.description,
+				operatorv1.ProgressingConditionType, tc.expectedProgressing, progressing.Type, progressing.Status)
+		}
+		if len(tc.reason) != 0 && (available.Reason != tc.reason || progressing.Reason != tc.reason) {
+			t.Errorf("%q: expected reason %s, got %s and %s", tc.description, tc.reason, available.Reason, progressing.Reason)
+		}
+	}
+}
+
+func TestDegradedCondition(t *testing.T) {
+	replicaFailure := &appsv1.Deployment{Status: appsv1.DeploymentStatus{
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentReplicaFailure, Status: corev1.ConditionTrue, Message: "exceeded quota"},
+		},
+	}}
+	deadlineExceeded := &appsv1.Deployment{Status: appsv1.DeploymentStatus{
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+		},
+	}}
+	testCases := []struct {
+		description string
+		deployment  *appsv1.Deployment
+		err         error
+		expected    operatorv1.ConditionStatus
+		reason      string
+	}{
+		{"healthy", &appsv1.Deployment{}, nil, operatorv1.ConditionFalse, "AsExpected"},
+		{"no deployment yet", nil, nil, operatorv1.ConditionFalse, "AsExpected"},
+		{"deployment not created", nil, errors.New("forbidden"), operatorv1.ConditionTrue, "DeploymentReconcileFailed"},
+		{"pods not created", replicaFailure, nil, operatorv1.ConditionTrue, "ReplicaFailure"},
+		{"rollout failed", deadlineExceeded, nil, operatorv1.ConditionTrue, "ProgressDeadlineExceeded"},
+	}
+	for _, tc := range testCases {
+		condition := degradedCondition(tc.deployment, tc.err)
+		if condition.Type != operatorv1.DegradedConditionType || condition.Status != tc.expected || condition.Reason != tc.reason {
+			t.Errorf("%q: expected %s condition %s with reason %s, got %s %s %s", tc.description,
+				operatorv1.DegradedConditionType, tc.expected, tc.reason, condition.Type, condition.Status, condition.Reason)
+		}
+	}
+}
+
+func TestExcessReplicasCondition(t *testing.T) {
+	one, two, three := int32(1), int32(2), int32(3)
+	testCases := []struct {
+		description string
+		replicas    *int32
+		autoscaling *operatorv1.AutoscalingSpec
+		threshold   *int32
+		expected    operatorv1.ConditionStatus
+	}{
+		{"defaults", nil, nil, nil, operatorv1.ConditionFalse},
+		{"single replica", &one, nil, nil, operatorv1.ConditionFalse},
+		{"excess replicas", &two, nil, nil, operatorv1.ConditionTrue},
+		{"single autoscaled replica", nil, &operatorv1.AutoscalingSpec{MaxReplicas: 1}, nil, operatorv1.ConditionFalse},
+		{"excess autoscaled replicas", &one, &operatorv1.AutoscalingSpec{MaxReplicas: 3}, nil, operatorv1.ConditionTrue},
+		{"replicas at the threshold", &two, nil, &two, operatorv1.ConditionFalse},
+		{"replicas over the threshold", &three, nil, &two, operatorv1.ConditionTrue},
+		{"autoscaled replicas at the threshold", nil, &operatorv1.AutoscalingSpec{MaxReplicas: 3}, &three, operatorv1.ConditionFalse},
+		{"autoscaled replicas over the threshold", nil, &operatorv1.AutoscalingSpec{MaxReplicas: 3}, &two, operatorv1.ConditionTrue},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{}
+		edns.Spec.Replicas = tc.replicas
+		edns.Spec.Autoscaling = tc.autoscaling
+		edns.Spec.ExcessReplicasThreshold = tc.threshold
+		condition := excessReplicasCondition(edns)
+		if condition.Type != operatorv1.ExcessReplicasConditionType || condition.Status != tc.expected {
+			t.Errorf("%q: expected %s condition %s, got %s %s", tc.description,
+				operatorv1.ExcessReplicasConditionType, tc.expected, condition.Type, condition.Status)
+		}
+	}
+}
+
+func TestBaseDomainChangedCondition(t *testing.T) {
+	edns := &operatorv1.ExternalDNS{
+		Spec:   operatorv1.ExternalDNSSpec{BaseDomain: "example.com"},
+		Status: operatorv1.ExternalDNSStatus{BaseDomain: "example.com"},
+	}
+	if condition := baseDomainChangedCondition(edns); condition != nil {
+		t.Errorf("expected no condition for an unchanged base domain, got %v", condition)
+	}
+
+	edns.Spec.BaseDomain = "example.org"
+	condition := externalDNSDegradedCondition(edns, nil)
+	if condition.Type != operatorv1.DegradedConditionType || condition.Status != operatorv1.ConditionTrue || condition.Reason != "BaseDomainChanged" {
+		t.Errorf("expected %s condition %s with reason BaseDomainChanged, got %s %s %s",
+			operatorv1.DegradedConditionType, operatorv1.ConditionTrue, condition.Type, condition.Status, condition.Reason)
+	}
+
+	edns.Status.BaseDomain = ""
+	if condition := baseDomainChangedCondition(edns); condition != nil {
+		t.Errorf("expected no condition before the base domain is published, got %v", condition)
+	}
+}
+
+func TestCredentialsAvailableCondition(t *testing.T) {
+	aws := operatorv1.AWSProvider
+	google := operatorv1.GoogleProvider
+	staticCreds := &corev1.Secret{Data: map[string][]byte{"aws_access_key_id": []byte("key"), "aws_secret_access_key": []byte("secret")}}
+	sa := func(annotations map[string]string) *corev1.ServiceAccount {
+		sa := desiredExternalDNSServiceAccount(DefaultOperandNamespace)
+		sa.Annotations = annotations
+		return sa
+	}
+	testCases := []struct {
+		description    string
+		provider       *operatorv1.ProviderType
+		creds          *corev1.Secret
+		sa             *corev1.ServiceAccount
+		expectedStatus operatorv1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			description:    "static credentials",
+			provider:       &aws,
+			creds:          staticCreds,
+			expectedStatus: operatorv1.ConditionTrue,
+			expectedReason: "StaticCredentials",
+		},
+		{
+			description:    "role annotation present",
+			provider:       &aws,
+			creds:          &corev1.Secret{},
+			sa:             sa(map[string]string{ServiceAccountRoleARNAnnotation: "arn:aws:iam::123456789012:role/externaldns"}),
+			expectedStatus: operatorv1.ConditionTrue,
+			expectedReason: "RoleAnnotation",
+		},
+		{
+			description:    "role annotation missing",
+			provider:       &aws,
+			creds:          &corev1.Secret{},
+			sa:             sa(nil),
+			expectedStatus: operatorv1.ConditionFalse,
+			expectedReason: "NoCredentials",
+		},
+		{
+			description: "other provider",
+			provider:    &google,
+			creds:       &corev1.Secret{},
+		},
+	}
+	for _, tc := range testCases {
+		r := &reconciler{
+			Config:  Config{OperandNamespace: DefaultOperandNamespace, Credentials: tc.creds},
+			kclient: &serviceAccountClient{sa: tc.sa},
+		}
+		edns := &operatorv1.ExternalDNS{Status: operatorv1.ExternalDNSStatus{ProviderType: tc.provider}}
+		condition, err := r.computeCredentialsAvailableCondition(edns)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.description, err)
+		}
+		if len(tc.expectedStatus) == 0 {
+			if condition != nil {
+				t.Errorf("%q: expected no condition, got %v", tc.description, condition)
+			}
+			continue
+		}
+		if condition == nil || condition.Type != operatorv1.CredentialsAvailableConditionType || condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason {
+			t.Errorf("%q: expected status %s with reason %s, got %v", tc.description, tc.expectedStatus, tc.expectedReason, condition)
+		}
+	}
+}
@@ -0,0 +1,1112 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+
+	"github.com/danehans/external-dns-operator/pkg/manifests"
+	"github.com/danehans/external-dns-operator/pkg/util/slice"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validateExternalDNS validates the user-provided configuration of edns,
+// returning an aggregate of all the problems found.
+func validateExternalDNS(edns *operatorv1.ExternalDNS) error {
+	errs := []error{}
+	for _, validate := range []func(*operatorv1.ExternalDNS) error{
+		validateMetricsAddress,
+		validateNamespace,
+		validateLabelFilter,
+		validateRegistry,
+		validateTXTOwnerIDFormat,
+		validatePolicy,
+		validateDomainFilters,
+		validateStartupFailureThreshold,
+		validateIncludeUnschedulableNodes,
+		validateCleanupRecordsOnDeletion,
+		validateServiceSourceOptions,
+		validateIngressClassFilter,
+		validateProviderEnv,
+		validateZoneType,
+		validateInitContainers,
+		validateRunMode,
+		validateAWSAPIRetries,
+		validateMinTTL,
+		validateAutoscaling,
+		validateReplicas,
+		validateExcessReplicasThreshold,
+		validateInMemoryProvider,
+		validateAWSBatchChangeSize,
+		validateGoogleBatchChange,
+		validateImagePullPolicy,
+		validateResources,
+		validateAWSTargetRecordTypes,
+		validateAWSAssumeRole,
+		validatePropagatedLabels,
+		validateConnectorSourceServer,
+		validateReadinessGates,
+		validateAWSZonesCacheDuration,
+		validateAWSZoneMatchParent,
+		validateDisableCRDSourceStatusUpdates,
+		validateWebhookProvider,
+		validateAnnotationPrefix,
+		validateHostnameOptions,
+		validateDefaultTargets,
+		validatePriorityClassName,
+		validateUserAgentAppID,
+		validateSyncIntervals,
+		validateAWSResourceTags,
+		validateProgressDeadlineSeconds,
+		validateCredentialsFiles,
+		validateServiceAccountToken,
+		validateCacheVolume,
+		validateCredentialsSidecar,
+		validateExperimentalArgs,
+		validateAWSCloudMap,
+		validateRevisionHistoryLimit,
+	} {
+		if err := validate(edns); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateMetricsAddress ensures spec.metricsAddress, if set, is a valid
+// host:port listen address.
+func validateMetricsAddress(edns *operatorv1.ExternalDNS) error {
+	if len(edns.Spec.MetricsAddress) == 0 {
+		return nil
+	}
+	if _, err := metricsPort(edns.Spec.MetricsAddress); err != nil {
+		return fmt.Errorf("invalid metricsAddress %q: %v", edns.Spec.MetricsAddress, err)
+	}
+	return nil
+}
+
+// validateNamespace ensures spec.namespace, if set, is a valid namespace
+// name, since it scopes both the operand's sources and, for the crd
+// source, the RoleBinding granting access to DNSEndpoints.
+func validateNamespace(edns *operatorv1.ExternalDNS) error {
+	if len(edns.Spec.Namespace) == 0 {
+		return nil
+	}
+	if errs := validation.IsDNS1123Label(edns.Spec.Namespace); len(errs) != 0 {
+		return fmt.Errorf("invalid namespace %q: %v", edns.Spec.Namespace, errs)
+	}
+	return nil
+}
+
+// validateLabelFilter ensures spec.labelFilter, if set, is a valid label
+// selector that, together with spec.namespace, scopes the operand to the
+// labeled resources of a single tenant namespace. The node and connector
+// sources aren't namespaced, so they would escape the tenant namespace.
+func validateLabelFilter(edns *operatorv1.ExternalDNS) error {
+	if len(edns.Spec.LabelFilter) == 0 {
+		return nil
+	}
+	if _, err := labels.Parse(edns.Spec.LabelFilter); err != nil {
+		return fmt.Errorf("invalid labelFilter %q: %v", edns.Spec.LabelFilter, err)
+	}
+	if len(edns.Spec.Namespace) == 0 {
+		return fmt.Errorf("labelFilter requires namespace")
+	}
+	for _, s := range []operatorv1.SourceType{operatorv1.NodeType, operatorv1.ConnectorType} {
+		if hasSourceType(edns, s) {
+			return fmt.Errorf("labelFilter cannot be used with the %q source", s)
+		}
+	}
+	return nil
+}
+
+// validatePolicy ensures spec.policy, if set, is a known policy type.
+func validatePolicy(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.Policy == nil {
+		return nil
+	}
+	switch *edns.Spec.Policy {
+	case operatorv1.SyncPolicyType, operatorv1.UpsertOnlyPolicyType:
+		return nil
+	}
+	return fmt.Errorf("invalid policy %q: must be %q or %q", *edns.Spec.Policy,
+		operatorv1.SyncPolicyType, operatorv1.UpsertOnlyPolicyType)
+}
+
+// validateTXTOwnerIDFormat ensures spec.txtOwnerIDFormat, if set, is a known
+// format.
+func validateTXTOwnerIDFormat(edns *operatorv1.ExternalDNS) error {
+	switch edns.Spec.TXTOwnerIDFormat {
+	case "", operatorv1.FullTXTOwnerIDFormat, operatorv1.HashedTXTOwnerIDFormat:
+		return nil
+	}
+	return fmt.Errorf("invalid txtOwnerIDFormat %q: must be %q or %q", edns.Spec.TXTOwnerIDFormat,
+		operatorv1.FullTXTOwnerIDFormat, operatorv1.HashedTXTOwnerIDFormat)
+}
+
+// validateRegistry ensures spec.registry is a known registry type, that
+// TXT registry options aren't passed to the noop registry, that txtPrefix
+// is only used with the txt registry and that the dynamodb registry is
+// only used with the aws provider.
+func validateRegistry(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.Provider.AWSDynamoDB != nil && edns.Spec.Registry != operatorv1.DynamoDBRegistryType {
+		return fmt.Errorf("provider.awsDynamoDB requires registry %q", operatorv1.DynamoDBRegistryType)
+	}
+	if len(edns.Spec.TXTPrefix) != 0 && len(edns.Spec.Registry) != 0 && edns.Spec.Registry != operatorv1.TXTRegistryType {
+		return fmt.Errorf("txtPrefix requires registry %q", operatorv1.TXTRegistryType)
+	}
+	if strings.ContainsAny(edns.Spec.TXTPrefix+edns.Spec.TXTOwnerID, " \t\n") {
+		return fmt.Errorf("txtPrefix and txtOwnerID cannot contain whitespace")
+	}
+	switch edns.Spec.Registry {
+	case "", operatorv1.TXTRegistryType:
+		return nil
+	case operatorv1.DynamoDBRegistryType:
+		if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.AWSProvider {
+			return fmt.Errorf("registry %q requires the %q provider", edns.Spec.Registry, operatorv1.AWSProvider)
+		}
+		return validateAWSDynamoDB(edns.Spec.Provider.AWSDynamoDB)
+	case operatorv1.NoopRegistryType:
+		if len(edns.Spec.TXTOwnerID) != 0 {
+			return fmt.Errorf("txtOwnerID cannot be used with registry %q", edns.Spec.Registry)
+		}
+		for _, arg := range edns.Spec.Provider.Args {
+			if strings.HasPrefix(arg, "--txt-") {
+				return fmt.Errorf("provider arg %q cannot be used with registry %q", arg, edns.Spec.Registry)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid registry %q", edns.Spec.Registry)
+	}
+}
+
+// awsRegionRegexp matches AWS region names, including GovCloud and other
+// partitions, e.g. "us-east-1" or "us-gov-west-1".
+var awsRegionRegexp = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
+// validateAWSDynamoDB ensures the region and endpoint of the dynamodb
+// registry table, if set, are well formed.
+func validateAWSDynamoDB(dynamoDB *operatorv1.AWSDynamoDBRegistrySpec) error {
+	if dynamoDB == nil {
+		return nil
+	}
+	if len(dynamoDB.Region) != 0 && !awsRegionRegexp.MatchString(dynamoDB.Region) {
+		return fmt.Errorf("invalid provider.awsDynamoDB.region %q", dynamoDB.Region)
+	}
+	if len(dynamoDB.Endpoint) != 0 {
+		u, err := url.Parse(dynamoDB.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return fmt.Errorf("invalid provider.awsDynamoDB.endpoint %q: must be an http or https URL", dynamoDB.Endpoint)
+		}
+	}
+	return nil
+}
+
+// validateDomainFilters ensures the domain filters of edns, from the spec
+// and the provider args, combine the way the ExternalDNS controller honors
+// them: exact domain filters with excluded domains, or a regular expression
+// domain filter with a regular expression exclusion. The ExternalDNS
+// controller silently ignores the exact filters and exclusions when a
+// regular expression domain filter is set.
+func validateDomainFilters(edns *operatorv1.ExternalDNS) error {
+	regex, exclusion := edns.Spec.RegexDomainFilter, edns.Spec.RegexDomainExclusion
+	domainFilterArgs, excludeDomainArgs := []string{}, []string{}
+	for _, arg := range edns.Spec.Provider.Args {
+		switch {
+		case strings.HasPrefix(arg, "--domain-filter"):
+			domainFilterArgs = append(domainFilterArgs, arg)
+		case strings.HasPrefix(arg, "--exclude-domains"):
+			excludeDomainArgs = append(excludeDomainArgs, arg)
+		case strings.HasPrefix(arg, "--regex-domain-filter="):
+			if len(regex) != 0 {
+				return fmt.Errorf("regexDomainFilter cannot be combined with provider arg %q", arg)
+			}
+			regex = strings.TrimPrefix(arg, "--regex-domain-filter=")
+		case strings.HasPrefix(arg, "--regex-domain-exclusion="):
+			if len(exclusion) != 0 {
+				return fmt.Errorf("regexDomainExclusion cannot be combined with provider arg %q", arg)
+			}
+			exclusion = strings.TrimPrefix(arg, "--regex-domain-exclusion=")
+		}
+	}
+
+	for _, d := range edns.Spec.ExcludeDomains {
+		if errs := validation.IsDNS1123Subdomain(strings.Trim(d, ".")); len(errs) != 0 {
+			return fmt.Errorf("invalid excludeDomains entry %q: %v", d, errs)
+		}
+	}
+	if len(exclusion) != 0 {
+		if len(regex) == 0 {
+			return fmt.Errorf("regexDomainExclusion %q requires regexDomainFilter", exclusion)
+		}
+		if _, err := regexp.Compile(exclusion); err != nil {
+			return fmt.Errorf("invalid regexDomainExclusion %q: %v", exclusion, err)
+		}
+	}
+	if len(regex) == 0 {
+		return nil
+	}
+	if _, err := regexp.Compile(regex); err != nil {
+		return fmt.Errorf("invalid regexDomainFilter %q: %v", regex, err)
+	}
+	if len(domainFilterArgs) != 0 {
+		return fmt.Errorf("regexDomainFilter cannot be combined with provider arg %q", domainFilterArgs[0])
+	}
+	if len(edns.Spec.ExcludeDomains) != 0 {
+		return fmt.Errorf("regexDomainFilter cannot be combined with excludeDomains; use regexDomainExclusion")
+	}
+	if len(excludeDomainArgs) != 0 {
+		return fmt.Errorf("regexDomainFilter cannot be combined with provider arg %q; use regexDomainExclusion", excludeDomainArgs[0])
+	}
+	return nil
+}
+
+// validateStartupFailureThreshold ensures spec.startupFailureThreshold, if
+// set, is at least 1.
+func validateStartupFailureThreshold(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.StartupFailureThreshold != nil && *edns.Spec.StartupFailureThreshold < 1 {
+		return fmt.Errorf("invalid startupFailureThreshold %d: must be at least 1", *edns.Spec.StartupFailureThreshold)
+	}
+	return nil
+}
+
+// validateIncludeUnschedulableNodes ensures spec.includeUnschedulableNodes
+// is only set with the node source.
+func validateIncludeUnschedulableNodes(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.IncludeUnschedulableNodes && !hasSourceType(edns, operatorv1.NodeType) {
+		return fmt.Errorf("includeUnschedulableNodes requires the %q source", operatorv1.NodeType)
+	}
+	return nil
+}
+
+// validateCleanupRecordsOnDeletion ensures record cleanup is only enabled
+// with the TXT registry, since without ownership records the cleanup would
+// delete every record in the managed zones.
+func validateCleanupRecordsOnDeletion(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.CleanupRecordsOnDeletion && edns.Spec.Registry == operatorv1.NoopRegistryType {
+		return fmt.Errorf("cleanupRecordsOnDeletion cannot be used with registry %q", edns.Spec.Registry)
+	}
+	return nil
+}
+
+// validateServiceSourceOptions ensures spec.serviceTypeFilter,
+// spec.publishHostIP and spec.alwaysPublishNotReadyAddresses are only set
+// with the service source and are consistent with each other.
+func validateServiceSourceOptions(edns *operatorv1.ExternalDNS) error {
+	if len(edns.Spec.ServiceTypeFilter) == 0 && !edns.Spec.PublishHostIP && !edns.Spec.AlwaysPublishNotReadyAddresses {
+		return nil
+	}
+	if !hasSourceType(edns, operatorv1.ServiceType) {
+		return fmt.Errorf("serviceTypeFilter, publishHostIP and alwaysPublishNotReadyAddresses require the %q source", operatorv1.ServiceType)
+	}
+	clusterIP := false
+	for _, t := range edns.Spec.ServiceTypeFilter {
+		switch t {
+		case corev1.ServiceTypeClusterIP:
+			clusterIP = true
+		case corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeExternalName:
+		default:
+			return fmt.Errorf("invalid serviceTypeFilter entry %q", t)
+		}
+	}
+	// Headless services are of type ClusterIP.
+	if edns.Spec.PublishHostIP && len(edns.Spec.ServiceTypeFilter) != 0 && !clusterIP {
+		return fmt.Errorf("publishHostIP requires serviceTypeFilter to include %q", corev1.ServiceTypeClusterIP)
+	}
+	return nil
+}
+
+// validateIngressClassFilter ensures spec.ingressClassFilter is only set
+// with the ingress source and holds valid ingress class names.
+func validateIngressClassFilter(edns *operatorv1.ExternalDNS) error {
+	if len(edns.Spec.IngressClassFilter) == 0 {
+		return nil
+	}
+	if !hasSourceType(edns, operatorv1.IngressType) {
+		return fmt.Errorf("ingressClassFilter requires the %q source", operatorv1.IngressType)
+	}
+	for _, class := range edns.Spec.IngressClassFilter {
+		if errs := validation.IsDNS1123Subdomain(class); len(errs) != 0 {
+			return fmt.Errorf("invalid ingressClassFilter entry %q: %v", class, errs)
+		}
+	}
+	return nil
+}
+
+// validateProviderEnv ensures spec.provider.env doesn't set variables
+// managed by the operator.
+func validateProviderEnv(edns *operatorv1.ExternalDNS) error {
+	for _, env := range edns.Spec.Provider.Env {
+		if len(env.Name) == 0 {
+			return fmt.Errorf("provider env variables must have a name")
+		}
+		if slice.ContainsString(managedEnvVarNames, env.Name) {
+			return fmt.Errorf("provider env variable %q is managed by the operator", env.Name)
+		}
+	}
+	return nil
+}
+
+// validateZoneType ensures spec.zoneType, if set, is a known zone type.
+func validateZoneType(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.ZoneType == nil {
+		return nil
+	}
+	switch *edns.Spec.ZoneType {
+	case operatorv1.PublicZoneType, operatorv1.PrivateZoneType, operatorv1.AnyZoneType:
+		return nil
+	}
+	return fmt.Errorf("zoneType %q must be one of %q, %q or %q", *edns.Spec.ZoneType,
+		operatorv1.PublicZoneType, operatorv1.PrivateZoneType, operatorv1.AnyZoneType)
+}
+
+// validateInitContainers ensures spec.initContainers have unique, valid
+// names that don't collide with the operand containers, and an image.
+func validateInitContainers(edns *operatorv1.ExternalDNS) error {
+	names := []string{}
+	for _, c := range manifests.ExternalDNSDeployment().Spec.Template.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	if webhookSidecar(edns) != nil {
+		names = append(names, webhookSidecarName)
+	}
+	if edns.Spec.Provider.CredentialsSidecar != nil {
+		names = append(names, credentialsSidecarName)
+	}
+	names = append(names, flagValidationContainerName)
+	for _, c := range edns.Spec.InitContainers {
+		if errs := validation.IsDNS1123Label(c.Name); len(errs) != 0 {
+			return fmt.Errorf("invalid init container name %q: %v", c.Name, errs)
+		}
+		if slice.ContainsString(names, c.Name) {
+			return fmt.Errorf("init container name %q is already in use", c.Name)
+		}
+		if len(c.Image) == 0 {
+			return fmt.Errorf("init container %q must specify an image", c.Name)
+		}
+		names = append(names, c.Name)
+	}
+	return nil
+}
+
+// validateRunMode ensures spec.runMode is a known run mode and that
+// spec.restartPolicy suits the resource running the operand: Always for a
+// Deployment, and Never or OnFailure for a Job.
+func validateRunMode(edns *operatorv1.ExternalDNS) error {
+	switch edns.Spec.RunMode {
+	case "", operatorv1.ContinuousRunMode:
+		switch edns.Spec.RestartPolicy {
+		case "", corev1.RestartPolicyAlways:
+			return nil
+		}
+	case operatorv1.OnceRunMode:
+		switch edns.Spec.RestartPolicy {
+		case "", corev1.RestartPolicyNever, corev1.RestartPolicyOnFailure:
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown runMode %q", edns.Spec.RunMode)
+	}
+	return fmt.Errorf("restartPolicy %q cannot be used with runMode %q", edns.Spec.RestartPolicy, edns.Spec.RunMode)
+}
+
+// validateAWSAPIRetries ensures spec.provider.awsAPIRetries, if set, is
+// not negative.
+func validateAWSAPIRetries(edns *operatorv1.ExternalDNS) error {
+	if retries := edns.Spec.Provider.AWSAPIRetries; retries != nil && *retries < 0 {
+		return fmt.Errorf("awsAPIRetries must not be negative, got %d", *retries)
+	}
+	return nil
+}
+
+// validateMinTTL ensures spec.minTTL, if set, is a valid record TTL.
+func validateMinTTL(edns *operatorv1.ExternalDNS) error {
+	if ttl := edns.Spec.MinTTL; ttl != nil && (*ttl < 1 || *ttl > math.MaxInt32) {
+		return fmt.Errorf("minTTL must be between 1 and %d seconds, got %d", math.MaxInt32, *ttl)
+	}
+	return nil
+}
+
+// validateAutoscaling ensures spec.autoscaling, if set, has valid replica
+// limits and CPU utilization target.
+func validateAutoscaling(edns *operatorv1.ExternalDNS) error {
+	as := edns.Spec.Autoscaling
+	if as == nil {
+		return nil
+	}
+	if as.MaxReplicas < 1 {
+		return fmt.Errorf("autoscaling maxReplicas must be at least 1, got %d", as.MaxReplicas)
+	}
+	if as.MinReplicas != nil && (*as.MinReplicas < 1 || *as.MinReplicas > as.MaxReplicas) {
+		return fmt.Errorf("autoscaling minReplicas must be between 1 and maxReplicas %d, got %d", as.MaxReplicas, *as.MinReplicas)
+	}
+	if as.TargetCPUUtilizationPercentage != nil && *as.TargetCPUUtilizationPercentage < 1 {
+		return fmt.Errorf("autoscaling targetCPUUtilizationPercentage must be at least 1, got %d", *as.TargetCPUUtilizationPercentage)
+	}
+	return nil
+}
+
+// validateReplicas ensures spec.replicas, if set, isn't negative and isn't
+// combined with spec.autoscaling.
+func validateReplicas(edns *operatorv1.ExternalDNS) error {
+	replicas := edns.Spec.Replicas
+	if replicas == nil {
+		return nil
+	}
+	if *replicas < 0 {
+		return fmt.Errorf("invalid replicas %d: must not be negative", *replicas)
+	}
+	if edns.Spec.Autoscaling != nil {
+		return fmt.Errorf("replicas and autoscaling are mutually exclusive")
+	}
+	return nil
+}
+
+// validateExcessReplicasThreshold ensures spec.excessReplicasThreshold, if
+// set, is at least 1.
+func validateExcessReplicasThreshold(edns *operatorv1.ExternalDNS) error {
+	if threshold := edns.Spec.ExcessReplicasThreshold; threshold != nil && *threshold < 1 {
+		return fmt.Errorf("excessReplicasThreshold must be at least 1, got %d", *threshold)
+	}
+	return nil
+}
+
+// validateAWSBatchChangeSize ensures the Route 53 batch change size limits
+// of spec.provider, if set, are positive.
+func validateAWSBatchChangeSize(edns *operatorv1.ExternalDNS) error {
+	if size := edns.Spec.Provider.AWSBatchChangeSizeBytes; size != nil && *size < 1 {
+		return fmt.Errorf("awsBatchChangeSizeBytes must be positive, got %d", *size)
+	}
+	if size := edns.Spec.Provider.AWSBatchChangeSizeValues; size != nil && *size < 1 {
+		return fmt.Errorf("awsBatchChangeSizeValues must be positive, got %d", *size)
+	}
+	return nil
+}
+
+// validateGoogleBatchChange ensures the Cloud DNS batch change size and
+// interval of spec.provider, if set, are positive.
+func validateGoogleBatchChange(edns *operatorv1.ExternalDNS) error {
+	if size := edns.Spec.Provider.GoogleBatchChangeSize; size != nil && *size < 1 {
+		return fmt.Errorf("googleBatchChangeSize must be positive, got %d", *size)
+	}
+	if d := edns.Spec.Provider.GoogleBatchChangeInterval; d != nil && d.Duration <= 0 {
+		return fmt.Errorf("googleBatchChangeInterval must be positive, got %v", d.Duration)
+	}
+	return nil
+}
+
+// validateImagePullPolicy ensures spec.imagePullPolicy, if set, is a known
+// pull policy.
+func validateImagePullPolicy(edns *operatorv1.ExternalDNS) error {
+	switch edns.Spec.ImagePullPolicy {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return nil
+	}
+	return fmt.Errorf("imagePullPolicy %q must be one of %q, %q or %q", edns.Spec.ImagePullPolicy,
+		corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever)
+}
+
+// validateResources ensures the requests of spec.resources don't exceed
+// their limits.
+func validateResources(edns *operatorv1.ExternalDNS) error {
+	for name, request := range edns.Spec.Resources.Requests {
+		if limit, ok := edns.Spec.Resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+			return fmt.Errorf("resources request of %s %s exceeds its limit %s", name, request.String(), limit.String())
+		}
+	}
+	return nil
+}
+
+// validateAWSTargetRecordTypes ensures provider.awsTargetRecordTypes, if
+// set, only uses known record types.
+func validateAWSTargetRecordTypes(edns *operatorv1.ExternalDNS) error {
+	types := edns.Spec.Provider.AWSTargetRecordTypes
+	if types == nil {
+		return nil
+	}
+	errs := []error{}
+	for _, field := range []struct {
+		name       string
+		recordType operatorv1.AWSTargetRecordType
+	}{{"public", types.Public}, {"private", types.Private}} {
+		switch field.recordType {
+		case "", operatorv1.AliasAWSTargetRecordType, operatorv1.CNAMEAWSTargetRecordType:
+			continue
+		}
+		errs = append(errs, fmt.Errorf("awsTargetRecordTypes.%s %q must be %q or %q", field.name, field.recordType,
+			operatorv1.AliasAWSTargetRecordType, operatorv1.CNAMEAWSTargetRecordType))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateAWSAssumeRole ensures provider.awsAssumeRole, if set, is an IAM
+// role ARN and provider.awsAssumeRoleExternalID is only set with it.
+func validateAWSAssumeRole(edns *operatorv1.ExternalDNS) error {
+	provider := edns.Spec.Provider
+	if len(provider.AWSAssumeRole) == 0 {
+		if len(provider.AWSAssumeRoleExternalID) != 0 {
+			return fmt.Errorf("awsAssumeRoleExternalID requires awsAssumeRole")
+		}
+		return nil
+	}
+	parsed, err := arn.Parse(provider.AWSAssumeRole)
+	if err != nil {
+		return fmt.Errorf("awsAssumeRole %q is not a valid ARN: %v", provider.AWSAssumeRole, err)
+	}
+	if parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+		return fmt.Errorf("awsAssumeRole %q is not an IAM role ARN", provider.AWSAssumeRole)
+	}
+	return nil
+}
+
+// validatePropagatedLabels ensures spec.propagatedLabels only contains
+// valid label keys.
+func validatePropagatedLabels(edns *operatorv1.ExternalDNS) error {
+	errs := []error{}
+	for _, k := range edns.Spec.PropagatedLabels {
+		if msgs := validation.IsQualifiedName(k); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("propagatedLabels key %q is invalid: %s", k, strings.Join(msgs, "; ")))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateConnectorSourceServer ensures spec.connectorSourceServer is a
+// host:port address set exactly when the connector source is used.
+func validateConnectorSourceServer(edns *operatorv1.ExternalDNS) error {
+	server := edns.Spec.ConnectorSourceServer
+	if !hasSourceType(edns, operatorv1.ConnectorType) {
+		if len(server) != 0 {
+			return fmt.Errorf("connectorSourceServer requires the %q source", operatorv1.ConnectorType)
+		}
+		return nil
+	}
+	if len(server) == 0 {
+		return fmt.Errorf("the %q source requires connectorSourceServer", operatorv1.ConnectorType)
+	}
+	if host, port, err := net.SplitHostPort(server); err != nil || len(host) == 0 || len(port) == 0 {
+		return fmt.Errorf("invalid connectorSourceServer %q: must be a host:port address", server)
+	}
+	return nil
+}
+
+// validateReadinessGates ensures spec.readinessGates have valid, unique
+// condition types.
+func validateReadinessGates(edns *operatorv1.ExternalDNS) error {
+	types := []string{}
+	for _, g := range edns.Spec.ReadinessGates {
+		conditionType := string(g.ConditionType)
+		if msgs := validation.IsQualifiedName(conditionType); len(msgs) != 0 {
+			return fmt.Errorf("invalid readiness gate condition type %q: %s", conditionType, strings.Join(msgs, "; "))
+		}
+		if slice.ContainsString(types, conditionType) {
+			return fmt.Errorf("duplicate readiness gate condition type %q", conditionType)
+		}
+		types = append(types, conditionType)
+	}
+	return nil
+}
+
+// validateAWSZonesCacheDuration ensures provider.awsZonesCacheDuration, if
+// set, isn't negative.
+func validateAWSZonesCacheDuration(edns *operatorv1.ExternalDNS) error {
+	if d := edns.Spec.Provider.AWSZonesCacheDuration; d != nil && d.Duration < 0 {
+		return fmt.Errorf("awsZonesCacheDuration must not be negative, got %s", d.Duration)
+	}
+	return nil
+}
+
+// validateAWSZoneMatchParent ensures provider.awsZoneMatchParent is only set
+// with the aws provider and a zoneFilter listing a zone by ID. Parent zones
+// match the base domain filter too, so without the delegated child zone in
+// the zoneFilter, the records of the subdomain would land in the parent.
+func validateAWSZoneMatchParent(edns *operatorv1.ExternalDNS) error {
+	if !edns.Spec.Provider.AWSZoneMatchParent {
+		return nil
+	}
+	if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.AWSProvider {
+		return fmt.Errorf("provider.awsZoneMatchParent requires the %q provider", operatorv1.AWSProvider)
+	}
+	for _, z := range edns.Spec.Provider.ZoneFilter {
+		if z != nil && len(z.ID) != 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("provider.awsZoneMatchParent requires a zoneFilter listing the delegated child zone by ID")
+}
+
+// validateDisableCRDSourceStatusUpdates ensures
+// spec.disableCRDSourceStatusUpdates is only set with the crd source.
+func validateDisableCRDSourceStatusUpdates(edns *operatorv1.ExternalDNS) error {
+	if edns.Spec.DisableCRDSourceStatusUpdates && !hasSourceType(edns, operatorv1.CRDType) {
+		return fmt.Errorf("disableCRDSourceStatusUpdates requires the %q source", operatorv1.CRDType)
+	}
+	return nil
+}
+
+// validateWebhookProvider ensures provider.webhook is only set with the
+// webhook provider, with an http or https url and a sidecar that can run
+// alongside the operand. The sidecar of a run-once operand would keep its
+// Job from completing, so it's only allowed with a continuous run mode and
+// without record cleanup.
+func validateWebhookProvider(edns *operatorv1.ExternalDNS) error {
+	webhook := edns.Spec.Provider.Webhook
+	if webhook == nil {
+		return nil
+	}
+	if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.WebhookProvider {
+		return fmt.Errorf("provider.webhook requires the %q provider", operatorv1.WebhookProvider)
+	}
+	if len(webhook.URL) != 0 {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return fmt.Errorf("invalid provider.webhook.url %q: must be an http or https URL", webhook.URL)
+		}
+	}
+	sidecar := webhook.Sidecar
+	if sidecar == nil {
+		return nil
+	}
+	if len(sidecar.Name) != 0 && sidecar.Name != webhookSidecarName {
+		return fmt.Errorf("provider.webhook.sidecar name %q must be empty or %q", sidecar.Name, webhookSidecarName)
+	}
+	if len(sidecar.Image) == 0 {
+		return fmt.Errorf("provider.webhook.sidecar requires an image")
+	}
+	if edns.Spec.RunMode == operatorv1.OnceRunMode {
+		return fmt.Errorf("provider.webhook.sidecar cannot be used with run mode %q", operatorv1.OnceRunMode)
+	}
+	if edns.Spec.CleanupRecordsOnDeletion {
+		return fmt.Errorf("provider.webhook.sidecar cannot be used with cleanupRecordsOnDeletion")
+	}
+	return nil
+}
+
+// validateAnnotationPrefix ensures spec.annotationPrefix, if set, is a DNS
+// subdomain followed by "/", so annotation names built from it are valid.
+func validateAnnotationPrefix(edns *operatorv1.ExternalDNS) error {
+	prefix := edns.Spec.AnnotationPrefix
+	if len(prefix) == 0 {
+		return nil
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		return fmt.Errorf("invalid annotationPrefix %q: must end with \"/\"", prefix)
+	}
+	if msgs := validation.IsDNS1123Subdomain(strings.TrimSuffix(prefix, "/")); len(msgs) != 0 {
+		return fmt.Errorf("invalid annotationPrefix %q: %s", prefix, strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// validateHostnameOptions ensures the options deciding the hostnames of
+// sources are consistent, whether set through spec fields or provider.args:
+// the template must parse, and ignoring or combining the hostname
+// annotation requires a template and excludes each other.
+func validateHostnameOptions(edns *operatorv1.ExternalDNS) error {
+	template := edns.Spec.FQDNTemplate
+	ignore := edns.Spec.IgnoreHostnameAnnotation
+	combine := edns.Spec.CombineFQDNAnnotation
+	for _, arg := range edns.Spec.Provider.Args {
+		switch {
+		case strings.HasPrefix(arg, "--fqdn-template="):
+			template = strings.TrimPrefix(arg, "--fqdn-template=")
+		case arg == "--ignore-hostname-annotation":
+			ignore = true
+		case arg == "--combine-fqdn-annotation":
+			combine = true
+		}
+	}
+	if len(template) != 0 {
+		if _, err := texttemplate.New("fqdn").Parse(template); err != nil {
+			return fmt.Errorf("invalid fqdnTemplate %q: %v", template, err)
+		}
+	}
+	switch {
+	case ignore && combine:
+		return fmt.Errorf("ignoreHostnameAnnotation and combineFQDNAnnotation cannot be used together")
+	case ignore && len(template) == 0:
+		return fmt.Errorf("ignoreHostnameAnnotation requires fqdnTemplate")
+	case combine && len(template) == 0:
+		return fmt.Errorf("combineFQDNAnnotation requires fqdnTemplate")
+	}
+	return nil
+}
+
+// validateDefaultTargets ensures spec.defaultTargets are unique IP
+// addresses or DNS names.
+func validateDefaultTargets(edns *operatorv1.ExternalDNS) error {
+	targets := []string{}
+	for _, target := range edns.Spec.DefaultTargets {
+		if net.ParseIP(target) == nil {
+			if msgs := validation.IsDNS1123Subdomain(strings.TrimSuffix(target, ".")); len(msgs) != 0 {
+				return fmt.Errorf("invalid defaultTargets entry %q: must be an IP address or DNS name", target)
+			}
+		}
+		if slice.ContainsString(targets, target) {
+			return fmt.Errorf("duplicate defaultTargets entry %q", target)
+		}
+		targets = append(targets, target)
+	}
+	return nil
+}
+
+// validatePriorityClassName ensures spec.priorityClassName, if set, is a
+// valid priority class name.
+func validatePriorityClassName(edns *operatorv1.ExternalDNS) error {
+	name := edns.Spec.PriorityClassName
+	if len(name) == 0 {
+		return nil
+	}
+	if msgs := validation.IsDNS1123Subdomain(name); len(msgs) != 0 {
+		return fmt.Errorf("invalid priorityClassName %q: %s", name, strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// maxUserAgentAppIDLength is the maximum length of an application id
+// recommended by the AWS SDKs.
+const maxUserAgentAppIDLength = 50
+
+// validateUserAgentAppID ensures provider.userAgentAppID, if set, is only
+// used with the aws provider and fits in a user agent.
+func validateUserAgentAppID(edns *operatorv1.ExternalDNS) error {
+	id := edns.Spec.Provider.UserAgentAppID
+	if len(id) == 0 {
+		return nil
+	}
+	if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.AWSProvider {
+		return fmt.Errorf("provider.userAgentAppID is only supported with the %q provider", operatorv1.AWSProvider)
+	}
+	if len(id) > maxUserAgentAppIDLength {
+		return fmt.Errorf("provider.userAgentAppID %q must be at most %d characters", id, maxUserAgentAppIDLength)
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return fmt.Errorf("provider.userAgentAppID %q must only contain printable ASCII characters without whitespace", id)
+		}
+	}
+	return nil
+}
+
+// validateSyncIntervals ensures spec.interval, spec.minEventSyncInterval and
+// events.fullResyncInterval are positive, with minEventSyncInterval at most
+// the periodic sync interval, and that provider.cacheTime is not negative.
+func validateSyncIntervals(edns *operatorv1.ExternalDNS) error {
+	interval, minEvent := edns.Spec.Interval, edns.Spec.MinEventSyncInterval
+	if interval != nil && interval.Duration <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", interval.Duration)
+	}
+	if minEvent != nil {
+		if minEvent.Duration <= 0 {
+			return fmt.Errorf("minEventSyncInterval must be positive, got %s", minEvent.Duration)
+		}
+		if interval != nil && minEvent.Duration > interval.Duration {
+			return fmt.Errorf("minEventSyncInterval %s must not exceed interval %s", minEvent.Duration, interval.Duration)
+		}
+	}
+	if edns.Spec.Events != nil && edns.Spec.Events.FullResyncInterval != nil {
+		fullResync := edns.Spec.Events.FullResyncInterval
+		if interval != nil {
+			return fmt.Errorf("interval and events.fullResyncInterval are mutually exclusive")
+		}
+		if fullResync.Duration <= 0 {
+			return fmt.Errorf("events.fullResyncInterval must be positive, got %s", fullResync.Duration)
+		}
+		if minEvent != nil && minEvent.Duration > fullResync.Duration {
+			return fmt.Errorf("minEventSyncInterval %s must not exceed events.fullResyncInterval %s", minEvent.Duration, fullResync.Duration)
+		}
+	}
+	if d := edns.Spec.Provider.CacheTime; d != nil && d.Duration < 0 {
+		return fmt.Errorf("provider.cacheTime must not be negative, got %s", d.Duration)
+	}
+	return nil
+}
+
+// awsTagRegexp matches the characters allowed in AWS tag keys and values.
+var awsTagRegexp = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// validateAWSResourceTags ensures provider.awsResourceTags is only set with
+// the aws provider, holds valid AWS tags, and doesn't conflict with the
+// serviceTag/ keys of provider.metadata.
+func validateAWSResourceTags(edns *operatorv1.ExternalDNS) error {
+	tags := edns.Spec.Provider.AWSResourceTags
+	if len(tags) == 0 {
+		return nil
+	}
+	if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.AWSProvider {
+		return fmt.Errorf("provider.awsResourceTags is only supported with the %q provider", operatorv1.AWSProvider)
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := tags[k]
+		switch {
+		case len(k) == 0 || utf8.RuneCountInString(k) > 128:
+			return fmt.Errorf("provider.awsResourceTags key %q must be 1 to 128 characters", k)
+		case utf8.RuneCountInString(v) > 256:
+			return fmt.Errorf("provider.awsResourceTags value of key %q must be at most 256 characters", k)
+		case strings.HasPrefix(strings.ToLower(k), "aws:"):
+			return fmt.Errorf("provider.awsResourceTags key %q must not start with \"aws:\"", k)
+		case !awsTagRegexp.MatchString(k) || !awsTagRegexp.MatchString(v):
+			return fmt.Errorf("provider.awsResourceTags %q=%q must only contain letters, digits, spaces and _.:/=+-@", k, v)
+		}
+		if mv, ok := edns.Spec.Provider.Metadata["serviceTag/"+k]; ok && mv != v {
+			return fmt.Errorf("provider.awsResourceTags key %q conflicts with provider.metadata key %q", k, "serviceTag/"+k)
+		}
+	}
+	return nil
+}
+
+// validateProgressDeadlineSeconds ensures spec.progressDeadlineSeconds, if
+// set, is positive.
+func validateProgressDeadlineSeconds(edns *operatorv1.ExternalDNS) error {
+	if s := edns.Spec.ProgressDeadlineSeconds; s != nil && *s < 1 {
+		return fmt.Errorf("invalid progressDeadlineSeconds %d: must be positive", *s)
+	}
+	return nil
+}
+
+// validateCredentialsFiles ensures provider.credentialsFiles names a valid
+// secret and an absolute mount path, and that spec.fsGroup isn't negative.
+func validateCredentialsFiles(edns *operatorv1.ExternalDNS) error {
+	if g := edns.Spec.FSGroup; g != nil && *g < 0 {
+		return fmt.Errorf("invalid fsGroup %d: must not be negative", *g)
+	}
+	files := edns.Spec.Provider.CredentialsFiles
+	if files == nil {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(files.SecretName); len(errs) != 0 {
+		return fmt.Errorf("invalid provider.credentialsFiles.secretName %q: %v", files.SecretName, errs)
+	}
+	if len(files.MountPath) != 0 && (!path.IsAbs(files.MountPath) || path.Clean(files.MountPath) == "/") {
+		return fmt.Errorf("invalid provider.credentialsFiles.mountPath %q: must be an absolute path other than /", files.MountPath)
+	}
+	return nil
+}
+
+// validateServiceAccountToken ensures provider.serviceAccountToken, if set,
+// has an audience, is used with a provider supporting keyless
+// authentication and is mounted at a valid path.
+func validateServiceAccountToken(edns *operatorv1.ExternalDNS) error {
+	token := edns.Spec.Provider.ServiceAccountToken
+	if token == nil {
+		return nil
+	}
+	if len(strings.TrimSpace(token.Audience)) == 0 {
+		return fmt.Errorf("provider.serviceAccountToken.audience must be set for keyless authentication")
+	}
+	if edns.Status.ProviderType != nil {
+		switch *edns.Status.ProviderType {
+		case operatorv1.AWSProvider, operatorv1.AzureProvider, operatorv1.GoogleProvider:
+		default:
+			return fmt.Errorf("provider.serviceAccountToken cannot be used with the %s provider", *edns.Status.ProviderType)
+		}
+	}
+	if len(token.MountPath) != 0 && (!path.IsAbs(token.MountPath) || path.Clean(token.MountPath) == "/") {
+		return fmt.Errorf("invalid provider.serviceAccountToken.mountPath %q: must be an absolute path other than /", token.MountPath)
+	}
+	if files := edns.Spec.Provider.CredentialsFiles; files != nil && len(token.MountPath) != 0 &&
+		path.Clean(token.MountPath) == path.Clean(credentialsFilesMountPath(files)) {
+		return fmt.Errorf("provider.serviceAccountToken.mountPath %q is the mount path of provider.credentialsFiles", token.MountPath)
+	}
+	return nil
+}
+
+// validateCacheVolume ensures spec.cacheVolume, if set, has a known medium,
+// a positive size limit and a valid mount path that doesn't clash with the
+// other operand volumes.
+func validateCacheVolume(edns *operatorv1.ExternalDNS) error {
+	cache := edns.Spec.CacheVolume
+	if cache == nil {
+		return nil
+	}
+	if cache.Medium != corev1.StorageMediumDefault && cache.Medium != corev1.StorageMediumMemory {
+		return fmt.Errorf("invalid cacheVolume.medium %q: must be empty or %q", cache.Medium, corev1.StorageMediumMemory)
+	}
+	if cache.SizeLimit != nil && cache.SizeLimit.Sign() <= 0 {
+		return fmt.Errorf("invalid cacheVolume.sizeLimit %s: must be positive", cache.SizeLimit.String())
+	}
+	if len(cache.MountPath) != 0 && (!path.IsAbs(cache.MountPath) || path.Clean(cache.MountPath) == "/") {
+		return fmt.Errorf("invalid cacheVolume.mountPath %q: must be an absolute path other than /", cache.MountPath)
+	}
+	_, mount := cacheVolume(cache)
+	if files := edns.Spec.Provider.CredentialsFiles; files != nil && path.Clean(mount.MountPath) == path.Clean(credentialsFilesMountPath(files)) {
+		return fmt.Errorf("cacheVolume.mountPath %q is the mount path of provider.credentialsFiles", mount.MountPath)
+	}
+	if token := edns.Spec.Provider.ServiceAccountToken; token != nil {
+		_, tokenMount, _ := serviceAccountTokenVolume(token)
+		if path.Clean(mount.MountPath) == path.Clean(tokenMount.MountPath) {
+			return fmt.Errorf("cacheVolume.mountPath %q is the mount path of provider.serviceAccountToken", mount.MountPath)
+		}
+	}
+	return nil
+}
+
+// validateCredentialsSidecar ensures provider.credentialsSidecar, if set,
+// has an image and a credentials file within a shared volume whose mount
+// path doesn't clash with the other operand volumes, and that the operand
+// runs continuously, since the sidecar never exits.
+func validateCredentialsSidecar(edns *operatorv1.ExternalDNS) error {
+	sidecar := edns.Spec.Provider.CredentialsSidecar
+	if sidecar == nil {
+		return nil
+	}
+	if len(sidecar.Container.Name) != 0 && sidecar.Container.Name != credentialsSidecarName {
+		return fmt.Errorf("provider.credentialsSidecar.container name %q must be empty or %q", sidecar.Container.Name, credentialsSidecarName)
+	}
+	if len(sidecar.Container.Image) == 0 {
+		return fmt.Errorf("provider.credentialsSidecar.container requires an image")
+	}
+	if len(sidecar.MountPath) != 0 && (!path.IsAbs(sidecar.MountPath) || path.Clean(sidecar.MountPath) == "/") {
+		return fmt.Errorf("invalid provider.credentialsSidecar.mountPath %q: must be an absolute path other than /", sidecar.MountPath)
+	}
+	file := path.Clean(sidecar.CredentialsFile)
+	if len(sidecar.CredentialsFile) == 0 || path.IsAbs(file) || file == "." || file == ".." || strings.HasPrefix(file, "../") {
+		return fmt.Errorf("invalid provider.credentialsSidecar.credentialsFile %q: must be a path within mountPath", sidecar.CredentialsFile)
+	}
+	mountPath := path.Clean(credentialsSidecarMountPath(sidecar))
+	otherMounts := map[string]string{}
+	if files := edns.Spec.Provider.CredentialsFiles; files != nil {
+		otherMounts["provider.credentialsFiles"] = credentialsFilesMountPath(files)
+	}
+	if token := edns.Spec.Provider.ServiceAccountToken; token != nil {
+		_, tokenMount, _ := serviceAccountTokenVolume(token)
+		otherMounts["provider.serviceAccountToken"] = tokenMount.MountPath
+	}
+	if cache := edns.Spec.CacheVolume; cache != nil {
+		_, cacheMount := cacheVolume(cache)
+		otherMounts["cacheVolume"] = cacheMount.MountPath
+	}
+	for field, other := range otherMounts {
+		if mountPath == path.Clean(other) {
+			return fmt.Errorf("provider.credentialsSidecar.mountPath %q is the mount path of %s", mountPath, field)
+		}
+	}
+	for _, m := range sidecar.Container.VolumeMounts {
+		if m.Name == credentialsSidecarVolumeName || path.Clean(m.MountPath) == mountPath {
+			return fmt.Errorf("provider.credentialsSidecar.container volume mount %q clashes with the shared credentials volume", m.Name)
+		}
+	}
+	if edns.Spec.RunMode == operatorv1.OnceRunMode {
+		return fmt.Errorf("provider.credentialsSidecar cannot be used with run mode %q", operatorv1.OnceRunMode)
+	}
+	if edns.Spec.CleanupRecordsOnDeletion {
+		return fmt.Errorf("provider.credentialsSidecar cannot be used with cleanupRecordsOnDeletion")
+	}
+	return nil
+}
+
+// validateExperimentalArgs ensures spec.experimentalArgs are flags that
+// aren't managed by the operator, in either their plain or "no-" form.
+func validateExperimentalArgs(edns *operatorv1.ExternalDNS) error {
+	for _, arg := range edns.Spec.ExperimentalArgs {
+		if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+			return fmt.Errorf("invalid experimentalArgs entry %q: must be a --flag", arg)
+		}
+		name := strings.SplitN(strings.TrimPrefix(arg, "--"), "=", 2)[0]
+		if slice.ContainsString(managedFlagNames, name) || slice.ContainsString(managedFlagNames, strings.TrimPrefix(name, "no-")) {
+			return fmt.Errorf("experimentalArgs entry %q sets flag --%s, which is managed by the operator", arg, name)
+		}
+	}
+	return nil
+}
+
+// validateRevisionHistoryLimit ensures spec.revisionHistoryLimit, if set,
+// isn't negative.
+func validateRevisionHistoryLimit(edns *operatorv1.ExternalDNS) error {
+	if l := edns.Spec.RevisionHistoryLimit; l != nil && *l < 0 {
+		return fmt.Errorf("invalid revisionHistoryLimit %d: must not be negative", *l)
+	}
+	return nil
+}
+
+// validateAWSCloudMap ensures provider.awsCloudMap is only set with the aws
+// provider and has a valid Cloud Map service type.
+func validateAWSCloudMap(edns *operatorv1.ExternalDNS) error {
+	cloudMap := edns.Spec.Provider.AWSCloudMap
+	if cloudMap == nil {
+		return nil
+	}
+	if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.AWSProvider {
+		return fmt.Errorf("provider.awsCloudMap is only supported with the %q provider", operatorv1.AWSProvider)
+	}
+	switch cloudMap.ServiceType {
+	case "", operatorv1.DNSAWSCloudMapServiceType, operatorv1.DNSHTTPAWSCloudMapServiceType, operatorv1.HTTPAWSCloudMapServiceType:
+	default:
+		return fmt.Errorf("invalid provider.awsCloudMap.serviceType %q: must be one of %q, %q or %q", cloudMap.ServiceType,
+			operatorv1.DNSAWSCloudMapServiceType, operatorv1.DNSHTTPAWSCloudMapServiceType, operatorv1.HTTPAWSCloudMapServiceType)
+	}
+	return nil
+}
+
+// validateInMemoryProvider ensures the in-memory provider isn't combined
+// with provider credentials or options of a real DNS provider.
+func validateInMemoryProvider(edns *operatorv1.ExternalDNS) error {
+	if edns.Status.ProviderType == nil || *edns.Status.ProviderType != operatorv1.InMemoryProvider {
+		return nil
+	}
+	for _, arg := range edns.Spec.Provider.Args {
+		for _, prefix := range []string{"--aws-", "--azure-", "--google-"} {
+			if strings.HasPrefix(arg, prefix) {
+				return fmt.Errorf("provider arg %q cannot be used with the %s provider", arg, operatorv1.InMemoryProvider)
+			}
+		}
+	}
+	if len(edns.Spec.Provider.Env) != 0 {
+		return fmt.Errorf("provider env cannot be used with the %s provider", operatorv1.InMemoryProvider)
+	}
+	if edns.Spec.Provider.AWSAPIRetries != nil {
+		return fmt.Errorf("awsAPIRetries cannot be used with the %s provider", operatorv1.InMemoryProvider)
+	}
+	return nil
+}
+
+// validateProviderEnvSecrets ensures the secrets referenced by
+// spec.provider.env exist in the operand namespace.
+func (r *reconciler) validateProviderEnvSecrets(edns *operatorv1.ExternalDNS) error {
+	namespace := ExternalDNSDeploymentNamespacedName(r.OperandNamespace, edns).Namespace
+	for _, env := range edns.Spec.Provider.Env {
+		if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+			continue
+		}
+		ref := env.ValueFrom.SecretKeyRef
+		if ref.Optional != nil && *ref.Optional {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := r.kclient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Errorf("secret %s/%s referenced by provider env variable %q does not exist", namespace, ref.Name, env.Name)
+			}
+			return fmt.Errorf("failed to get secret %s/%s: %v", namespace, ref.Name, err)
+		}
+		if _, ok := secret.Data[ref.Key]; !ok {
+			return fmt.Errorf("secret %s/%s referenced by provider env variable %q has no key %q", namespace, ref.Name, env.Name, ref.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,876 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateMetricsAddress(t *testing.T) {
+	testCases := []struct {
+		address   string
+		expectErr bool
+	}{
+		{address: ""},
+		{address: ":7979"},
+		{address: "0.0.0.0:8080"},
+		{address: "127.0.0.1:9090"},
+		{address: "[::1]:7979"},
+		{address: "localhost:7979"},
+		{address: "7979", expectErr: true},
+		{address: ":", expectErr: true},
+		{address: ":0", expectErr: true},
+		{address: ":65536", expectErr: true},
+		{address: ":metrics", expectErr: true},
+		{address: "::1:7979", expectErr: true},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{MetricsAddress: tc.address}}
+		if err := validateMetricsAddress(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.address, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateRunMode(t *testing.T) {
+	testCases := []struct {
+		description   string
+		runMode       operatorv1.RunMode
+		restartPolicy corev1.RestartPolicy
+		expectErr     bool
+	}{
+		{
+			description: "defaults",
+		},
+		{
+			description:   "continuous with Always",
+			runMode:       operatorv1.ContinuousRunMode,
+			restartPolicy: corev1.RestartPolicyAlways,
+		},
+		{
+			description:   "continuous with Never",
+			runMode:       operatorv1.ContinuousRunMode,
+			restartPolicy: corev1.RestartPolicyNever,
+			expectErr:     true,
+		},
+		{
+			description:   "once with OnFailure",
+			runMode:       operatorv1.OnceRunMode,
+			restartPolicy: corev1.RestartPolicyOnFailure,
+		},
+		{
+			description:   "once with Always",
+			runMode:       operatorv1.OnceRunMode,
+			restartPolicy: corev1.RestartPolicyAlways,
+			expectErr:     true,
+		},
+		{
+			description: "unknown run mode",
+			runMode:     operatorv1.RunMode("sometimes"),
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			Spec: operatorv1.ExternalDNSSpec{
+				RunMode:       tc.runMode,
+				RestartPolicy: tc.restartPolicy,
+			},
+		}
+		if err := validateRunMode(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateAWSAssumeRole(t *testing.T) {
+	testCases := []struct {
+		description string
+		role        string
+		externalID  string
+		expectErr   bool
+	}{
+		{
+			description: "unset",
+		},
+		{
+			description: "role with external id",
+			role:        "arn:aws:iam::123456789012:role/dns",
+			externalID:  "abc",
+		},
+		{
+			description: "external id without role",
+			externalID:  "abc",
+			expectErr:   true,
+		},
+		{
+			description: "not an arn",
+			role:        "dns",
+			expectErr:   true,
+		},
+		{
+			description: "not a role arn",
+			role:        "arn:aws:iam::123456789012:user/dns",
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			Spec: operatorv1.ExternalDNSSpec{
+				Provider: operatorv1.ProviderSpec{
+					AWSAssumeRole:           tc.role,
+					AWSAssumeRoleExternalID: tc.externalID,
+				},
+			},
+		}
+		if err := validateAWSAssumeRole(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateConnectorSourceServer(t *testing.T) {
+	connector := operatorv1.ConnectorType
+	testCases := []struct {
+		description string
+		sources     []*operatorv1.SourceType
+		server      string
+		expectErr   bool
+	}{
+		{
+			description: "unset",
+		},
+		{
+			description: "connector source with server",
+			sources:     []*operatorv1.SourceType{&connector},
+			server:      "discovery.example.com:8080",
+		},
+		{
+			description: "connector source without server",
+			sources:     []*operatorv1.SourceType{&connector},
+			expectErr:   true,
+		},
+		{
+			description: "server without connector source",
+			server:      "discovery.example.com:8080",
+			expectErr:   true,
+		},
+		{
+			description: "server without port",
+			sources:     []*operatorv1.SourceType{&connector},
+			server:      "discovery.example.com",
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			Spec: operatorv1.ExternalDNSSpec{
+				Sources:               tc.sources,
+				ConnectorSourceServer: tc.server,
+			},
+		}
+		if err := validateConnectorSourceServer(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateAWSDynamoDB(t *testing.T) {
+	testCases := []struct {
+		description string
+		dynamoDB    *operatorv1.AWSDynamoDBRegistrySpec
+		expectErr   bool
+	}{
+		{
+			description: "unset",
+		},
+		{
+			description: "govcloud region with vpc endpoint",
+			dynamoDB: &operatorv1.AWSDynamoDBRegistrySpec{
+				Region:   "us-gov-west-1",
+				Endpoint: "https://vpce-123.dynamodb.us-gov-west-1.vpce.amazonaws.com",
+			},
+		},
+		{
+			description: "invalid region",
+			dynamoDB:    &operatorv1.AWSDynamoDBRegistrySpec{Region: "US East"},
+			expectErr:   true,
+		},
+		{
+			description: "endpoint without scheme",
+			dynamoDB:    &operatorv1.AWSDynamoDBRegistrySpec{Endpoint: "dynamodb.local:8000"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		if err := validateAWSDynamoDB(tc.dynamoDB); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateHostnameOptions(t *testing.T) {
+	testCases := []struct {
+		description string
+		spec        operatorv1.ExternalDNSSpec
+		expectErr   bool
+	}{
+		{
+			description: "unset",
+		},
+		{
+			description: "template with combine",
+			spec:        operatorv1.ExternalDNSSpec{FQDNTemplate: "{{.Name}}.example.com", CombineFQDNAnnotation: true},
+		},
+		{
+			description: "combine without template",
+			spec:        operatorv1.ExternalDNSSpec{CombineFQDNAnnotation: true},
+			expectErr:   true,
+		},
+		{
+			description: "ignore without template",
+			spec:        operatorv1.ExternalDNSSpec{IgnoreHostnameAnnotation: true},
+			expectErr:   true,
+		},
+		{
+			description: "ignore and combine",
+			spec: operatorv1.ExternalDNSSpec{
+				FQDNTemplate:             "{{.Name}}.example.com",
+				IgnoreHostnameAnnotation: true,
+				CombineFQDNAnnotation:    true,
+			},
+			expectErr: true,
+		},
+		{
+			description: "invalid template",
+			spec:        operatorv1.ExternalDNSSpec{FQDNTemplate: "{{.Name"},
+			expectErr:   true,
+		},
+		{
+			description: "combine with template in provider args",
+			spec: operatorv1.ExternalDNSSpec{
+				CombineFQDNAnnotation: true,
+				Provider:              operatorv1.ProviderSpec{Args: []string{"--fqdn-template={{.Name}}.example.com"}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: tc.spec}
+		if err := validateHostnameOptions(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateSyncIntervals(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	testCases := []struct {
+		description string
+		spec        operatorv1.ExternalDNSSpec
+		expectErr   bool
+	}{
+		{
+			description: "unset",
+		},
+		{
+			description: "intervals and cache time",
+			spec: operatorv1.ExternalDNSSpec{
+				Interval:             duration(5 * time.Minute),
+				MinEventSyncInterval: duration(30 * time.Second),
+				Provider:             operatorv1.ProviderSpec{CacheTime: duration(0)},
+			},
+		},
+		{
+			description: "zero interval",
+			spec:        operatorv1.ExternalDNSSpec{Interval: duration(0)},
+			expectErr:   true,
+		},
+		{
+			description: "negative min event sync interval",
+			spec:        operatorv1.ExternalDNSSpec{MinEventSyncInterval: duration(-time.Second)},
+			expectErr:   true,
+		},
+		{
+			description: "min event sync interval exceeding interval",
+			spec: operatorv1.ExternalDNSSpec{
+				Interval:             duration(time.Minute),
+				MinEventSyncInterval: duration(2 * time.Minute),
+			},
+			expectErr: true,
+		},
+		{
+			description: "negative cache time",
+			spec:        operatorv1.ExternalDNSSpec{Provider: operatorv1.ProviderSpec{CacheTime: duration(-time.Second)}},
+			expectErr:   true,
+		},
+		{
+			description: "events with a full resync interval",
+			spec: operatorv1.ExternalDNSSpec{
+				MinEventSyncInterval: duration(10 * time.Second),
+				Events:               &operatorv1.EventsSpec{FullResyncInterval: duration(15 * time.Minute)},
+			},
+		},
+		{
+			description: "full resync interval with interval",
+			spec: operatorv1.ExternalDNSSpec{
+				Interval: duration(time.Minute),
+				Events:   &operatorv1.EventsSpec{FullResyncInterval: duration(15 * time.Minute)},
+			},
+			expectErr: true,
+		},
+		{
+			description: "zero full resync interval",
+			spec:        operatorv1.ExternalDNSSpec{Events: &operatorv1.EventsSpec{FullResyncInterval: duration(0)}},
+			expectErr:   true,
+		},
+		{
+			description: "min event sync interval exceeding full resync interval",
+			spec: operatorv1.ExternalDNSSpec{
+				MinEventSyncInterval: duration(2 * time.Minute),
+				Events:               &operatorv1.EventsSpec{FullResyncInterval: duration(time.Minute)},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: tc.spec}
+		if err := validateSyncIntervals(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateAWSResourceTags(t *testing.T) {
+	aws := operatorv1.AWSProvider
+	azure := operatorv1.AzureProvider
+	testCases := []struct {
+		description string
+		provider    *operatorv1.ProviderType
+		tags        map[string]string
+		metadata    map[string]string
+		expectErr   bool
+	}{
+		{
+			description: "no tags",
+			provider:    &azure,
+		},
+		{
+			description: "valid tags",
+			provider:    &aws,
+			tags:        map[string]string{"cost-center": "1234", "owner": "dns team@example.com", "empty": ""},
+			metadata:    map[string]string{"serviceTag/owner": "dns team@example.com"},
+		},
+		{
+			description: "non-aws provider",
+			provider:    &azure,
+			tags:        map[string]string{"owner": "me"},
+			expectErr:   true,
+		},
+		{
+			description: "reserved prefix",
+			provider:    &aws,
+			tags:        map[string]string{"AWS:owner": "me"},
+			expectErr:   true,
+		},
+		{
+			description: "invalid characters",
+			provider:    &aws,
+			tags:        map[string]string{"owner": "me!"},
+			expectErr:   true,
+		},
+		{
+			description: "conflicting metadata",
+			provider:    &aws,
+			tags:        map[string]string{"owner": "me"},
+			metadata:    map[string]string{"serviceTag/owner": "you"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{
+			Spec:   operatorv1.ExternalDNSSpec{Provider: operatorv1.ProviderSpec{AWSResourceTags: tc.tags, Metadata: tc.metadata}},
+			Status: operatorv1.ExternalDNSStatus{ProviderType: tc.provider},
+		}
+		if err := validateAWSResourceTags(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateDomainFilters(t *testing.T) {
+	testCases := []struct {
+		description string
+		spec        operatorv1.ExternalDNSSpec
+		expectErr   bool
+	}{
+		{
+			description: "no filters",
+		},
+		{
+			description: "domain filter with excluded domains",
+			spec: operatorv1.ExternalDNSSpec{
+				ExcludeDomains: []string{"internal.example.com"},
+				Provider:       operatorv1.ProviderSpec{Args: []string{"--domain-filter=example.com"}},
+			},
+		},
+		{
+			description: "regex filter with regex exclusion",
+			spec: operatorv1.ExternalDNSSpec{
+				RegexDomainFilter:    `.*\.example\.com$`,
+				RegexDomainExclusion: `^internal\.`,
+			},
+		},
+		{
+			description: "regex filter with regex exclusion in provider args",
+			spec: operatorv1.ExternalDNSSpec{
+				RegexDomainFilter: `.*\.example\.com$`,
+				Provider:          operatorv1.ProviderSpec{Args: []string{`--regex-domain-exclusion=^internal\.`}},
+			},
+		},
+		{
+			description: "regex filter with domain filter",
+			spec: operatorv1.ExternalDNSSpec{
+				RegexDomainFilter: `.*\.example\.com$`,
+				Provider:          operatorv1.ProviderSpec{Args: []string{"--domain-filter=example.com"}},
+			},
+			expectErr: true,
+		},
+		{
+			description: "regex filter with excluded domains",
+			spec: operatorv1.ExternalDNSSpec{
+				RegexDomainFilter: `.*\.example\.com$`,
+				ExcludeDomains:    []string{"internal.example.com"},
+			},
+			expectErr: true,
+		},
+		{
+			description: "regex filter in provider args with excluded domains args",
+			spec: operatorv1.ExternalDNSSpec{
+				Provider: operatorv1.ProviderSpec{Args: []string{`--regex-domain-filter=.*\.example\.com$`, "--exclude-domains=internal.example.com"}},
+			},
+			expectErr: true,
+		},
+		{
+			description: "regex exclusion without regex filter",
+			spec:        operatorv1.ExternalDNSSpec{RegexDomainExclusion: `^internal\.`},
+			expectErr:   true,
+		},
+		{
+			description: "invalid regex filter",
+			spec:        operatorv1.ExternalDNSSpec{RegexDomainFilter: "("},
+			expectErr:   true,
+		},
+		{
+			description: "invalid excluded domain",
+			spec:        operatorv1.ExternalDNSSpec{ExcludeDomains: []string{"not a domain"}},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: tc.spec}
+		if err := validateDomainFilters(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateExperimentalArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        []string
+		expectErr   bool
+	}{
+		{"none", nil, false},
+		{"unmanaged flags", []string{"--txt-cache-interval=1h", "--txt-encrypt-enabled=true"}, false},
+		{"not a flag", []string{"events"}, true},
+		{"managed flag", []string{"--provider=aws"}, true},
+		{"negated managed flag", []string{"--no-exclude-unschedulable"}, true},
+		{"managed flag without value", []string{"--once"}, true},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{ExperimentalArgs: tc.args}}
+		if err := validateExperimentalArgs(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateReplicas(t *testing.T) {
+	zero, two, negative := int32(0), int32(2), int32(-1)
+	autoscaling := &operatorv1.AutoscalingSpec{MaxReplicas: 3}
+	testCases := []struct {
+		description string
+		replicas    *int32
+		autoscaling *operatorv1.AutoscalingSpec
+		expectErr   bool
+	}{
+		{"unset", nil, nil, false},
+		{"scaled down", &zero, nil, false},
+		{"scaled up", &two, nil, false},
+		{"negative", &negative, nil, true},
+		{"autoscaled", nil, autoscaling, false},
+		{"replicas with autoscaling", &two, autoscaling, true},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{Replicas: tc.replicas, Autoscaling: tc.autoscaling}}
+		if err := validateReplicas(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateAWSCloudMap(t *testing.T) {
+	aws, google := operatorv1.AWSProvider, operatorv1.GoogleProvider
+	testCases := []struct {
+		description string
+		provider    operatorv1.ProviderType
+		cloudMap    *operatorv1.AWSCloudMapSpec
+		expectErr   bool
+	}{
+		{"unset", google, nil, false},
+		{"defaults", aws, &operatorv1.AWSCloudMapSpec{}, false},
+		{"http services", aws, &operatorv1.AWSCloudMapSpec{ServiceType: operatorv1.HTTPAWSCloudMapServiceType}, false},
+		{"dns services with cleanup", aws, &operatorv1.AWSCloudMapSpec{ServiceType: operatorv1.DNSAWSCloudMapServiceType, ServiceCleanup: true}, false},
+		{"unknown service type", aws, &operatorv1.AWSCloudMapSpec{ServiceType: "http"}, true},
+		{"other provider", google, &operatorv1.AWSCloudMapSpec{ServiceType: operatorv1.HTTPAWSCloudMapServiceType}, true},
+	}
+	for _, tc := range testCases {
+		provider := tc.provider
+		edns := &operatorv1.ExternalDNS{
+			Spec:   operatorv1.ExternalDNSSpec{Provider: operatorv1.ProviderSpec{AWSCloudMap: tc.cloudMap}},
+			Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		if err := validateAWSCloudMap(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateGoogleBatchChange(t *testing.T) {
+	zero, size := int32(0), int32(1000)
+	testCases := []struct {
+		description string
+		provider    operatorv1.ProviderSpec
+		expectErr   bool
+	}{
+		{"unset", operatorv1.ProviderSpec{}, false},
+		{"positive", operatorv1.ProviderSpec{GoogleBatchChangeSize: &size, GoogleBatchChangeInterval: &metav1.Duration{Duration: time.Second}}, false},
+		{"zero size", operatorv1.ProviderSpec{GoogleBatchChangeSize: &zero}, true},
+		{"zero interval", operatorv1.ProviderSpec{GoogleBatchChangeInterval: &metav1.Duration{}}, true},
+		{"negative interval", operatorv1.ProviderSpec{GoogleBatchChangeInterval: &metav1.Duration{Duration: -time.Second}}, true},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{Provider: tc.provider}}
+		if err := validateGoogleBatchChange(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateServiceSourceOptions(t *testing.T) {
+	service, ingress := operatorv1.ServiceType, operatorv1.IngressType
+	testCases := []struct {
+		description string
+		source      operatorv1.SourceType
+		spec        operatorv1.ExternalDNSSpec
+		expectErr   bool
+	}{
+		{"defaults", ingress, operatorv1.ExternalDNSSpec{}, false},
+		{"not ready addresses with service source", service, operatorv1.ExternalDNSSpec{AlwaysPublishNotReadyAddresses: true}, false},
+		{"not ready addresses without service source", ingress, operatorv1.ExternalDNSSpec{AlwaysPublishNotReadyAddresses: true}, true},
+		{"host ip of headless services", service, operatorv1.ExternalDNSSpec{PublishHostIP: true, ServiceTypeFilter: []corev1.ServiceType{corev1.ServiceTypeClusterIP}}, false},
+		{"host ip without cluster ip services", service, operatorv1.ExternalDNSSpec{PublishHostIP: true, ServiceTypeFilter: []corev1.ServiceType{corev1.ServiceTypeNodePort}}, true},
+	}
+	for _, tc := range testCases {
+		source := tc.source
+		edns := &operatorv1.ExternalDNS{Spec: tc.spec}
+		edns.Spec.Sources = []*operatorv1.SourceType{&source}
+		if err := validateServiceSourceOptions(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	testCases := []struct {
+		description string
+		resources   corev1.ResourceRequirements
+		expectErr   bool
+	}{
+		{"unset", corev1.ResourceRequirements{}, false},
+		{"requests only", corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}}, false},
+		{
+			"requests within limits",
+			corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+			},
+			false,
+		},
+		{
+			"requests exceed limits",
+			corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			},
+			true,
+		},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{Resources: tc.resources}}
+		if err := validateResources(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateServiceAccountToken(t *testing.T) {
+	aws, inmemory := operatorv1.AWSProvider, operatorv1.InMemoryProvider
+	testCases := []struct {
+		description string
+		provider    operatorv1.ProviderType
+		spec        operatorv1.ProviderSpec
+		expectErr   bool
+	}{
+		{"unset", inmemory, operatorv1.ProviderSpec{}, false},
+		{"audience", aws, operatorv1.ProviderSpec{ServiceAccountToken: &operatorv1.ProviderServiceAccountToken{Audience: "sts.amazonaws.com"}}, false},
+		{"missing audience", aws, operatorv1.ProviderSpec{ServiceAccountToken: &operatorv1.ProviderServiceAccountToken{}}, true},
+		{"unsupported provider", inmemory, operatorv1.ProviderSpec{ServiceAccountToken: &operatorv1.ProviderServiceAccountToken{Audience: "test"}}, true},
+		{"relative mount path", aws, operatorv1.ProviderSpec{ServiceAccountToken: &operatorv1.ProviderServiceAccountToken{Audience: "sts.amazonaws.com", MountPath: "tokens"}}, true},
+		{
+			"credentials files mount path",
+			aws,
+			operatorv1.ProviderSpec{
+				CredentialsFiles:    &operatorv1.ProviderCredentialsFiles{SecretName: "creds"},
+				ServiceAccountToken: &operatorv1.ProviderServiceAccountToken{Audience: "sts.amazonaws.com", MountPath: defaultCredentialsFilesMountPath + "/"},
+			},
+			true,
+		},
+	}
+	for _, tc := range testCases {
+		provider := tc.provider
+		edns := &operatorv1.ExternalDNS{
+			Spec:   operatorv1.ExternalDNSSpec{Provider: tc.spec},
+			Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		if err := validateServiceAccountToken(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateIngressClassFilter(t *testing.T) {
+	service, ingress := operatorv1.ServiceType, operatorv1.IngressType
+	testCases := []struct {
+		description string
+		source      operatorv1.SourceType
+		filter      []string
+		expectErr   bool
+	}{
+		{"unset", service, nil, false},
+		{"ingress classes", ingress, []string{"public", "openshift-default"}, false},
+		{"without ingress source", service, []string{"public"}, true},
+		{"empty entry", ingress, []string{""}, true},
+		{"invalid entry", ingress, []string{"Public Class"}, true},
+	}
+	for _, tc := range testCases {
+		source := tc.source
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{
+			Sources:            []*operatorv1.SourceType{&source},
+			IngressClassFilter: tc.filter,
+		}}
+		if err := validateIngressClassFilter(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateRegistryTXTOptions(t *testing.T) {
+	testCases := []struct {
+		description string
+		registry    operatorv1.RegistryType
+		prefix      string
+		owner       string
+		expectErr   bool
+	}{
+		{"defaults", "", "", "", false},
+		{"prefix with default registry", "", "dns-", "", false},
+		{"prefix and owner with txt registry", operatorv1.TXTRegistryType, "dns-", "legacy-owner", false},
+		{"prefix with noop registry", operatorv1.NoopRegistryType, "dns-", "", true},
+		{"owner with noop registry", operatorv1.NoopRegistryType, "", "legacy-owner", true},
+		{"prefix with whitespace", "", "dns -", "", true},
+		{"owner with whitespace", "", "", "legacy owner", true},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{
+			Registry:   tc.registry,
+			TXTPrefix:  tc.prefix,
+			TXTOwnerID: tc.owner,
+		}}
+		if err := validateRegistry(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateAWSZoneMatchParent(t *testing.T) {
+	aws, azure := operatorv1.AWSProvider, operatorv1.AzureProvider
+	child := &configv1.DNSZone{ID: "Z2CHILD"}
+	tagged := &configv1.DNSZone{Tags: map[string]string{"Name": "apps"}}
+	testCases := []struct {
+		description string
+		provider    operatorv1.ProviderType
+		matchParent bool
+		zoneFilter  []*configv1.DNSZone
+		expectErr   bool
+	}{
+		{"unset", azure, false, nil, false},
+		{"child zone by ID", aws, true, []*configv1.DNSZone{tagged, child}, false},
+		{"non-aws provider", azure, true, []*configv1.DNSZone{child}, true},
+		{"no zone filter", aws, true, nil, true},
+		{"zone filter by tags only", aws, true, []*configv1.DNSZone{tagged}, true},
+	}
+	for _, tc := range testCases {
+		provider := tc.provider
+		edns := &operatorv1.ExternalDNS{
+			Spec: operatorv1.ExternalDNSSpec{Provider: operatorv1.ProviderSpec{
+				AWSZoneMatchParent: tc.matchParent,
+				ZoneFilter:         tc.zoneFilter,
+			}},
+			Status: operatorv1.ExternalDNSStatus{ProviderType: &provider},
+		}
+		if err := validateAWSZoneMatchParent(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateLabelFilter(t *testing.T) {
+	service, node := operatorv1.ServiceType, operatorv1.NodeType
+	testCases := []struct {
+		description string
+		namespace   string
+		filter      string
+		source      operatorv1.SourceType
+		expectErr   bool
+	}{
+		{"unset", "", "", node, false},
+		{"tenant namespace", "team-a", "team=a,tier in (web,api)", service, false},
+		{"invalid selector", "team-a", "team=(a", service, true},
+		{"without namespace", "", "team=a", service, true},
+		{"with node source", "team-a", "team=a", node, true},
+	}
+	for _, tc := range testCases {
+		source := tc.source
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{
+			Namespace:   tc.namespace,
+			LabelFilter: tc.filter,
+			Sources:     []*operatorv1.SourceType{&source},
+		}}
+		if err := validateLabelFilter(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateCacheVolume(t *testing.T) {
+	positive, zero := resource.MustParse("64Mi"), resource.MustParse("0")
+	testCases := []struct {
+		description string
+		cache       *operatorv1.OperandCacheVolume
+		files       *operatorv1.ProviderCredentialsFiles
+		expectErr   bool
+	}{
+		{"unset", nil, nil, false},
+		{"defaults", &operatorv1.OperandCacheVolume{}, nil, false},
+		{"memory with size limit", &operatorv1.OperandCacheVolume{MountPath: "/cache", Medium: corev1.StorageMediumMemory, SizeLimit: &positive}, nil, false},
+		{"unknown medium", &operatorv1.OperandCacheVolume{Medium: corev1.StorageMediumHugePages}, nil, true},
+		{"zero size limit", &operatorv1.OperandCacheVolume{SizeLimit: &zero}, nil, true},
+		{"relative mount path", &operatorv1.OperandCacheVolume{MountPath: "cache"}, nil, true},
+		{"root mount path", &operatorv1.OperandCacheVolume{MountPath: "/"}, nil, true},
+		{"credentials files mount path", &operatorv1.OperandCacheVolume{MountPath: "/etc/externaldns/credentials/"},
+			&operatorv1.ProviderCredentialsFiles{SecretName: "creds"}, true},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{
+			CacheVolume: tc.cache,
+			Provider:    operatorv1.ProviderSpec{CredentialsFiles: tc.files},
+		}}
+		if err := validateCacheVolume(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateCredentialsSidecar(t *testing.T) {
+	newSidecar := func(mountPath, file string) *operatorv1.ProviderCredentialsSidecar {
+		return &operatorv1.ProviderCredentialsSidecar{
+			Container:       corev1.Container{Image: "vault:1.4"},
+			MountPath:       mountPath,
+			CredentialsFile: file,
+		}
+	}
+	noImage := newSidecar("", "aws-credentials")
+	noImage.Container.Image = ""
+	renamed := newSidecar("", "aws-credentials")
+	renamed.Container.Name = "vault-agent"
+	clashingMount := newSidecar("/vault", "aws-credentials")
+	clashingMount.Container.VolumeMounts = []corev1.VolumeMount{{Name: "config", MountPath: "/vault/"}}
+	testCases := []struct {
+		description string
+		sidecar     *operatorv1.ProviderCredentialsSidecar
+		files       *operatorv1.ProviderCredentialsFiles
+		runMode     operatorv1.RunMode
+		expectErr   bool
+	}{
+		{"unset", nil, nil, "", false},
+		{"defaults", newSidecar("", "aws-credentials"), nil, "", false},
+		{"nested credentials file", newSidecar("/vault", "secrets/aws-credentials"), nil, "", false},
+		{"no image", noImage, nil, "", true},
+		{"other container name", renamed, nil, "", true},
+		{"relative mount path", newSidecar("vault", "aws-credentials"), nil, "", true},
+		{"no credentials file", newSidecar("", ""), nil, "", true},
+		{"absolute credentials file", newSidecar("", "/aws-credentials"), nil, "", true},
+		{"credentials file outside mount path", newSidecar("", "../aws-credentials"), nil, "", true},
+		{"credentials files mount path", newSidecar("/etc/externaldns/credentials", "aws-credentials"),
+			&operatorv1.ProviderCredentialsFiles{SecretName: "creds"}, "", true},
+		{"clashing sidecar mount", clashingMount, nil, "", true},
+		{"once run mode", newSidecar("", "aws-credentials"), nil, operatorv1.OnceRunMode, true},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{
+			RunMode:  tc.runMode,
+			Provider: operatorv1.ProviderSpec{CredentialsSidecar: tc.sidecar, CredentialsFiles: tc.files},
+		}}
+		if err := validateCredentialsSidecar(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
+
+func TestValidateExcessReplicasThreshold(t *testing.T) {
+	zero, one, five := int32(0), int32(1), int32(5)
+	testCases := []struct {
+		description string
+		threshold   *int32
+		expectErr   bool
+	}{
+		{"unset", nil, false},
+		{"one", &one, false},
+		{"five", &five, false},
+		{"zero", &zero, true},
+	}
+	for _, tc := range testCases {
+		edns := &operatorv1.ExternalDNS{Spec: operatorv1.ExternalDNSSpec{ExcessReplicasThreshold: tc.threshold}}
+		if err := validateExcessReplicasThreshold(edns); (err != nil) != tc.expectErr {
+			t.Errorf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+	}
+}
@@ -22,27 +22,72 @@ import (
 	operatorclient "github.com/danehans/external-dns-operator/pkg/operator/client"
 	operatorconfig "github.com/danehans/external-dns-operator/pkg/operator/config"
 	operatorcontroller "github.com/danehans/external-dns-operator/pkg/operator/controller"
+	"github.com/danehans/external-dns-operator/pkg/util/route53"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 
 	"k8s.io/client-go/rest"
 
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/util/wait"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// defaultExternalDNSResyncInterval is how often the default
+	// externaldnses are ensured to exist.
+	defaultExternalDNSResyncInterval = 1 * time.Minute
+
+	// defaultExternalDNSMinBackoff and defaultExternalDNSMaxBackoff bound
+	// the delay before retrying to ensure the default externaldnses.
+	defaultExternalDNSMinBackoff = 10 * time.Second
+	defaultExternalDNSMaxBackoff = 10 * time.Minute
+
+	// defaultAWSZonesCacheDuration is how long the operands of the default
+	// externaldnses on AWS cache the list of Route 53 hosted zones. Listing
+	// the hosted zones and their tags makes up most of the Route 53 calls
+	// of a sync, so the cache keeps a throttled Route 53 API from being
+	// exhausted. It is only set with operand flag validation, since older
+	// ExternalDNS images don't recognize --aws-zones-cache-duration.
+	defaultAWSZonesCacheDuration = 10 * time.Minute
+
+	// taggingAPICheckTimeout bounds the startup check of the AWS resource
+	// groups tagging API.
+	taggingAPICheckTimeout = 30 * time.Second
 )
 
+var (
+	// defaultExternalDNSFailures is the number of consecutive failures to
+	// ensure the default externaldnses, so persistent failures can be
+	// alerted on.
+	defaultExternalDNSFailures = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "externaldns_operator_default_externaldns_failures",
+		Help: "Number of consecutive failures to ensure the default ExternalDNS instances exist.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(defaultExternalDNSFailures)
+}
+
 // Operator is the scaffolding for the externaldns operator. It sets up dependencies
 // and defines the topology of the operator and its managed components, wiring
 // them together.
@@ -53,7 +98,10 @@ type Operator struct {
 	kclient   client.Client
 	dnsConfig *configv1.DNS
 	provider  operatorv1.ProviderType
-	tClient *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
+	tClient   *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
+
+	resolveZoneIDFromTags bool
+	validateOperandFlags  bool
 }
 
 // New creates (but does not start) a new operator from configuration.
@@ -63,33 +111,78 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config, dnsConfig *confi
 		return nil, fmt.Errorf("failed to create kube kclient: %v", err)
 	}
 
-	creds := credentials.NewStaticCredentials(string(config.Credentials.Data["aws_access_key_id"]), string(config.Credentials.Data["aws_secret_access_key"]), "")
-	sess, err := session.NewSessionWithOptions(session.Options{
-		Config: aws.Config{
-			Credentials: creds,
-		},
-		SharedConfigState: session.SharedConfigEnable,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("couldn't create AWS client session: %v", err)
+	// The operator only calls AWS APIs; the operand of other providers
+	// gets its credentials from the controller.
+	var sess *session.Session
+	if usesAWS(config) {
+		awsConfig := aws.Config{}
+		// Without static credentials, fall back to the default credential chain.
+		if len(config.Credentials.Data["aws_access_key_id"]) != 0 {
+			awsConfig.Credentials = credentials.NewStaticCredentials(string(config.Credentials.Data["aws_access_key_id"]), string(config.Credentials.Data["aws_secret_access_key"]), "")
+		}
+		sess, err = session.NewSessionWithOptions(session.Options{
+			Config:            awsConfig,
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create AWS client session: %v", err)
+		}
+	}
+
+	var syncPeriod *time.Duration
+	if config.SyncPeriod != nil {
+		syncPeriod = &config.SyncPeriod.Duration
+	}
+
+	operandNamespace := config.OperandNamespace
+	if len(operandNamespace) == 0 {
+		operandNamespace = operatorcontroller.DefaultOperandNamespace
 	}
 
 	scheme := operatorclient.GetScheme()
 	operatorManager, err := manager.New(kubeConfig, manager.Options{
-		Namespace: config.Namespace,
-		Scheme:    scheme,
+		Namespace:  config.Namespace,
+		Scheme:     scheme,
+		SyncPeriod: syncPeriod,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create operator manager: %v", err)
 	}
 
-	// Create and register the operator controller with the operator manager.
-	operatorController, err := operatorcontroller.New(operatorManager, operatorcontroller.Config{
+	controllerConfig := operatorcontroller.Config{
 		KubeConfig:       kubeConfig,
 		Namespace:        config.Namespace,
 		ExternalDNSImage: config.ExternalDNSImage,
 		Credentials:      config.Credentials,
-	})
+		RoleARN:          config.RoleARN,
+
+		CredentialsSecretName: config.CredentialsSecretName,
+		OperandNamespace:      operandNamespace,
+
+		OperandContainerName:     config.OperandContainerName,
+		OperandPriorityClassName: config.OperandPriorityClassName,
+		ValidateOperandFlags:     config.ValidateOperandFlags,
+		CleanupOperandNamespace:  config.CleanupOperandNamespace,
+	}
+	if config.VerifyZoneFilter && sess != nil {
+		controllerConfig.ZoneChecker = route53.New(sess)
+	}
+	if config.DetectZoneType && sess != nil {
+		controllerConfig.ZoneTypeDetector = route53.New(sess)
+	}
+
+	// The tagging API is only used to resolve the ID of the private zone,
+	// which degrades to passing the zone tags to the operand.
+	var tClient *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
+	if config.ResolveZoneIDFromTags && config.Provider == operatorv1.AWSProvider {
+		tClient = resourcegroupstaggingapi.New(sess, aws.NewConfig().WithRegion("us-east-1"))
+		if err := checkTaggingAPI(tClient); err != nil {
+			logrus.Warningf("AWS resource groups tagging API is unavailable; the private zone will be identified by tags until it is reachable: %v", err)
+		}
+	}
+
+	// Create and register the operator controller with the operator manager.
+	operatorController, err := operatorcontroller.New(operatorManager, controllerConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create operator controller: %v", err)
 	}
@@ -101,14 +194,19 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config, dnsConfig *confi
 	if err != nil {
 		return nil, fmt.Errorf("failed to get API Group-Resources")
 	}
-	operandCache, err := cache.New(kubeConfig, cache.Options{Namespace: "openshift-externaldns", Scheme: scheme, Mapper: mapper})
+	operandCache, err := cache.New(kubeConfig, operandCacheOptions(operandNamespace, scheme, mapper, syncPeriod))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create openshift-externaldns cache: %v", err)
+		return nil, fmt.Errorf("failed to create %s cache: %v", operandNamespace, err)
 	}
 	// Any types added to the list here will only queue an externaldns if the
 	// resource has the expected label.
 	for _, o := range []runtime.Object{
 		&appsv1.Deployment{},
+		&batchv1.Job{},
+		&autoscalingv1.HorizontalPodAutoscaler{},
+		&corev1.ConfigMap{},
+		&corev1.Pod{},
+		&networkingv1.NetworkPolicy{},
 	} {
 		// TODO: It may not be necessary to copy, but erring on the side of caution for
 		//       now given we're in a loop.
@@ -146,27 +244,54 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config, dnsConfig *confi
 
 		// TODO: These are only needed for the default ingress controller stuff, which
 		// should be refactored away.
-		kclient:     kubeClient,
-		namespace:   config.Namespace,
-		dnsConfig:   dnsConfig,
-		provider:    config.Provider,
-		tClient: resourcegroupstaggingapi.New(sess, aws.NewConfig().WithRegion("us-east-1")),
+		kclient:   kubeClient,
+		namespace: config.Namespace,
+		dnsConfig: dnsConfig,
+		provider:  config.Provider,
+		tClient:   tClient,
+
+		resolveZoneIDFromTags: tClient != nil,
+		validateOperandFlags:  config.ValidateOperandFlags,
 	}, nil
 }
 
+// CredentialsRequired reports whether the operator can't run without the
+// credentials secret on a platform of the given provider type. The secret
+// is optional on platforms without a provider, where it only holds the
+// credentials of externaldnses selecting a provider through
+// spec.provider.type, and on AWS when the operand assumes roleARN.
+func CredentialsRequired(provider operatorv1.ProviderType, roleARN string) bool {
+	switch provider {
+	case operatorv1.AWSProvider:
+		return len(roleARN) == 0
+	case operatorv1.AzureProvider, operatorv1.GoogleProvider:
+		return true
+	}
+	return false
+}
+
+// usesAWS reports whether the operator calls AWS APIs, either on AWS or
+// with static AWS credentials for externaldnses selecting the aws provider
+// on another platform.
+func usesAWS(config operatorconfig.Config) bool {
+	if config.Provider == operatorv1.AWSProvider {
+		return true
+	}
+	return config.Credentials != nil && len(config.Credentials.Data["aws_access_key_id"]) != 0
+}
+
+// operandCacheOptions returns the options of the cache informing the
+// operator of the resources in the operand namespace.
+func operandCacheOptions(operandNamespace string, scheme *runtime.Scheme, mapper meta.RESTMapper, resync *time.Duration) cache.Options {
+	return cache.Options{Namespace: operandNamespace, Scheme: scheme, Mapper: mapper, Resync: resync}
+}
+
 // Start creates the default ExternalDNS and then starts the operator
 // synchronously until a message is received on the stop channel.
 // TODO: Move the default ExternalDNS logic elsewhere.
 func (o *Operator) Start(stop <-chan struct{}) error {
-	// Periodically ensure the default externaldns controller exists.
-	go wait.Until(func() {
-		if err := o.ensureDefaultPrivateExternalDNS(); err != nil {
-			logrus.Errorf("failed to ensure default private zone externaldns: %v", err)
-		}
-		if err := o.ensureDefaultPublicExternalDNS(); err != nil {
-			logrus.Errorf("failed to ensure default public zone externaldns: %v", err)
-		}
-	}, 1*time.Minute, stop)
+	// Periodically ensure the default externaldns controllers exist.
+	go o.ensureDefaultExternalDNSesUntil(stop)
 
 	errChan := make(chan error)
 
@@ -184,35 +309,91 @@ func (o *Operator) Start(stop <-chan struct{}) error {
 	}
 }
 
+// ensureDefaultExternalDNSesUntil ensures the default externaldnses exist
+// every defaultExternalDNSResyncInterval until stop is closed. Failures are
+// retried with an exponential backoff and counted by the
+// defaultExternalDNSFailures metric.
+func (o *Operator) ensureDefaultExternalDNSesUntil(stop <-chan struct{}) {
+	failures := 0
+	for {
+		delay := defaultExternalDNSResyncInterval
+		errs := []error{}
+		if err := o.ensureDefaultPrivateExternalDNS(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure default private zone externaldns: %v", err))
+		}
+		if err := o.ensureDefaultPublicExternalDNS(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure default public zone externaldns: %v", err))
+		}
+		if err := kerrors.NewAggregate(errs); err != nil {
+			failures++
+			delay = defaultExternalDNSBackoff(failures)
+			logrus.Errorf("failed to ensure default externaldnses %d time(s), retrying in %s: %v", failures, delay, err)
+		} else if failures > 0 {
+			logrus.Infof("ensured default externaldnses after %d failure(s)", failures)
+			failures = 0
+		}
+		defaultExternalDNSFailures.Set(float64(failures))
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// defaultExternalDNSBackoff returns the delay before retrying to ensure the
+// default externaldnses after the given number of consecutive failures.
+func defaultExternalDNSBackoff(failures int) time.Duration {
+	delay := defaultExternalDNSMinBackoff
+	for i := 1; i < failures && delay < defaultExternalDNSMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > defaultExternalDNSMaxBackoff {
+		delay = defaultExternalDNSMaxBackoff
+	}
+	return delay
+}
+
 // ensureDefaultPrivateExternalDNS creates the default private zone externaldns
-// if it does not already exist.
+// if it does not already exist. Clusters without a private zone get none.
 func (o *Operator) ensureDefaultPrivateExternalDNS() error {
+	if o.dnsConfig.Spec.PrivateZone == nil {
+		return nil
+	}
 	svc := operatorv1.ServiceType
 	zone := operatorv1.PrivateZoneType
-	id, err := o.getZoneIDFromTags(o.dnsConfig.Spec.PrivateZone)
-	if err != nil {
-		logrus.Errorf("failed to get zone id from tags: %v", err)
-	}
-	// TODO: Remove after testing. Tags are used for private zones and is broken upstream:
-	// https://github.com/kubernetes-incubator/external-dns/issues/1019
-	private := configv1.DNSZone{ID: id}
 	edns := &operatorv1.ExternalDNS{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      operatorcontroller.DefaultExternalDNSPrivateZoneController,
 			Namespace: o.namespace,
 		},
 		Spec: operatorv1.ExternalDNSSpec{
-			Sources: []*operatorv1.SourceType{&svc},
+			Sources:  []*operatorv1.SourceType{&svc},
 			ZoneType: &zone,
-			Provider: operatorv1.ProviderSpec{
-				ZoneFilter: []*configv1.DNSZone{&private},
-			},
 		},
 	}
 	if err := o.kclient.Get(context.TODO(), types.NamespacedName{Namespace: edns.Namespace, Name: edns.Name}, edns); err != nil {
 		if !errors.IsNotFound(err) {
 			return err
 		}
+		// The private zone is identified by tags, which are passed to
+		// the operand as a zone filter unless the zone ID is resolved
+		// here. It is only resolved on creation, so resyncs don't call
+		// the tagging API.
+		private := *o.dnsConfig.Spec.PrivateZone
+		if o.resolveZoneIDFromTags {
+			id, err := o.getZoneIDFromTags(o.dnsConfig.Spec.PrivateZone)
+			switch {
+			case err != nil:
+				logrus.Warningf("failed to get zone id from tags, using the tags as zone filter: %v", err)
+			case len(id) == 0:
+				logrus.Warningf("found no hosted zone with tags %q, using the tags as zone filter", o.dnsConfig.Spec.PrivateZone.Tags)
+			default:
+				private = configv1.DNSZone{ID: id}
+			}
+		}
+		edns.Spec.Provider = defaultProviderSpec(o.provider, &private, o.validateOperandFlags)
 		if err := o.kclient.Create(context.TODO(), edns); err != nil {
 			return fmt.Errorf("failed to create externaldns default private zone: %v", err)
 		}
@@ -222,8 +403,11 @@ func (o *Operator) ensureDefaultPrivateExternalDNS() error {
 }
 
 // ensureDefaultPublicExternalDNS creates the default public zone externaldns
-// if it does not already exist.
+// if it does not already exist. Clusters without a public zone get none.
 func (o *Operator) ensureDefaultPublicExternalDNS() error {
+	if o.dnsConfig.Spec.PublicZone == nil {
+		return nil
+	}
 	svc := operatorv1.ServiceType
 	zone := operatorv1.PublicZoneType
 	edns := &operatorv1.ExternalDNS{
@@ -232,11 +416,9 @@ func (o *Operator) ensureDefaultPublicExternalDNS() error {
 			Namespace: o.namespace,
 		},
 		Spec: operatorv1.ExternalDNSSpec{
-			Sources: []*operatorv1.SourceType{&svc},
+			Sources:  []*operatorv1.SourceType{&svc},
 			ZoneType: &zone,
-			Provider: operatorv1.ProviderSpec{
-				ZoneFilter: []*configv1.DNSZone{o.dnsConfig.Spec.PublicZone},
-			},
+			Provider: defaultProviderSpec(o.provider, o.dnsConfig.Spec.PublicZone, o.validateOperandFlags),
 		},
 	}
 	if err := o.kclient.Get(context.TODO(), types.NamespacedName{Namespace: edns.Namespace, Name: edns.Name}, edns); err != nil {
@@ -251,6 +433,32 @@ func (o *Operator) ensureDefaultPublicExternalDNS() error {
 	return nil
 }
 
+// defaultProviderSpec returns the provider spec of a default externaldns
+// managing the given zone on a platform of the given provider type. It is
+// only set on creation, so existing externaldnses keep their settings.
+// Flags that older ExternalDNS images don't recognize are only defaulted
+// when validateOperandFlags is set, so that an image without them fails
+// the flag validation with a clear message instead of crash-looping.
+func defaultProviderSpec(provider operatorv1.ProviderType, zone *configv1.DNSZone, validateOperandFlags bool) operatorv1.ProviderSpec {
+	spec := operatorv1.ProviderSpec{ZoneFilter: []*configv1.DNSZone{zone}}
+	if provider == operatorv1.AWSProvider && validateOperandFlags {
+		spec.AWSZonesCacheDuration = &metav1.Duration{Duration: defaultAWSZonesCacheDuration}
+	}
+	return spec
+}
+
+// checkTaggingAPI verifies that the tagging API can be reached with the
+// operator's credentials by listing a single hosted zone.
+func checkTaggingAPI(tClient *resourcegroupstaggingapi.ResourceGroupsTaggingAPI) error {
+	ctx, cancel := context.WithTimeout(context.Background(), taggingAPICheckTimeout)
+	defer cancel()
+	_, err := tClient.GetResourcesWithContext(ctx, &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: []*string{aws.String("route53:hostedzone")},
+		ResourcesPerPage:    aws.Int64(1),
+	})
+	return err
+}
+
 // getZoneIDFromTags finds the ID of a Route53 hosted zone from the given zoneConfig
 // by using tags to search for the zone. Returns an error if the zone can't be found.
 func (o *Operator) getZoneIDFromTags(zoneConfig *configv1.DNSZone) (string, error) {
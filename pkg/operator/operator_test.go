@@ -0,0 +1,184 @@
+package operator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/danehans/api/operator/v1"
+	configv1 "github.com/openshift/api/config/v1"
+
+	operatorconfig "github.com/danehans/external-dns-operator/pkg/operator/config"
+	operatorcontroller "github.com/danehans/external-dns-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDefaultExternalDNSBackoff(t *testing.T) {
+	testCases := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 1, expected: 10 * time.Second},
+		{failures: 2, expected: 20 * time.Second},
+		{failures: 4, expected: 80 * time.Second},
+		{failures: 7, expected: 10 * time.Minute},
+		{failures: 100, expected: 10 * time.Minute},
+	}
+	for _, tc := range testCases {
+		if actual := defaultExternalDNSBackoff(tc.failures); actual != tc.expected {
+			t.Errorf("%d failures: expected %s, got %s", tc.failures, tc.expected, actual)
+		}
+	}
+}
+
+func TestOperandCacheOptions(t *testing.T) {
+	resync := time.Minute
+	options := operandCacheOptions("custom-externaldns", nil, nil, &resync)
+	if options.Namespace != "custom-externaldns" {
+		t.Errorf("expected the cache to inform on namespace custom-externaldns, got %q", options.Namespace)
+	}
+	if options.Resync != &resync {
+		t.Errorf("expected the resync period to be passed through, got %v", options.Resync)
+	}
+}
+
+func TestCredentialsRequired(t *testing.T) {
+	testCases := []struct {
+		provider operatorv1.ProviderType
+		roleARN  string
+		expected bool
+	}{
+		{provider: operatorv1.AWSProvider, expected: true},
+		{provider: operatorv1.AWSProvider, roleARN: "arn:aws:iam::123456789012:role/externaldns", expected: false},
+		{provider: operatorv1.AzureProvider, expected: true},
+		{provider: operatorv1.GoogleProvider, expected: true},
+		// Platforms without a provider, where externaldnses select one
+		// through spec.provider.type.
+		{provider: "", expected: false},
+	}
+	for _, tc := range testCases {
+		if actual := CredentialsRequired(tc.provider, tc.roleARN); actual != tc.expected {
+			t.Errorf("%q with role %q: expected %t, got %t", tc.provider, tc.roleARN, tc.expected, actual)
+		}
+	}
+}
+
+func TestUsesAWS(t *testing.T) {
+	awsCreds := &corev1.Secret{Data: map[string][]byte{"aws_access_key_id": []byte("key"), "aws_secret_access_key": []byte("secret")}}
+	azureCreds := &corev1.Secret{Data: map[string][]byte{"azure_client_id": []byte("id")}}
+	testCases := []struct {
+		description string
+		config      operatorconfig.Config
+		expected    bool
+	}{
+		{"aws platform", operatorconfig.Config{Provider: operatorv1.AWSProvider, Credentials: &corev1.Secret{}}, true},
+		{"aws credentials on azure", operatorconfig.Config{Provider: operatorv1.AzureProvider, Credentials: awsCreds}, true},
+		{"aws credentials without platform provider", operatorconfig.Config{Credentials: awsCreds}, true},
+		{"azure credentials on azure", operatorconfig.Config{Provider: operatorv1.AzureProvider, Credentials: azureCreds}, false},
+		{"no credentials", operatorconfig.Config{}, false},
+	}
+	for _, tc := range testCases {
+		if actual := usesAWS(tc.config); actual != tc.expected {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestDefaultProviderSpec(t *testing.T) {
+	zone := &configv1.DNSZone{ID: "zone"}
+	testCases := []struct {
+		provider      operatorv1.ProviderType
+		validateFlags bool
+		expected      *metav1.Duration
+	}{
+		{provider: operatorv1.AWSProvider, validateFlags: true, expected: &metav1.Duration{Duration: 10 * time.Minute}},
+		{provider: operatorv1.AWSProvider},
+		{provider: operatorv1.AzureProvider, validateFlags: true},
+		{provider: operatorv1.GoogleProvider, validateFlags: true},
+		{provider: "", validateFlags: true},
+	}
+	for _, tc := range testCases {
+		spec := defaultProviderSpec(tc.provider, zone, tc.validateFlags)
+		if len(spec.ZoneFilter) != 1 || spec.ZoneFilter[0] != zone {
+			t.Errorf("%q: expected zone filter %v, got %v", tc.provider, zone, spec.ZoneFilter)
+		}
+		if !reflect.DeepEqual(spec.AWSZonesCacheDuration, tc.expected) {
+			t.Errorf("%q: expected zones cache duration %v, got %v", tc.provider, tc.expected, spec.AWSZonesCacheDuration)
+		}
+	}
+}
+
+// externalDNSCreator is a client finding the externaldnses of the given
+// names and recording created externaldnses. Calls to other methods panic.
+type externalDNSCreator struct {
+	client.Client
+	existing []string
+	created  []*operatorv1.ExternalDNS
+}
+
+func (c *externalDNSCreator) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	for _, name := range c.existing {
+		if name == key.Name {
+			return nil
+		}
+	}
+	return errors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (c *externalDNSCreator) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOptionFunc) error {
+	c.created = append(c.created, obj.(*operatorv1.ExternalDNS).DeepCopy())
+	return nil
+}
+
+func TestEnsureDefaultExternalDNSes(t *testing.T) {
+	private := &configv1.DNSZone{Tags: map[string]string{"Name": "cluster-abc-int"}}
+	public := &configv1.DNSZone{ID: "public"}
+	both := []string{operatorcontroller.DefaultExternalDNSPrivateZoneController, operatorcontroller.DefaultExternalDNSPublicZoneController}
+	testCases := []struct {
+		description string
+		spec        configv1.DNSSpec
+		existing    []string
+		expected    []string
+	}{
+		{"no zones", configv1.DNSSpec{}, nil, nil},
+		{"public zone only", configv1.DNSSpec{PublicZone: public}, nil, []string{operatorcontroller.DefaultExternalDNSPublicZoneController}},
+		{"private zone only", configv1.DNSSpec{PrivateZone: private}, nil, []string{operatorcontroller.DefaultExternalDNSPrivateZoneController}},
+		{"both zones", configv1.DNSSpec{PrivateZone: private, PublicZone: public}, nil, both},
+		// The zone ID isn't resolved from the tags of an existing
+		// instance, which would panic without a tagging client.
+		{"existing instances", configv1.DNSSpec{PrivateZone: private, PublicZone: public}, both, nil},
+	}
+	for _, tc := range testCases {
+		c := &externalDNSCreator{existing: tc.existing}
+		o := &Operator{
+			kclient:               c,
+			dnsConfig:             &configv1.DNS{Spec: tc.spec},
+			resolveZoneIDFromTags: len(tc.existing) != 0,
+		}
+		if err := o.ensureDefaultPrivateExternalDNS(); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		}
+		if err := o.ensureDefaultPublicExternalDNS(); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		}
+		created := []string{}
+		for _, edns := range c.created {
+			created = append(created, edns.Name)
+			if len(edns.Spec.Provider.ZoneFilter) != 1 || edns.Spec.Provider.ZoneFilter[0] == nil {
+				t.Errorf("%q: expected a zone filter on %s, got %v", tc.description, edns.Name, edns.Spec.Provider.ZoneFilter)
+			}
+		}
+		if len(created) != len(tc.expected) || (len(created) != 0 && !reflect.DeepEqual(created, tc.expected)) {
+			t.Errorf("%q: expected %v to be created, got %v", tc.description, tc.expected, created)
+		}
+	}
+}
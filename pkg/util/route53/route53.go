@@ -0,0 +1,122 @@
+// Package route53 is a minimal Route 53 API client. The vendored AWS SDK
+// doesn't include the Route 53 service client, so requests are built and
+// signed with the core SDK packages.
+package route53
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	serviceName   = "route53"
+	apiVersion    = "2013-04-01"
+	endpoint      = "https://route53.amazonaws.com"
+	signingRegion = "us-east-1"
+)
+
+// Client queries the Route 53 API.
+type Client struct {
+	*client.Client
+}
+
+// New creates a Route 53 client from p, e.g. an AWS session.
+func New(p client.ConfigProvider) *Client {
+	c := p.ClientConfig(serviceName, aws.NewConfig().WithRegion(signingRegion))
+	svc := client.New(*c.Config, metadata.ClientInfo{
+		ServiceName:   serviceName,
+		APIVersion:    apiVersion,
+		Endpoint:      endpoint,
+		SigningName:   serviceName,
+		SigningRegion: signingRegion,
+	}, c.Handlers)
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	svc.Handlers.Unmarshal.PushBack(discardBody)
+	svc.Handlers.UnmarshalError.PushBack(unmarshalError)
+	return &Client{svc}
+}
+
+// getHostedZoneOutput is the subset of the GetHostedZone response used by
+// the client.
+type getHostedZoneOutput struct {
+	HostedZone struct {
+		Config struct {
+			PrivateZone bool `xml:"PrivateZone"`
+		} `xml:"Config"`
+	} `xml:"HostedZone"`
+}
+
+// HostedZoneExists returns whether the hosted zone with the given ID exists.
+// The ID may include the "/hostedzone/" prefix. An error is returned if the
+// zone can't be read, e.g. when the credentials aren't allowed to.
+func (c *Client) HostedZoneExists(id string) (bool, error) {
+	req := c.NewRequest(getHostedZoneOperation(id), nil, nil)
+	if err := req.Send(); err != nil {
+		if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// HostedZoneIsPrivate returns whether the hosted zone with the given ID is
+// a private zone. The ID may include the "/hostedzone/" prefix.
+func (c *Client) HostedZoneIsPrivate(id string) (bool, error) {
+	out := &getHostedZoneOutput{}
+	req := c.NewRequest(getHostedZoneOperation(id), nil, out)
+	req.Handlers.Unmarshal.Clear()
+	req.Handlers.Unmarshal.PushBack(unmarshalXML)
+	if err := req.Send(); err != nil {
+		return false, err
+	}
+	return out.HostedZone.Config.PrivateZone, nil
+}
+
+// getHostedZoneOperation returns the GetHostedZone operation of the zone
+// with the given ID.
+func getHostedZoneOperation(id string) *request.Operation {
+	return &request.Operation{
+		Name:       "GetHostedZone",
+		HTTPMethod: http.MethodGet,
+		HTTPPath:   "/" + apiVersion + "/hostedzone/" + url.PathEscape(strings.TrimPrefix(id, "/hostedzone/")),
+	}
+}
+
+// discardBody drains and closes the body of a successful response, since
+// no response data is used.
+func discardBody(r *request.Request) {
+	defer r.HTTPResponse.Body.Close()
+	io.Copy(ioutil.Discard, r.HTTPResponse.Body)
+}
+
+// unmarshalXML decodes the body of a successful response into the request
+// data.
+func unmarshalXML(r *request.Request) {
+	defer r.HTTPResponse.Body.Close()
+	if err := xml.NewDecoder(r.HTTPResponse.Body).Decode(r.Data); err != nil {
+		r.Error = awserr.New("SerializationError", "failed to decode route53 "+r.Operation.Name+" response", err)
+	}
+}
+
+// unmarshalError replaces the generic error of a failed response with one
+// carrying the response status, so callers can tell a missing zone from
+// other failures.
+func unmarshalError(r *request.Request) {
+	defer r.HTTPResponse.Body.Close()
+	io.Copy(ioutil.Discard, r.HTTPResponse.Body)
+	code := r.HTTPResponse.StatusCode
+	r.Error = awserr.NewRequestFailure(awserr.New(strings.Replace(http.StatusText(code), " ", "", -1),
+		"route53 "+r.Operation.Name+" failed", nil), code, r.RequestID)
+}